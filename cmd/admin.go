@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+)
+
+const adminUsage = `usage:
+  app admin create -username <name> [-password <password>]
+  app admin recover -username <name>
+
+Without -password a password is generated and printed. Either way it must be
+changed on first login.
+`
+
+func newAdminService() ports.AdminService {
+	ur := repository.NewUserRepository(db)
+	ks := services.NewKeyService(repository.NewSigningKeyRepository(db))
+	ts := services.NewTokenService(repository.NewTokenRepository(db), ur, repository.NewRoleRepository(db), ks)
+	return services.NewAdminService(ur, ts, hasher.New())
+}
+
+// bootstrapAdmin makes sure a fresh database has an admin to start with.
+func bootstrapAdmin() {
+	admin, password, err := newAdminService().EnsureAdmin()
+	if err != nil {
+		log.Fatal("admin bootstrap failed:", err)
+	}
+	if admin != nil && password != "" {
+		printPassword(admin.Username, password)
+	}
+}
+
+// runAdminCommand implements the admin subcommand and returns the exit code.
+func runAdminCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("admin "+args[0], flag.ContinueOnError)
+	username := fs.String("username", "", "admin username")
+	password := fs.String("password", "", "initial password (generated when empty)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *username == "" {
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+
+	as := newAdminService()
+	switch args[0] {
+	case "create":
+		admin, generated, err := as.CreateAdmin(*username, *password)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "could not create admin:", err)
+			return 1
+		}
+		fmt.Printf("admin %q created\n", admin.Username)
+		if generated != "" {
+			printPassword(admin.Username, generated)
+		}
+
+	case "recover":
+		generated, err := as.RecoverAdmin(*username)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "could not recover admin:", err)
+			return 1
+		}
+		printPassword(*username, generated)
+
+	default:
+		fmt.Fprint(os.Stderr, adminUsage)
+		return 2
+	}
+	return 0
+}
+
+func printPassword(username, password string) {
+	fmt.Printf("\n  admin user: %s\n  password:   %s\n\n  It will not be shown again and must be changed on first login.\n\n", username, password)
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type DeleteAccountRequest struct {
+	// Password confirms the deletion. Accounts created through an identity
+	// provider have none and may leave it out.
+	Password string `json:"password"`
+}
+
+// UserExportResponse is the personal data archive of a user.
+type UserExportResponse struct {
+	ExportedAt time.Time                `json:"exported_at"`
+	Profile    ProfileResponse          `json:"profile"`
+	Scores     []ScoreResponse          `json:"scores"`
+	Sessions   []SessionResponse        `json:"sessions"`
+	APIKeys    []APIKeyResponse         `json:"api_keys"`
+	Identities []IdentityResponse       `json:"identities"`
+	Usernames  []UsernameChangeResponse `json:"previous_usernames"`
+}
+
+type IdentityResponse struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SessionResponse struct {
+	ID        string     `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
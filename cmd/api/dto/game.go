@@ -2,9 +2,11 @@ package dto
 
 type CreateRequest struct {
 	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug,omitempty"` // derived from the name when empty
 }
 
 type GameResponse struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
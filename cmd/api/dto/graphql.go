@@ -0,0 +1,19 @@
+package dto
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required" example:"{ me { username scores { points game { name } } } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type GraphQLResponse struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []GraphQLError         `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string        `json:"message" example:"game not allowed"`
+	Path    []interface{} `json:"path,omitempty"`
+	// Extensions carry the error code and status of the REST API.
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
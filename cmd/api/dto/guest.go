@@ -0,0 +1,17 @@
+package dto
+
+type GuestLoginRequest struct {
+	DeviceID string `json:"device_id" binding:"required,min=16,max=200"` // stable, hard to guess identifier of the device
+}
+
+// UpgradeGuestRequest sets the credentials of a guest turning into a
+// registered account.
+type UpgradeGuestRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Password string `json:"password" binding:"required"` // checked against the password policy
+}
+
+type MergeGuestRequest struct {
+	DeviceID string `json:"device_id" binding:"required,max=200"` // device of the guest to merge
+}
@@ -0,0 +1,22 @@
+package dto
+
+type ImportReportResponse struct {
+	Kind     string `json:"kind" example:"scores"`
+	Conflict string `json:"conflict" example:"keep-best"`
+	DryRun   bool   `json:"dry_run"`
+	Rows     int    `json:"rows"`
+	Created  int    `json:"created"`
+	Updated  int    `json:"updated"`
+	Skipped  int    `json:"skipped"`
+	Rejected int    `json:"rejected"`
+	// Errors lists the rejected rows, up to IMPORT_MAX_ERRORS.
+	Errors          []ImportRowErrorResponse `json:"errors"`
+	ErrorsTruncated bool                     `json:"errors_truncated"`
+}
+
+type ImportRowErrorResponse struct {
+	Line    int    `json:"line" example:"12"`
+	Field   string `json:"field,omitempty" example:"points"`
+	Message string `json:"message" example:"must be at least 0"`
+	Row     string `json:"row" example:"ana,chess,-5"`
+}
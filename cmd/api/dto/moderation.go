@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type ModerationRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type SuspendRequest struct {
+	Reason string    `json:"reason" binding:"required,max=500"`
+	Until  time.Time `json:"until" binding:"required"`
+}
+
+type ModerationActionResponse struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	ActorID   string     `json:"actor_id,omitempty"` // user ID, or api_key:<id> for actions taken with an API key
+	Action    string     `json:"action" example:"suspend"`
+	Reason    string     `json:"reason"`
+	Until     *time.Time `json:"until,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
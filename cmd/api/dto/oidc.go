@@ -0,0 +1,7 @@
+package dto
+
+type LinkIdentityResponse struct {
+	// URL is the provider login page to send the user to. The callback links
+	// the account and answers with a new token pair.
+	URL string `json:"url" example:"https://accounts.example.com/authorize?client_id=scoring-api&state=..."`
+}
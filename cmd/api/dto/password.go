@@ -0,0 +1,15 @@
+package dto
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"` // checked against the password policy
+}
+
+type ForgotPasswordRequest struct {
+	Login string `json:"login" binding:"required"` // username or email
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // checked against the password policy
+}
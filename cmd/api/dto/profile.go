@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+// ProfileResponse is the full profile, shown to the user themselves and to
+// staff listing users.
+type ProfileResponse struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email,omitempty"`
+	Role        string    `json:"role"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Country     string    `json:"country"`
+	Bio         string    `json:"bio"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Guest          bool       `json:"guest"`
+	Status         string     `json:"status" example:"active"` // active, suspended or banned
+	StatusReason   string     `json:"status_reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+}
+
+// UpdateProfileRequest changes the fields present in the body; an empty
+// string clears a field.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=50"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,http_url,max=500"`
+	Country     *string `json:"country" binding:"omitempty,iso3166_1_alpha2"` // e.g. "AR"
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+}
+
+type PublicProfileResponse struct {
+	ID          string               `json:"id"`
+	Username    string               `json:"username"`
+	DisplayName string               `json:"display_name"`
+	AvatarURL   string               `json:"avatar_url"`
+	Country     string               `json:"country"`
+	Bio         string               `json:"bio"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Summary     ScoreSummaryResponse `json:"summary"`
+	Scores      []ScoreResponse      `json:"scores"`
+}
+
+type ScoreSummaryResponse struct {
+	GamesPlayed int            `json:"games_played"`
+	TotalPoints int            `json:"total_points"`
+	Best        *ScoreResponse `json:"best,omitempty"`
+}
+
+type UserListResponse struct {
+	Items    []ProfileResponse `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+type RenameRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type UsernameChangeResponse struct {
+	Username  string    `json:"username"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -2,7 +2,7 @@ package dto
 
 type SubmitScoreRequest struct {
 	UserID string `json:"user_id" binding:"required,uuid4"`
-	GameID string `json:"game_id" binding:"required,uuid4"`
+	GameID string `json:"game_id" binding:"required"` // game ID or slug
 	Points int    `json:"points" binding:"required,min=0"`
 }
 
@@ -10,6 +10,7 @@ type ScoreResponse struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	GameID   string `json:"game_id"`
+	GameSlug string `json:"game_slug"`
 	GameName string `json:"game_name"`
 	Points   int    `json:"points"`
 }
@@ -20,6 +21,7 @@ type SuccessResponse struct {
 
 type ScoreStatisticsDTO struct {
 	GameID   string  `json:"game_id"`
+	GameSlug string  `json:"game_slug"`
 	GameName string  `json:"game_name"`
 	Mean     float64 `json:"mean"`
 	Median   float64 `json:"median"`
@@ -5,11 +5,30 @@ type RegisterResponse struct {
 	Username string `json:"username"`
 }
 
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"omitempty,email"` // optional, needed to reset a forgotten password
+	Password string `json:"password" binding:"required"`     // checked against the password policy
+}
+
 type AuthRequest struct {
 	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+	// MustChangePassword means every endpoint but the password change is
+	// refused until the user picks a new password.
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
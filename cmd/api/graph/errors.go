@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/rs/zerolog/log"
+)
+
+// fieldError is the error of a field. Its extensions carry the code and the
+// status the REST API answers the same error with.
+type fieldError struct {
+	err error
+}
+
+func fail(err error) error {
+	if problem.CodeOf(err) == problem.CodeInternal {
+		log.Error().Err(err).Msg("graphql field failed")
+	}
+	return &fieldError{err: err}
+}
+
+func (e *fieldError) Error() string {
+	if problem.CodeOf(e.err) == problem.CodeInternal {
+		return "internal error"
+	}
+	return e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+func (e *fieldError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   problem.CodeOf(e.err),
+		"status": problem.Status(e.err),
+	}
+}
@@ -0,0 +1,77 @@
+package graph
+
+import "sync"
+
+// Loader batches the lookups of one request to avoid a query per parent
+// object. Resolvers of a list queue the keys their children will need, and
+// the first Load fetches every queued key in a single call. Results are
+// cached for the rest of the request.
+type Loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu     sync.Mutex
+	queued []K
+	cache  map[K]V
+	errs   map[K]error
+}
+
+// NewLoader returns a loader that fetches keys with fetch. Keys missing from
+// the map fetch returns load as the zero value of V.
+func NewLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, cache: map[K]V{}, errs: map[K]error{}}
+}
+
+// Queue adds keys to the next batch.
+func (l *Loader[K, V]) Queue(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queued = append(l.queued, keys...)
+}
+
+// Prime caches a value that is already known, so that it is never fetched.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; !ok {
+		l.cache[key] = value
+	}
+}
+
+// Load returns the value of key, fetching it along with the queued keys
+// unless it is cached.
+func (l *Loader[K, V]) Load(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value, ok := l.cache[key]; ok {
+		return value, nil
+	}
+	if err, ok := l.errs[key]; ok {
+		var zero V
+		return zero, err
+	}
+
+	batch := []K{key}
+	seen := map[K]bool{key: true}
+	for _, k := range l.queued {
+		if _, cached := l.cache[k]; !cached && !seen[k] {
+			seen[k] = true
+			batch = append(batch, k)
+		}
+	}
+	l.queued = nil
+
+	values, err := l.fetch(batch)
+	for _, k := range batch {
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.cache[k] = values[k]
+	}
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return values[key], nil
+}
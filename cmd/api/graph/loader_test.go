@@ -0,0 +1,89 @@
+package graph_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fetcher struct {
+	mu      sync.Mutex
+	batches [][]int
+	err     error
+}
+
+func (f *fetcher) fetch(keys []int) (map[int]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, keys)
+	if f.err != nil {
+		return nil, f.err
+	}
+	values := map[int]string{}
+	for _, k := range keys {
+		if k > 0 {
+			values[k] = string(rune('a' + k - 1))
+		}
+	}
+	return values, nil
+}
+
+func TestLoader_BatchesQueuedKeys(t *testing.T) {
+	f := &fetcher{}
+	l := graph.NewLoader(f.fetch)
+	l.Queue(1, 2, 2, 3)
+
+	var wg sync.WaitGroup
+	for _, k := range []int{3, 2, 1} {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			_, err := l.Load(k)
+			assert.NoError(t, err)
+		}(k)
+	}
+	wg.Wait()
+
+	require.Len(t, f.batches, 1)
+	assert.ElementsMatch(t, []int{1, 2, 3}, f.batches[0])
+}
+
+func TestLoader_CachesAndPrimes(t *testing.T) {
+	f := &fetcher{}
+	l := graph.NewLoader(f.fetch)
+	l.Prime(1, "primed")
+
+	v, err := l.Load(1)
+	require.NoError(t, err)
+	assert.Equal(t, "primed", v)
+	assert.Empty(t, f.batches)
+
+	v, _ = l.Load(2)
+	assert.Equal(t, "b", v)
+	v, _ = l.Load(2)
+	assert.Equal(t, "b", v)
+	assert.Len(t, f.batches, 1)
+}
+
+func TestLoader_MissingKeyIsZero(t *testing.T) {
+	l := graph.NewLoader((&fetcher{}).fetch)
+	v, err := l.Load(-1)
+	require.NoError(t, err)
+	assert.Equal(t, "", v)
+}
+
+func TestLoader_ErrorAppliesToBatch(t *testing.T) {
+	f := &fetcher{err: errors.New("db down")}
+	l := graph.NewLoader(f.fetch)
+	l.Queue(2)
+
+	_, err := l.Load(1)
+	assert.Error(t, err)
+	_, err = l.Load(2)
+	assert.Error(t, err)
+	assert.Len(t, f.batches, 1)
+}
@@ -0,0 +1,348 @@
+package graph
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rs/zerolog/log"
+)
+
+type queryResolver struct{}
+
+func (q *queryResolver) Me(ctx context.Context) (*userResolver, error) {
+	r := requestFrom(ctx)
+	if r.caller.UserID == "" {
+		return nil, fail(domain.ErrUserTokenRequired)
+	}
+	user, err := r.services.Profiles.GetProfile(r.caller.UserID)
+	if err != nil {
+		return nil, fail(err)
+	}
+	r.users.Prime(user.ID, user)
+	return &userResolver{r: r, user: user}, nil
+}
+
+func (q *queryResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	r := requestFrom(ctx)
+	if !r.caller.has(domain.PermScoresRead) {
+		return nil, fail(domain.ErrForbidden)
+	}
+	if _, err := uuid.Parse(string(args.ID)); err != nil {
+		return nil, fail(domain.ErrUserNotFound)
+	}
+	return r.user(string(args.ID))
+}
+
+func (q *queryResolver) Users(ctx context.Context, args struct {
+	Search   *string
+	Page     *int32
+	PageSize *int32
+}) (*userPageResolver, error) {
+	r := requestFrom(ctx)
+	if !r.caller.has(domain.PermUsersRead) {
+		return nil, fail(domain.ErrForbidden)
+	}
+
+	query := &domain.UserQuery{}
+	if args.Search != nil {
+		query.Search = *args.Search
+	}
+	if args.Page != nil {
+		query.Page = int(*args.Page)
+	}
+	if args.PageSize != nil {
+		query.PageSize = int(*args.PageSize)
+	}
+	page, err := r.services.Profiles.ListUsers(query)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if err := r.spend(len(page.Users)); err != nil {
+		return nil, err
+	}
+
+	users := make([]*userResolver, 0, len(page.Users))
+	for i := range page.Users {
+		user := &page.Users[i]
+		r.users.Prime(user.ID, user)
+		r.userScores.Queue(user.ID)
+		users = append(users, &userResolver{r: r, user: user})
+	}
+	return &userPageResolver{users: users, page: page}, nil
+}
+
+func (q *queryResolver) Games(ctx context.Context) ([]*gameResolver, error) {
+	r := requestFrom(ctx)
+	if !r.caller.has(domain.PermGamesRead) {
+		return nil, fail(domain.ErrForbidden)
+	}
+	games, err := r.services.Games.GetGames()
+	if err != nil {
+		return nil, fail(err)
+	}
+	if err := r.spend(len(*games)); err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*gameResolver, 0, len(*games))
+	for i := range *games {
+		game := &(*games)[i]
+		r.games.Prime(game.ID, game)
+		r.gameScores.Queue(game.ID)
+		resolvers = append(resolvers, &gameResolver{r: r, game: game})
+	}
+	return resolvers, nil
+}
+
+func (q *queryResolver) Game(ctx context.Context, args struct{ Ref string }) (*gameResolver, error) {
+	r := requestFrom(ctx)
+	if !r.caller.has(domain.PermGamesRead) {
+		return nil, fail(domain.ErrForbidden)
+	}
+	game, err := r.services.Games.GetGame(args.Ref)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if !r.caller.allowsGame(game.ID) {
+		log.Warn().Str("api_key_id", r.caller.APIKey.ID).Str("game_id", game.ID).Msg("api key used outside its games")
+		return nil, fail(domain.ErrGameNotAllowed)
+	}
+	r.games.Prime(game.ID, game)
+	return &gameResolver{r: r, game: game}, nil
+}
+
+func (r *request) user(id string) (*userResolver, error) {
+	user, err := r.users.Load(id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if user == nil {
+		return nil, fail(domain.ErrUserNotFound)
+	}
+	return &userResolver{r: r, user: user}, nil
+}
+
+func (r *request) game(id string) (*gameResolver, error) {
+	game, err := r.games.Load(id)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if game == nil {
+		return nil, fail(domain.ErrGameNotFound)
+	}
+	return &gameResolver{r: r, game: game}, nil
+}
+
+// scores wraps the scores the caller may see in resolvers, spending them
+// from the budget of the query. Only the users and games of these scores are
+// queued, so a list cut short never loads the rest of them.
+func (r *request) scores(scores []domain.Score) ([]*scoreResolver, error) {
+	resolvers := make([]*scoreResolver, 0, len(scores))
+	for i := range scores {
+		score := &scores[i]
+		if !r.caller.allowsGame(score.GameID) {
+			continue
+		}
+		resolvers = append(resolvers, &scoreResolver{r: r, score: score})
+	}
+	if err := r.spend(len(resolvers)); err != nil {
+		return nil, err
+	}
+	for _, resolver := range resolvers {
+		r.users.Queue(resolver.score.UserID)
+		r.games.Queue(resolver.score.GameID)
+	}
+	return resolvers, nil
+}
+
+type userResolver struct {
+	r    *request
+	user *domain.User
+}
+
+func (u *userResolver) ID() graphql.ID       { return graphql.ID(u.user.ID) }
+func (u *userResolver) Username() string     { return u.user.Username }
+func (u *userResolver) DisplayName() *string { return optional(u.user.DisplayName) }
+func (u *userResolver) AvatarURL() *string   { return optional(u.user.AvatarURL) }
+func (u *userResolver) Country() *string     { return optional(u.user.Country) }
+func (u *userResolver) Bio() *string         { return optional(u.user.Bio) }
+func (u *userResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: u.user.CreatedAt}
+}
+
+func (u *userResolver) Email() *string {
+	if u.user.ID != u.r.caller.UserID {
+		return nil
+	}
+	return optional(u.user.Email)
+}
+
+func (u *userResolver) Scores() ([]*scoreResolver, error) {
+	scores, err := u.loadScores()
+	if err != nil {
+		return nil, err
+	}
+	return u.r.scores(scores)
+}
+
+func (u *userResolver) Score(args struct{ Game string }) (*scoreResolver, error) {
+	scores, err := u.loadScores()
+	if err != nil {
+		return nil, err
+	}
+	for i := range scores {
+		score := &scores[i]
+		if !u.r.caller.allowsGame(score.GameID) {
+			continue
+		}
+		if score.GameID == args.Game || score.GameSlug == args.Game {
+			return &scoreResolver{r: u.r, score: score}, nil
+		}
+	}
+	return nil, nil
+}
+
+// loadScores returns the scores of the user. Other users' scores need
+// scores:read, like /users/:id/scores.
+func (u *userResolver) loadScores() ([]domain.Score, error) {
+	if u.user.ID != u.r.caller.UserID && !u.r.caller.has(domain.PermScoresRead) {
+		return nil, fail(domain.ErrForbidden)
+	}
+	scores, err := u.r.userScores.Load(u.user.ID)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return scores, nil
+}
+
+type userPageResolver struct {
+	users []*userResolver
+	page  *domain.UserPage
+}
+
+func (p *userPageResolver) Users() []*userResolver { return p.users }
+func (p *userPageResolver) Total() int32           { return int32(p.page.Total) }
+func (p *userPageResolver) Page() int32            { return int32(p.page.Page) }
+func (p *userPageResolver) PageSize() int32        { return int32(p.page.PageSize) }
+
+const (
+	defaultLeaderboardSize = 10
+	maxLeaderboardSize     = 100
+)
+
+type gameResolver struct {
+	r    *request
+	game *domain.Game
+}
+
+func (g *gameResolver) ID() graphql.ID { return graphql.ID(g.game.ID) }
+func (g *gameResolver) Name() string   { return g.game.Name }
+func (g *gameResolver) Slug() string   { return g.game.Slug }
+
+func (g *gameResolver) ScoresUpdatedAt() *graphql.Time {
+	if g.game.ScoresUpdatedAt.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: g.game.ScoresUpdatedAt}
+}
+
+// Leaderboard returns the best scores of the game: defaultLeaderboardSize
+// unless first says otherwise, and never more than maxLeaderboardSize.
+func (g *gameResolver) Leaderboard(args struct{ First *int32 }) ([]*scoreResolver, error) {
+	scores, err := g.loadScores()
+	if err != nil {
+		return nil, err
+	}
+	first := defaultLeaderboardSize
+	if args.First != nil {
+		first = min(max(int(*args.First), 0), maxLeaderboardSize)
+	}
+	if first < len(scores) {
+		scores = scores[:first]
+	}
+	return g.r.scores(scores)
+}
+
+// Stats are computed from every score of the game, which the leaderboard
+// does not load, so they are looked up on their own like /games/:id/stats.
+func (g *gameResolver) Stats() (*statsResolver, error) {
+	if err := g.canReadScores(); err != nil {
+		return nil, err
+	}
+	stats, err := g.r.services.Scores.GetGameStats(g.game.ID)
+	if errors.Is(err, domain.ErrScoreNotFound) {
+		return &statsResolver{mode: []int{}}, nil
+	}
+	if err != nil {
+		return nil, fail(err)
+	}
+	return &statsResolver{mean: stats.Mean, median: stats.Median, mode: stats.Mode}, nil
+}
+
+// canReadScores tells whether the caller may see the scores of the game.
+// Like /games/:id/scores it needs scores:read, and keys restricted to other
+// games are refused.
+func (g *gameResolver) canReadScores() error {
+	if !g.r.caller.has(domain.PermScoresRead) {
+		return fail(domain.ErrForbidden)
+	}
+	if !g.r.caller.allowsGame(g.game.ID) {
+		return fail(domain.ErrGameNotAllowed)
+	}
+	return nil
+}
+
+// loadScores returns the top of the leaderboard of the game, at most
+// maxLeaderboardSize scores.
+func (g *gameResolver) loadScores() ([]domain.Score, error) {
+	if err := g.canReadScores(); err != nil {
+		return nil, err
+	}
+	scores, err := g.r.gameScores.Load(g.game.ID)
+	if err != nil {
+		return nil, fail(err)
+	}
+	return scores, nil
+}
+
+type scoreResolver struct {
+	r     *request
+	score *domain.Score
+}
+
+func (s *scoreResolver) Points() int32 { return int32(s.score.Points) }
+
+func (s *scoreResolver) User() (*userResolver, error) {
+	return s.r.user(s.score.UserID)
+}
+
+func (s *scoreResolver) Game() (*gameResolver, error) {
+	return s.r.game(s.score.GameID)
+}
+
+type statsResolver struct {
+	mean, median float64
+	mode         []int
+}
+
+func (s *statsResolver) Mean() float64   { return s.mean }
+func (s *statsResolver) Median() float64 { return s.median }
+
+func (s *statsResolver) Mode() []int32 {
+	mode := make([]int32, len(s.mode))
+	for i, m := range s.mode {
+		mode[i] = int32(m)
+	}
+	return mode
+}
+
+// optional maps empty profile fields to null.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
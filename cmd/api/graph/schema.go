@@ -0,0 +1,161 @@
+// Package graph serves users, games, scores and statistics as a GraphQL
+// graph on top of the same services as the REST handlers.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"sync/atomic"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// defaultMaxDepth bounds how deeply queries may nest, e.g.
+// games.leaderboard.user.scores.game...
+const defaultMaxDepth = 8
+
+// defaultMaxNodes bounds how many objects a query may resolve, which depth
+// alone does not: a shallow query can still walk every score of every game.
+const defaultMaxNodes = 10000
+
+// Services are what the graph is resolved with.
+type Services struct {
+	Games    ports.GameService
+	Scores   ports.ScoreService
+	Profiles ports.ProfileService
+}
+
+// Caller is who a query runs for, as set by AuthMiddleware.
+type Caller struct {
+	// UserID is empty for API keys.
+	UserID      string
+	Permissions []domain.Permission
+	APIKey      *domain.APIKey
+}
+
+func (c *Caller) has(perm domain.Permission) bool {
+	return domain.HasPermission(c.Permissions, perm)
+}
+
+// allowsGame tells whether the caller may see the game. Only API keys
+// restricted to a set of games can be refused.
+func (c *Caller) allowsGame(gameID string) bool {
+	return c.APIKey == nil || c.APIKey.AllowsGame(gameID)
+}
+
+type Schema struct {
+	schema   *graphql.Schema
+	services Services
+	maxNodes int64
+}
+
+// NewSchema parses the schema. The maximum query depth is read from
+// GRAPHQL_MAX_DEPTH and the objects a query may resolve from
+// GRAPHQL_MAX_NODES.
+func NewSchema(s Services) *Schema {
+	schema := graphql.MustParseSchema(schemaSDL, &queryResolver{},
+		graphql.MaxDepth(utils.EnvInt("GRAPHQL_MAX_DEPTH", defaultMaxDepth)),
+	)
+	return &Schema{
+		schema:   schema,
+		services: s,
+		maxNodes: int64(utils.EnvInt("GRAPHQL_MAX_NODES", defaultMaxNodes)),
+	}
+}
+
+// Exec runs a query for caller.
+func (s *Schema) Exec(ctx context.Context, caller Caller, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	r := newRequest(s.services, caller)
+	r.nodes.Store(s.maxNodes)
+	ctx = context.WithValue(ctx, requestKey{}, r)
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
+
+// request is the state of one query: the caller and the loaders that batch
+// its lookups.
+type request struct {
+	caller   Caller
+	services Services
+	// nodes is how many more objects the query may resolve.
+	nodes atomic.Int64
+
+	users      *Loader[string, *domain.User]
+	games      *Loader[string, *domain.Game]
+	userScores *Loader[string, []domain.Score]
+	gameScores *Loader[string, []domain.Score]
+}
+
+type requestKey struct{}
+
+// spend takes n objects from the budget of the query. Sibling fields
+// resolve concurrently, so the budget is shared atomically.
+func (r *request) spend(n int) error {
+	if r.nodes.Add(-int64(n)) < 0 {
+		return fail(domain.ErrQueryTooLarge)
+	}
+	return nil
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+func newRequest(s Services, caller Caller) *request {
+	r := &request{caller: caller, services: s}
+	r.users = NewLoader(func(ids []string) (map[string]*domain.User, error) {
+		users, err := s.Profiles.GetUsers(ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*domain.User, len(*users))
+		for i := range *users {
+			byID[(*users)[i].ID] = &(*users)[i]
+		}
+		return byID, nil
+	})
+	r.games = NewLoader(func(ids []string) (map[string]*domain.Game, error) {
+		games, err := s.Games.GetGamesByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*domain.Game, len(*games))
+		for i := range *games {
+			byID[(*games)[i].ID] = &(*games)[i]
+		}
+		return byID, nil
+	})
+	r.userScores = NewLoader(func(ids []string) (map[string][]domain.Score, error) {
+		scores, err := s.Scores.GetScoresByUsers(ids)
+		if err != nil {
+			return nil, err
+		}
+		return groupScores(*scores, func(score *domain.Score) string { return score.UserID }), nil
+	})
+	r.gameScores = NewLoader(func(ids []string) (map[string][]domain.Score, error) {
+		// No leaderboard is longer than maxLeaderboardSize, so neither is
+		// what is read of it.
+		scores, err := s.Scores.GetScoresByGames(ids, maxLeaderboardSize)
+		if err != nil {
+			return nil, err
+		}
+		return groupScores(*scores, func(score *domain.Score) string { return score.GameID }), nil
+	})
+	return r
+}
+
+// groupScores splits scores by key, keeping their order.
+func groupScores(scores []domain.Score, key func(*domain.Score) string) map[string][]domain.Score {
+	groups := map[string][]domain.Score{}
+	for i := range scores {
+		score := &scores[i]
+		k := key(score)
+		groups[k] = append(groups[k], *score)
+	}
+	return groups
+}
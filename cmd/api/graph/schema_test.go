@@ -0,0 +1,284 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/graph"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/dto"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ana = "8c1f2d0e-6a5b-4c3d-9e8f-7a6b5c4d3e2f"
+	bob = "0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9"
+)
+
+var player = []domain.Permission{domain.PermGamesRead, domain.PermScoresRead}
+
+type env struct {
+	schema *graph.Schema
+	gs     *mocks.GameServiceMock
+	ss     *mocks.ScoreServiceMock
+	ps     *mocks.ProfileServiceMock
+}
+
+func newEnv() *env {
+	e := &env{gs: new(mocks.GameServiceMock), ss: new(mocks.ScoreServiceMock), ps: new(mocks.ProfileServiceMock)}
+	e.schema = graph.NewSchema(graph.Services{Games: e.gs, Scores: e.ss, Profiles: e.ps})
+	return e
+}
+
+type result struct {
+	Data   map[string]any
+	Errors []struct {
+		Message    string
+		Extensions map[string]any
+	}
+}
+
+func (e *env) exec(t *testing.T, caller graph.Caller, query string) result {
+	t.Helper()
+	resp := e.schema.Exec(context.Background(), caller, query, "", nil)
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	var res result
+	require.NoError(t, json.Unmarshal(b, &res))
+	return res
+}
+
+func TestLeaderboard_BatchesUsers(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGames").Return(&[]domain.Game{{ID: "g1", Name: "Chess"}, {ID: "g2", Name: "Go"}}, nil)
+	e.ss.On("GetScoresByGames", mock.Anything, 100).Return(&[]domain.Score{
+		{UserID: ana, GameID: "g1", Points: 30},
+		{UserID: bob, GameID: "g1", Points: 20},
+		{UserID: bob, GameID: "g2", Points: 10},
+	}, nil)
+	e.ps.On("GetUsers", mock.Anything).Return(&[]domain.User{{ID: ana, Username: "ana"}, {ID: bob, Username: "bob"}}, nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player},
+		`{ games { name leaderboard { points user { username } } } }`)
+
+	require.Empty(t, res.Errors)
+	games := res.Data["games"].([]any)
+	require.Len(t, games, 2)
+	chess := games[0].(map[string]any)["leaderboard"].([]any)
+	assert.Equal(t, "ana", chess[0].(map[string]any)["user"].(map[string]any)["username"])
+	assert.EqualValues(t, 20, chess[1].(map[string]any)["points"])
+
+	e.ss.AssertNumberOfCalls(t, "GetScoresByGames", 1)
+	// Sibling leaderboards resolve concurrently and may each fetch their
+	// players, but no player is fetched twice.
+	var ids []string
+	for _, call := range e.ps.Calls {
+		ids = append(ids, call.Arguments.Get(0).([]string)...)
+	}
+	assert.ElementsMatch(t, []string{ana, bob}, ids)
+}
+
+func TestLeaderboard_LoadsOnlyReturnedPlayers(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("GetScoresByGames", []string{"g1"}, 100).Return(&[]domain.Score{
+		{UserID: ana, GameID: "g1", Points: 30},
+		{UserID: bob, GameID: "g1", Points: 20},
+	}, nil)
+	e.ps.On("GetUsers", []string{ana}).Return(&[]domain.User{{ID: ana, Username: "ana"}}, nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ game(ref: "chess") { leaderboard(first: 1) { user { username } } } }`)
+
+	require.Empty(t, res.Errors)
+	e.ps.AssertExpectations(t)
+}
+
+func TestMe_OwnScoresWithoutScoresRead(t *testing.T) {
+	e := newEnv()
+	e.ps.On("GetProfile", ana).Return(&domain.User{ID: ana, Username: "ana", Email: "ana@example.com"}, nil)
+	e.ss.On("GetScoresByUsers", []string{ana}).Return(&[]domain.Score{{UserID: ana, GameID: "g1", Points: 30}}, nil)
+	e.ss.On("GetScoresByUsers", mock.Anything).Return(&[]domain.Score{}, nil)
+	e.gs.On("GetGamesByIDs", []string{"g1"}).Return(&[]domain.Game{{ID: "g1", Slug: "chess"}}, nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana}, `{ me { email scores { points game { slug } } } }`)
+
+	require.Empty(t, res.Errors)
+	me := res.Data["me"].(map[string]any)
+	assert.Equal(t, "ana@example.com", me["email"])
+	score := me["scores"].([]any)[0].(map[string]any)
+	assert.Equal(t, "chess", score["game"].(map[string]any)["slug"])
+}
+
+func TestMe_APIKey(t *testing.T) {
+	e := newEnv()
+
+	res := e.exec(t, graph.Caller{APIKey: &domain.APIKey{ID: "k1"}}, `{ me { username } }`)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "user_token_required", res.Errors[0].Extensions["code"])
+	assert.EqualValues(t, 403, res.Errors[0].Extensions["status"])
+}
+
+func TestUser_NeedsScoresRead(t *testing.T) {
+	e := newEnv()
+
+	res := e.exec(t, graph.Caller{UserID: ana}, `{ user(id: "`+bob+`") { username } }`)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "forbidden", res.Errors[0].Extensions["code"])
+	assert.Nil(t, res.Data["user"])
+}
+
+func TestUser_OtherUserHidesEmail(t *testing.T) {
+	e := newEnv()
+	e.ps.On("GetUsers", []string{bob}).Return(&[]domain.User{{ID: bob, Username: "bob", Email: "bob@example.com"}}, nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ user(id: "`+bob+`") { username email } }`)
+
+	require.Empty(t, res.Errors)
+	user := res.Data["user"].(map[string]any)
+	assert.Equal(t, "bob", user["username"])
+	assert.Nil(t, user["email"])
+}
+
+func TestGames_NeedGamesRead(t *testing.T) {
+	e := newEnv()
+
+	res := e.exec(t, graph.Caller{UserID: ana}, `{ games { name } }`)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "forbidden", res.Errors[0].Extensions["code"])
+}
+
+func TestGame_APIKeyOutsideItsGames(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGame", "go").Return(&domain.Game{ID: "g2"}, nil)
+	key := &domain.APIKey{ID: "k1", Scopes: player, GameIDs: []string{"g1"}}
+
+	res := e.exec(t, graph.Caller{Permissions: player, APIKey: key}, `{ game(ref: "go") { name } }`)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "game_not_allowed", res.Errors[0].Extensions["code"])
+}
+
+func TestUserScores_FiltersGamesOfAPIKey(t *testing.T) {
+	e := newEnv()
+	e.ps.On("GetUsers", []string{ana}).Return(&[]domain.User{{ID: ana, Username: "ana"}}, nil)
+	e.ss.On("GetScoresByUsers", []string{ana}).Return(&[]domain.Score{
+		{UserID: ana, GameID: "g1", Points: 30},
+		{UserID: ana, GameID: "g2", Points: 20},
+	}, nil)
+	key := &domain.APIKey{ID: "k1", Scopes: player, GameIDs: []string{"g1"}}
+
+	res := e.exec(t, graph.Caller{Permissions: player, APIKey: key}, `{ user(id: "`+ana+`") { scores { points } } }`)
+
+	require.Empty(t, res.Errors)
+	scores := res.Data["user"].(map[string]any)["scores"].([]any)
+	require.Len(t, scores, 1)
+	assert.EqualValues(t, 30, scores[0].(map[string]any)["points"])
+}
+
+func TestGameStats(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("GetGameStats", "g1").Return(&dto.ScoreStatisticsDTO{Mean: 50.0 / 3, Median: 10, Mode: []int{10}}, nil)
+	e.ss.On("GetScoresByGames", []string{"g1"}, 100).Return(&[]domain.Score{
+		{GameID: "g1", Points: 30}, {GameID: "g1", Points: 10}, {GameID: "g1", Points: 10},
+	}, nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ game(ref: "chess") { stats { median mode } leaderboard(first: 1) { points } } }`)
+
+	require.Empty(t, res.Errors)
+	game := res.Data["game"].(map[string]any)
+	assert.EqualValues(t, 10, game["stats"].(map[string]any)["median"])
+	assert.Equal(t, []any{float64(10)}, game["stats"].(map[string]any)["mode"])
+	assert.Len(t, game["leaderboard"].([]any), 1)
+}
+
+func TestGameStats_NoScores(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("GetGameStats", "g1").Return(nil, domain.ErrScoreNotFound)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ game(ref: "chess") { stats { mean mode } } }`)
+
+	require.Empty(t, res.Errors)
+	stats := res.Data["game"].(map[string]any)["stats"].(map[string]any)
+	assert.EqualValues(t, 0, stats["mean"])
+	assert.Equal(t, []any{}, stats["mode"])
+}
+
+func TestInternalErrorsAreHidden(t *testing.T) {
+	e := newEnv()
+	e.gs.On("GetGames").Return(nil, assert.AnError)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ games { name } }`)
+
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "internal error", res.Errors[0].Message)
+	assert.Equal(t, "internal_error", res.Errors[0].Extensions["code"])
+}
+
+func TestMaxDepth(t *testing.T) {
+	e := newEnv()
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player},
+		`{ games { leaderboard { user { scores { game { leaderboard { user { scores { points } } } } } } } } }`)
+
+	require.NotEmpty(t, res.Errors)
+	assert.Nil(t, res.Data)
+}
+
+// gameScores returns n scores of game g1 by ana, best first.
+func gameScores(n int) *[]domain.Score {
+	scores := make([]domain.Score, n)
+	for i := range scores {
+		scores[i] = domain.Score{UserID: ana, GameID: "g1", Points: n - i}
+	}
+	return &scores
+}
+
+func TestLeaderboard_DefaultAndMaximumSize(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{`leaderboard { points }`, 10},
+		{`leaderboard(first: 50) { points }`, 50},
+		{`leaderboard(first: 1000000) { points }`, 100},
+		{`leaderboard(first: -1) { points }`, 0},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			e := newEnv()
+			e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+			e.ss.On("GetScoresByGames", []string{"g1"}, 100).Return(gameScores(500), nil)
+
+			res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, fmt.Sprintf(`{ game(ref: "chess") { %s } }`, tc.query))
+
+			require.Empty(t, res.Errors)
+			assert.Len(t, res.Data["game"].(map[string]any)["leaderboard"].([]any), tc.want)
+		})
+	}
+}
+
+func TestMaxNodes(t *testing.T) {
+	t.Setenv("GRAPHQL_MAX_NODES", "50")
+	e := newEnv()
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("GetScoresByGames", []string{"g1"}, 100).Return(gameScores(40), nil)
+
+	res := e.exec(t, graph.Caller{UserID: ana, Permissions: player}, `{ game(ref: "chess") { leaderboard(first: 40) { points } } }`)
+	require.Empty(t, res.Errors)
+
+	// Aliases widen a query without nesting it deeper.
+	res = e.exec(t, graph.Caller{UserID: ana, Permissions: player},
+		`{ game(ref: "chess") { a: leaderboard(first: 40) { points } b: leaderboard(first: 40) { points } } }`)
+
+	require.NotEmpty(t, res.Errors)
+	assert.Equal(t, "query_too_large", res.Errors[0].Extensions["code"])
+}
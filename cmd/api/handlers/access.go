@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// apiKeyFrom returns the API key the request was authenticated with, if any.
+func apiKeyFrom(c *gin.Context) *domain.APIKey {
+	value, ok := c.Get("api_key")
+	if !ok {
+		return nil
+	}
+	key, _ := value.(*domain.APIKey)
+	return key
+}
+
+// gameAllowed checks that the caller may act on the game referenced by ref
+// (ID or slug). Only API keys restricted to a set of games can be refused.
+// When it returns false the error response has already been written.
+func gameAllowed(c *gin.Context, gs ports.GameService, ref string) bool {
+	key := apiKeyFrom(c)
+	if key == nil || len(key.GameIDs) == 0 {
+		return true
+	}
+
+	_, ok := allowedGame(c, gs, ref)
+	return ok
+}
+
+// allowedGame is gameAllowed for handlers that need the game anyway: it
+// always loads it.
+func allowedGame(c *gin.Context, gs ports.GameService, ref string) (*domain.Game, bool) {
+	game, err := gs.GetGame(ref)
+	if err != nil {
+		problem.Write(c, err)
+		return nil, false
+	}
+
+	if key := apiKeyFrom(c); key != nil && !key.AllowsGame(game.ID) {
+		log.Warn().Str("api_key_id", key.ID).Str("game_id", game.ID).Msg("api key used outside its games")
+		problem.Write(c, domain.ErrGameNotAllowed)
+		return nil, false
+	}
+	return game, true
+}
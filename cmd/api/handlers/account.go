@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type AccountHandler struct {
+	as ports.AccountService
+}
+
+func NewAccountHandler(as ports.AccountService) *AccountHandler {
+	return &AccountHandler{as: as}
+}
+
+// Export hands out all data stored about the logged in user.
+//
+// @Summary Export personal data
+// @Description Returns the profile, scores, sessions, API keys and linked identities of the current user as a downloadable JSON archive.
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.UserExportResponse
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/export [get]
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	export, err := h.as.Export(userID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	response := dto.UserExportResponse{
+		ExportedAt: export.ExportedAt,
+		Profile:    newProfileResponse(&export.User),
+		Scores:     make([]dto.ScoreResponse, 0, len(export.Scores)),
+		Sessions:   make([]dto.SessionResponse, 0, len(export.Sessions)),
+		APIKeys:    make([]dto.APIKeyResponse, 0, len(export.APIKeys)),
+		Identities: make([]dto.IdentityResponse, 0, len(export.Identities)),
+		Usernames:  newUsernameChangeResponses(export.Usernames),
+	}
+	for i := range export.Scores {
+		response.Scores = append(response.Scores, newScoreResponse(&export.Scores[i]))
+	}
+	for _, session := range export.Sessions {
+		response.Sessions = append(response.Sessions, dto.SessionResponse{
+			ID:        session.ID,
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			RevokedAt: session.RevokedAt,
+		})
+	}
+	for i := range export.APIKeys {
+		response.APIKeys = append(response.APIKeys, newAPIKeyResponse(&export.APIKeys[i]))
+	}
+	for _, identity := range export.Identities {
+		response.Identities = append(response.Identities, dto.IdentityResponse{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s.json"`, userID))
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// Delete removes the account of the logged in user.
+//
+// @Summary Delete own account
+// @Description Deletes the current user after confirming the password. Depending on the server configuration the account is anonymized, keeping its scores under a placeholder name, or deleted with all its data.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.DeleteAccountRequest true "Password confirmation"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} problem.Problem "Invalid request or wrong password"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 409 {object} problem.Problem "Last admin cannot be deleted"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me [delete]
+func (h *AccountHandler) Delete(c *gin.Context) {
+	value, ok := c.Get("claims")
+	if !ok {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+	claims := value.(*domain.AccessClaims)
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid delete account request")
+		problem.Bind(c, err)
+		return
+	}
+
+	if err := h.as.DeleteAccount(claims, req.Password); err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "account deleted successfully"})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type APIKeyHandler struct {
+	ks ports.APIKeyService
+}
+
+func NewAPIKeyHandler(ks ports.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{ks: ks}
+}
+
+// Create issues a new API key.
+//
+// @Summary Create an API key
+// @Description Issues a named API key restricted to the given scopes and, optionally, games. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "API key to create"
+// @Success 201 {object} dto.CreateAPIKeyResponse "API key created"
+// @Failure 400 {object} problem.Problem "Invalid request, scope or expiry"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "Game not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid create api key request")
+		problem.Bind(c, err)
+		return
+	}
+
+	scopes := make([]domain.Permission, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		scopes = append(scopes, domain.Permission(s))
+	}
+
+	key, rawKey, err := h.ks.CreateAPIKey(&domain.APIKey{
+		Name:      req.Name,
+		Scopes:    scopes,
+		CreatedBy: c.GetString("uid"),
+		ExpiresAt: req.ExpiresAt,
+	}, req.Games)
+	if err != nil {
+		log.Warn().Err(err).Str("name", req.Name).Msg("api key could not be created")
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.CreateAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(key),
+		Key:            rawKey,
+	})
+}
+
+// List returns all API keys.
+//
+// @Summary Get list of API keys
+// @Description Retrieves every API key, including revoked and expired ones. Key values are never returned.
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} dto.APIKeyResponse "List of API keys"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.ks.ListAPIKeys()
+	if err != nil {
+		log.Error().Err(err).Msg("error listing api keys")
+		problem.Write(c, err)
+		return
+	}
+
+	var response []dto.APIKeyResponse
+	for _, key := range *keys {
+		response = append(response, newAPIKeyResponse(&key))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke disables an API key.
+//
+// @Summary Revoke an API key
+// @Description Revokes an API key. Requests using it are rejected from then on.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "API key not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	if err := h.ks.RevokeAPIKey(c.Param("id")); err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "api key revoked successfully"})
+}
+
+func newAPIKeyResponse(key *domain.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, s := range key.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	gameIDs := key.GameIDs
+	if gameIDs == nil {
+		gameIDs = []string{}
+	}
+
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		GameIDs:    gameIDs,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type AuthHandler struct {
+	ts ports.TokenService
+}
+
+func NewAuthHandler(ts ports.TokenService) *AuthHandler {
+	return &AuthHandler{ts: ts}
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+//
+// @Summary Refresh tokens
+// @Description Exchanges a refresh token for a new access token and a new refresh token. The presented refresh token is revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh token"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 401 {object} problem.Problem "Invalid or expired refresh token"
+// @Failure 403 {object} problem.Problem "Account banned or suspended"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/refresh [post]
+func (ah *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid refresh request")
+		problem.Bind(c, err)
+		return
+	}
+
+	tokens, err := ah.ts.Refresh(req.RefreshToken)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to refresh tokens")
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// Logout revokes the current access token and, optionally, its refresh token.
+//
+// @Summary Logout
+// @Description Revokes the access token used for the request. If a refresh token is sent it is revoked as well.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} problem.Problem "Called with an API key"
+// @Failure 401 {object} problem.Problem "Invalid access token"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Router /api/v1/auth/logout [post]
+func (ah *AuthHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	// The body is optional, a missing or empty one only revokes the access token.
+	_ = c.ShouldBindJSON(&req)
+
+	value, ok := c.Get("claims")
+	if !ok {
+		// API keys have no session to end.
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+	claims := value.(*domain.AccessClaims)
+	if err := ah.ts.Logout(claims, req.RefreshToken); err != nil {
+		log.Error().Err(err).Str("user_id", claims.UserID).Msg("failed to logout")
+		problem.Write(c, err)
+		return
+	}
+
+	log.Info().Str("user_id", claims.UserID).Msg("user logged out")
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "logged out successfully"})
+}
+
+func newLoginResponse(tokens *domain.TokenPair) dto.LoginResponse {
+	return dto.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+
+		MustChangePassword: tokens.MustChangePassword,
+	}
+}
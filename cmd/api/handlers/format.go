@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/export"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// responseFormat negotiates the format of the response. When it returns
+// false the error response has already been written.
+func responseFormat(c *gin.Context) (export.Format, bool) {
+	// The same URL answers in several formats.
+	c.Header("Vary", "Accept")
+
+	format, err := export.Negotiate(c)
+	switch {
+	case errors.Is(err, export.ErrUnknownFormat):
+		problem.Invalid(c, problem.FieldError{
+			Field:   export.FormatParam,
+			Code:    "oneof",
+			Message: "must be one of json csv ndjson",
+		})
+		return "", false
+	case err != nil:
+		problem.Write(c, err)
+		return "", false
+	}
+	return format, true
+}
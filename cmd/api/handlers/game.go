@@ -1,12 +1,11 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
-	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -22,42 +21,43 @@ func NewGameHandler(gs ports.GameService) *GameHandler {
 // Create creates a new game.
 //
 // @Summary Create a new game
-// @Description Adds a new game to the system with a unique name.
+// @Description Adds a new game to the system with a unique name. The slug is derived from the name unless one is given.
 // @Tags games
 // @Accept json
 // @Produce json
 // @Param request body dto.CreateRequest true "Game to create"
+// @Param Idempotency-Key header string false "Key to retry the request safely with"
 // @Success 201 {object} dto.GameResponse "Game created successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 409 {object} map[string]string "Game already exists"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 409 {object} problem.Problem "Game or slug already exists, or a request with the same Idempotency-Key in progress"
+// @Failure 422 {object} problem.Problem "Idempotency-Key used for a different request"
+// @Failure 413 {object} problem.Problem "Body over 1 MiB sent with an Idempotency-Key"
+// @Failure 500 {object} problem.Problem "Internal server error"
 // @Security BearerAuth
-// @Router /api/games [post]
+// @Security APIKeyAuth
+// @Router /api/v1/games [post]
 func (h *GameHandler) Create(c *gin.Context) {
 
 	var createReq dto.CreateRequest
 
-	if err := c.BindJSON(&createReq); err != nil {
+	if err := c.ShouldBindJSON(&createReq); err != nil {
 		log.Warn().Err(err).Msg("invalid input for game creation")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		problem.Bind(c, err)
 		return
 	}
 
-	createdGame, err := h.gs.CreateGame(createReq.Name)
+	createdGame, err := h.gs.CreateGame(createReq.Name, createReq.Slug)
 	if err != nil {
 		log.Warn().Err(err).Str("name", createReq.Name).Msg("game could not be created")
-		if errors.Is(err, domain.ErrGameAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrGameAlreadyExists.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed creating game"})
+		problem.Write(c, err)
 		return
 	}
 
-	log.Info().Str("game_id", createdGame.ID).Str("game_name", createdGame.Name).Msg("game created successfully")
+	log.Info().Str("game_id", createdGame.ID).Str("game_name", createdGame.Name).Str("slug", createdGame.Slug).Msg("game created successfully")
 	c.JSON(http.StatusCreated, dto.GameResponse{
 		ID:   createdGame.ID,
 		Name: createdGame.Name,
+		Slug: createdGame.Slug,
 	})
 }
 
@@ -68,13 +68,13 @@ func (h *GameHandler) Create(c *gin.Context) {
 // @Tags games
 // @Produce json
 // @Success 200 {array} dto.GameResponse "List of games"
-// @Failure 500 {object} map[string]string "Internal server error"
-// @Router /api/games [get]
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /api/v1/games [get]
 func (h *GameHandler) List(c *gin.Context) {
 	games, err := h.gs.GetGames()
 	if err != nil {
 		log.Error().Err(err).Msg("error listing games")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		problem.Write(c, err)
 		return
 	}
 
@@ -83,8 +83,42 @@ func (h *GameHandler) List(c *gin.Context) {
 		response = append(response, dto.GameResponse{
 			ID:   game.ID,
 			Name: game.Name,
+			Slug: game.Slug,
 		})
 	}
 	log.Info().Int("game_count", len(*games)).Msg("games listed successfully")
 	c.JSON(http.StatusOK, response)
 }
+
+// Get returns a game.
+//
+// @Summary Get a game
+// @Description Retrieves a game by ID or slug.
+// @Tags games
+// @Produce json
+// @Param id path string true "Game ID or slug"
+// @Success 200 {object} dto.GameResponse
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "Game not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/games/{id} [get]
+func (h *GameHandler) Get(c *gin.Context) {
+	if !gameAllowed(c, h.gs, c.Param("id")) {
+		return
+	}
+
+	game, err := h.gs.GetGame(c.Param("id"))
+	if err != nil {
+		log.Warn().Err(err).Str("game_id", c.Param("id")).Msg("game could not be retrieved")
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GameResponse{
+		ID:   game.ID,
+		Name: game.Name,
+		Slug: game.Slug,
+	})
+}
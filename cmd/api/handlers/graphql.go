@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/graph"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type GraphQLHandler struct {
+	schema *graph.Schema
+}
+
+func NewGraphQLHandler(schema *graph.Schema) *GraphQLHandler {
+	return &GraphQLHandler{schema: schema}
+}
+
+// Query runs a GraphQL query.
+//
+// @Summary Run a GraphQL query
+// @Description Queries users, games, scores and statistics as a graph. Every field applies the permissions of the matching REST endpoint; errors are returned in the errors list with the REST error code in their extensions.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body dto.GraphQLRequest true "Query"
+// @Success 200 {object} dto.GraphQLResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req dto.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid graphql request")
+		problem.Bind(c, err)
+		return
+	}
+	h.exec(c, &req)
+}
+
+// QueryGet runs a GraphQL query sent in the URL, which lets caches and
+// browsers handle queries like other GETs.
+//
+// @Summary Run a GraphQL query
+// @Description Same as POST /api/v1/graphql, with the request in the query string.
+// @Tags graphql
+// @Produce json
+// @Param query query string true "Query"
+// @Param operationName query string false "Operation to run"
+// @Param variables query string false "Variables, as a JSON object"
+// @Success 200 {object} dto.GraphQLResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/graphql [get]
+func (h *GraphQLHandler) QueryGet(c *gin.Context) {
+	if missing := requiredQuery(c, "query"); len(missing) > 0 {
+		problem.Invalid(c, missing...)
+		return
+	}
+
+	req := dto.GraphQLRequest{Query: c.Query("query"), OperationName: c.Query("operationName")}
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			problem.Invalid(c, problem.FieldError{Field: "variables", Code: "json", Message: "must be a JSON object"})
+			return
+		}
+	}
+	h.exec(c, &req)
+}
+
+func (h *GraphQLHandler) exec(c *gin.Context, req *dto.GraphQLRequest) {
+	value, _ := c.Get("permissions")
+	permissions, _ := value.([]domain.Permission)
+	caller := graph.Caller{UserID: c.GetString("uid"), Permissions: permissions, APIKey: apiKeyFrom(c)}
+
+	resp := h.schema.Exec(c.Request.Context(), caller, req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type GuestHandler struct {
+	gs ports.GuestService
+}
+
+func NewGuestHandler(gs ports.GuestService) *GuestHandler {
+	return &GuestHandler{gs: gs}
+}
+
+// Login signs in the guest of a device.
+//
+// @Summary Login as a guest
+// @Description Returns tokens for the guest account of the device, creating it on the first call. Guests have no password and play as regular players.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.GuestLoginRequest true "Device identifier"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Account banned or suspended"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/guest [post]
+func (h *GuestHandler) Login(c *gin.Context) {
+	var req dto.GuestLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid guest login request")
+		problem.Bind(c, err)
+		return
+	}
+
+	tokens, err := h.gs.LoginGuest(req.DeviceID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// Upgrade turns the logged in guest into a registered account.
+//
+// @Summary Upgrade a guest account
+// @Description Gives the current guest a username, a password and optionally an email. The account keeps its ID and scores; the device identifier no longer signs it in.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.UpgradeGuestRequest true "Credentials of the account"
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 400 {object} problem.Problem "Invalid request, username or password against the policy"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "Not a guest, username taken, reserved or held, or email already exists"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/upgrade [post]
+func (h *GuestHandler) Upgrade(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	var req dto.UpgradeGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid guest upgrade request")
+		problem.Bind(c, err)
+		return
+	}
+
+	user, err := h.gs.Upgrade(userID, req.Username, req.Email, req.Password)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+// Merge moves the scores of a guest into the logged in account.
+//
+// @Summary Merge a guest into the own account
+// @Description Moves the scores of the guest of a device into the current account and deletes the guest, in one transaction. Where both played a game the higher score is kept.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.MergeGuestRequest true "Device of the guest"
+// @Success 200 {array} dto.ScoreResponse "Scores of the account after the merge"
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "Guest not found"
+// @Failure 409 {object} problem.Problem "Account cannot take guest scores"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/merge [post]
+func (h *GuestHandler) Merge(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	var req dto.MergeGuestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid guest merge request")
+		problem.Bind(c, err)
+		return
+	}
+
+	scores, err := h.gs.Merge(userID, req.DeviceID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	response := make([]dto.ScoreResponse, 0, len(*scores))
+	for i := range *scores {
+		response = append(response, newScoreResponse(&(*scores)[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/export"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ImportHandler struct {
+	is ports.ImportService
+}
+
+func NewImportHandler(is ports.ImportService) *ImportHandler {
+	return &ImportHandler{is: is}
+}
+
+// Import loads users, games or scores from a CSV or NDJSON file.
+//
+// @Summary Import users, games or scores
+// @Description Reads a CSV file with a header row or an NDJSON file, as told by Content-Type, and writes it in batches, each in its own transaction.
+// @Description Invalid rows are rejected and reported while the rest are imported. With dry_run nothing is written but the report is the same.
+// @Description CSV columns: users `username,email,password_hash`; games `name,slug`; scores `user_id|username,game_id|game_slug,points`. NDJSON objects use the same names.
+// @Description Password hashes must be bcrypt or argon2id; users imported without one have to reset their password.
+// @Description Asking for text/csv or application/x-ndjson answers with the rejected rows instead of the report.
+// @Tags imports
+// @Accept text/csv,application/x-ndjson
+// @Produce json,text/csv,application/x-ndjson
+// @Param kind path string true "What the file holds" Enums(users, games, scores)
+// @Param conflict query string false "What to do with rows that already exist, keep-best by default" Enums(keep-best, overwrite, skip)
+// @Param dry_run query bool false "Validate and report without writing"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Param file body string true "The file"
+// @Success 200 {object} dto.ImportReportResponse
+// @Failure 400 {object} problem.Problem "Invalid file or options"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/imports/{kind} [post]
+func (h *ImportHandler) Import(c *gin.Context) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			problem.Invalid(c, problem.FieldError{Field: "dry_run", Code: "boolean", Message: "must be true or false"})
+			return
+		}
+	}
+
+	input, err := importFormat(c.ContentType())
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	report, err := h.is.Import(c.Request.Context(), c.Request.Body, domain.ImportOptions{
+		Kind:     domain.ImportKind(c.Param("kind")),
+		Format:   input,
+		Conflict: domain.ConflictMode(c.Query("conflict")),
+		DryRun:   dryRun,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("kind", c.Param("kind")).Msg("import failed")
+		problem.Write(c, err)
+		return
+	}
+
+	response := newImportReportResponse(report)
+	if format == export.JSON {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// The rejected rows, to fix and import again.
+	filename := fmt.Sprintf("%s-rejected.%s", report.Kind, format)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	enc := export.NewEncoder(c, format, dto.ImportRowErrorResponse{})
+	for _, rowErr := range response.Errors {
+		if err := enc.Encode(rowErr); err != nil {
+			log.Error().Err(err).Msg("rejected rows could not be written")
+			return
+		}
+	}
+	if err := enc.Close(); err != nil {
+		log.Error().Err(err).Msg("rejected rows could not be written")
+	}
+}
+
+// importFormat tells the format of the file from its content type.
+func importFormat(contentType string) (domain.ImportFormat, error) {
+	switch contentType {
+	case "text/csv":
+		return domain.ImportCSV, nil
+	case "application/x-ndjson", "application/jsonl":
+		return domain.ImportNDJSON, nil
+	}
+	return "", fmt.Errorf("%w: Content-Type must be text/csv or application/x-ndjson", domain.ErrInvalidImport)
+}
+
+func newImportReportResponse(report *domain.ImportReport) dto.ImportReportResponse {
+	response := dto.ImportReportResponse{
+		Kind:            string(report.Kind),
+		Conflict:        string(report.Conflict),
+		DryRun:          report.DryRun,
+		Rows:            report.Rows,
+		Created:         report.Created,
+		Updated:         report.Updated,
+		Skipped:         report.Skipped,
+		Rejected:        report.Rejected,
+		Errors:          make([]dto.ImportRowErrorResponse, 0, len(report.Errors)),
+		ErrorsTruncated: report.ErrorsTruncated,
+	}
+	for _, rowErr := range report.Errors {
+		response.Errors = append(response.Errors, dto.ImportRowErrorResponse{
+			Line:    rowErr.Line,
+			Field:   rowErr.Field,
+			Message: rowErr.Message,
+			Row:     rowErr.Row,
+		})
+	}
+	return response
+}
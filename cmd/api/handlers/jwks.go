@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type JWKSHandler struct {
+	ks ports.KeyService
+}
+
+func NewJWKSHandler(ks ports.KeyService) *JWKSHandler {
+	return &JWKSHandler{ks: ks}
+}
+
+// Get publishes the public keys access tokens are signed with.
+//
+// @Summary JSON Web Key Set
+// @Description Returns the public keys used to sign access tokens, so other services can verify them. Tokens carry the key ID in their kid header.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.JWKSResponse
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) Get(c *gin.Context) {
+	keys, err := h.ks.JWKS()
+	if err != nil {
+		log.Error().Err(err).Msg("error building jwks")
+		problem.Write(c, err)
+		return
+	}
+
+	response := dto.JWKSResponse{Keys: make([]dto.JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, dto.JSONWebKey{
+			Kty: key.KeyType,
+			Kid: key.KeyID,
+			Use: key.Use,
+			Alg: key.Algorithm,
+			N:   key.Modulus,
+			E:   key.Exponent,
+			Crv: key.Curve,
+			X:   key.X,
+		})
+	}
+
+	// Verifiers may cache the set: keys are published well before they sign.
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(domain.JWKSMaxAge.Seconds())))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+type LockoutHandler struct {
+	ls ports.LockoutService
+}
+
+func NewLockoutHandler(ls ports.LockoutService) *LockoutHandler {
+	return &LockoutHandler{ls: ls}
+}
+
+// Unlock lifts the login lockout of a user.
+//
+// @Summary Unlock a user
+// @Description Clears the failed login attempts of a user, ending any lockout. Lockouts of client IPs expire on their own.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/lockout [delete]
+func (h *LockoutHandler) Unlock(c *gin.Context) {
+	if err := h.ls.Unlock(c.Param("id")); err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "user unlocked successfully"})
+}
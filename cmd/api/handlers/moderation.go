@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ModerationHandler struct {
+	ms ports.ModerationService
+}
+
+func NewModerationHandler(ms ports.ModerationService) *ModerationHandler {
+	return &ModerationHandler{ms: ms}
+}
+
+// Promote makes a user an admin.
+//
+// @Summary Promote a user to admin
+// @Description Gives an active user the admin role. The new permissions apply from the user's next access token.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ModerationRequest true "Reason"
+// @Success 200 {object} dto.ModerationActionResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "User is already an admin, banned or suspended"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/promote [post]
+func (h *ModerationHandler) Promote(c *gin.Context) {
+	h.act(c, h.ms.Promote)
+}
+
+// Demote turns an admin back into a player.
+//
+// @Summary Demote an admin
+// @Description Replaces the admin role of a user with the player role. The last admin cannot be demoted.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ModerationRequest true "Reason"
+// @Success 200 {object} dto.ModerationActionResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "User is not an admin or is the last admin"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/demote [post]
+func (h *ModerationHandler) Demote(c *gin.Context) {
+	h.act(c, h.ms.Demote)
+}
+
+// Suspend keeps a user from signing in until the given time.
+//
+// @Summary Suspend a user
+// @Description Refuses logins and access tokens of the user until the given time and ends their sessions. Admins must be demoted first.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.SuspendRequest true "Reason and end of the suspension"
+// @Success 200 {object} dto.ModerationActionResponse
+// @Failure 400 {object} problem.Problem "Invalid request or suspension ending in the past"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "User is an admin"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/suspend [post]
+func (h *ModerationHandler) Suspend(c *gin.Context) {
+	var req dto.SuspendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid suspend request")
+		problem.Bind(c, err)
+		return
+	}
+
+	action, err := h.ms.Suspend(actor(c), c.Param("id"), req.Until, req.Reason)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", c.Param("id")).Msg("moderation action failed")
+		problem.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newModerationActionResponse(action))
+}
+
+// Ban keeps a user from signing in for good.
+//
+// @Summary Ban a user
+// @Description Refuses logins and access tokens of the user, ends their sessions and hides their scores from leaderboards and statistics. The scores are kept. Admins must be demoted first.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ModerationRequest true "Reason"
+// @Success 200 {object} dto.ModerationActionResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "User is an admin"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/ban [post]
+func (h *ModerationHandler) Ban(c *gin.Context) {
+	h.act(c, h.ms.Ban)
+}
+
+// Reinstate lifts the suspension or the ban of a user.
+//
+// @Summary Reinstate a user
+// @Description Lifts a suspension or a ban. The scores of a banned user show up again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ModerationRequest true "Reason"
+// @Success 200 {object} dto.ModerationActionResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/reinstate [post]
+func (h *ModerationHandler) Reinstate(c *gin.Context) {
+	h.act(c, h.ms.Reinstate)
+}
+
+// History lists the moderation actions taken on a user.
+//
+// @Summary Get the moderation history of a user
+// @Description Lists promotions, demotions, suspensions, bans and reinstatements of a user, newest first.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} dto.ModerationActionResponse
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/moderation [get]
+func (h *ModerationHandler) History(c *gin.Context) {
+	actions, err := h.ms.History(c.Param("id"))
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", c.Param("id")).Msg("moderation action failed")
+		problem.Write(c, err)
+		return
+	}
+
+	response := make([]dto.ModerationActionResponse, 0, len(*actions))
+	for i := range *actions {
+		response = append(response, newModerationActionResponse(&(*actions)[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// act runs a moderation action that only takes a reason.
+func (h *ModerationHandler) act(c *gin.Context, action func(actorID, userID, reason string) (*domain.ModerationAction, error)) {
+	var req dto.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid moderation request")
+		problem.Bind(c, err)
+		return
+	}
+
+	taken, err := action(actor(c), c.Param("id"), req.Reason)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", c.Param("id")).Msg("moderation action failed")
+		problem.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newModerationActionResponse(taken))
+}
+
+// actor is who a moderation action is recorded for: the user, or the API key
+// as api_key:<id>.
+func actor(c *gin.Context) string {
+	if key := apiKeyFrom(c); key != nil {
+		return "api_key:" + key.ID
+	}
+	return c.GetString("uid")
+}
+
+func newModerationActionResponse(action *domain.ModerationAction) dto.ModerationActionResponse {
+	return dto.ModerationActionResponse{
+		ID:        action.ID,
+		UserID:    action.UserID,
+		ActorID:   action.ActorID,
+		Action:    action.Action,
+		Reason:    action.Reason,
+		Until:     action.Until,
+		CreatedAt: action.CreatedAt,
+	}
+}
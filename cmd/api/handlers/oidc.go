@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// oidcBindingCookie ties a login to the browser that started it. The callback
+// is a redirect from the provider, a top-level navigation, which SameSite=Lax
+// cookies are sent with.
+const oidcBindingCookie = "oidc_binding"
+
+type OIDCHandler struct {
+	os ports.OIDCService
+}
+
+func NewOIDCHandler(os ports.OIDCService) *OIDCHandler {
+	return &OIDCHandler{os: os}
+}
+
+// setBinding stores the binding of a login in the browser, or clears it with
+// an empty value.
+func setBinding(c *gin.Context, binding string) {
+	cookie := &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    binding,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https"),
+		SameSite: http.SameSiteLaxMode,
+	}
+	if binding == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// Login sends the player to the identity provider.
+//
+// @Summary Log in with an identity provider
+// @Description Starts an OpenID Connect authorization code flow with PKCE and redirects to the provider's login page. The login is bound to the browser by the oidc_binding cookie, which the callback needs.
+// @Tags auth
+// @Param provider path string true "Provider name, as configured in OIDC_PROVIDERS"
+// @Success 302 "Redirect to the provider"
+// @Failure 404 {object} problem.Problem "Unknown provider"
+// @Failure 502 {object} problem.Problem "Provider unavailable"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	redirect, err := h.os.AuthCodeURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	setBinding(c, redirect.Binding)
+	c.Redirect(http.StatusFound, redirect.URL)
+}
+
+// Link starts linking an identity provider account to the logged in user.
+//
+// @Summary Link an identity provider account
+// @Description Starts an OpenID Connect login whose callback links the provider account to the current user instead of signing in with it. Accounts are never linked by email, since local emails are not verified.
+// @Description Returns the provider URL to send the user to; the callback answers with a new token pair. An account already linked to another user is refused with identity_linked.
+// @Description The response sets the oidc_binding cookie, so it has to be requested from the browser that then opens the URL: the callback refuses links completed in another browser.
+// @Tags users
+// @Produce json
+// @Param provider path string true "Provider name, as configured in OIDC_PROVIDERS"
+// @Success 200 {object} dto.LinkIdentityResponse
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "Unknown provider"
+// @Failure 502 {object} problem.Problem "Provider unavailable"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/identities/{provider} [post]
+func (h *OIDCHandler) Link(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	redirect, err := h.os.LinkURL(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	setBinding(c, redirect.Binding)
+	c.JSON(http.StatusOK, dto.LinkIdentityResponse{URL: redirect.URL})
+}
+
+// Callback completes a login started with Login or Link.
+//
+// @Summary Identity provider callback
+// @Description The provider redirects here after the player logged in. The user the account is linked to is signed in; an unknown account gets a new user, or is linked to the user who started the login with the link endpoint. A token pair is returned.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State sent to the provider"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem "Missing code, invalid state or login started in another browser"
+// @Failure 401 {object} problem.Problem "Login failed at the provider"
+// @Failure 409 {object} problem.Problem "Account already linked to another user"
+// @Failure 403 {object} problem.Problem "Account banned or suspended"
+// @Failure 404 {object} problem.Problem "Unknown provider"
+// @Failure 502 {object} problem.Problem "Provider unavailable"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		log.Info().Str("provider", c.Param("provider")).Str("error", providerErr).Msg("identity provider returned an error")
+		problem.Write(c, domain.ErrOIDCLoginFailed)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		problem.Invalid(c, requiredQuery(c, "code", "state")...)
+		return
+	}
+
+	// The binding is single use, like the state.
+	binding, _ := c.Cookie(oidcBindingCookie)
+	setBinding(c, "")
+
+	tokens, err := h.os.Callback(c.Request.Context(), c.Param("provider"), code, state, binding)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
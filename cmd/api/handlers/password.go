@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type PasswordHandler struct {
+	ps ports.PasswordService
+}
+
+func NewPasswordHandler(ps ports.PasswordService) *PasswordHandler {
+	return &PasswordHandler{ps: ps}
+}
+
+// Change replaces the password of the logged in user.
+//
+// @Summary Change password
+// @Description Changes the password of the current user. Every other session is ended and a new token pair is returned.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} problem.Problem "Invalid request, wrong current password or new password against the policy"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/password [put]
+func (ph *PasswordHandler) Change(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid change password request")
+		problem.Bind(c, err)
+		return
+	}
+
+	tokens, err := ph.ps.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// Forgot sends a password reset token to the user's email.
+//
+// @Summary Forgot password
+// @Description Sends a one-time reset token to the email of the account. The response is the same whether the account exists or not.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Username or email"
+// @Success 202 {object} dto.SuccessResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/password/forgot [post]
+func (ph *PasswordHandler) Forgot(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid forgot password request")
+		problem.Bind(c, err)
+		return
+	}
+
+	if err := ph.ps.RequestPasswordReset(req.Login); err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.SuccessResponse{Message: "if the account exists, a reset token was sent to its email"})
+}
+
+// Reset sets a new password using a reset token.
+//
+// @Summary Reset password
+// @Description Sets a new password with a token received by email. The token can only be used once and every session of the user is ended.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} problem.Problem "Invalid request or token, or new password against the policy"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/password/reset [post]
+func (ph *PasswordHandler) Reset(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid reset password request")
+		problem.Bind(c, err)
+		return
+	}
+
+	if err := ph.ps.ResetPassword(req.Token, req.NewPassword); err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "password reset successfully"})
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type ProfileHandler struct {
+	ps ports.ProfileService
+}
+
+func NewProfileHandler(ps ports.ProfileService) *ProfileHandler {
+	return &ProfileHandler{ps: ps}
+}
+
+// GetMe returns the profile of the logged in user.
+//
+// @Summary Get own profile
+// @Description Returns the profile of the current user, including private fields such as the email.
+// @Tags users
+// @Produce json
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me [get]
+func (h *ProfileHandler) GetMe(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	user, err := h.ps.GetProfile(userID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+// UpdateMe changes the profile of the logged in user.
+//
+// @Summary Update own profile
+// @Description Updates the fields present in the body. An empty string clears a field. The country is an ISO 3166-1 alpha-2 code.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateProfileRequest true "Profile fields"
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me [patch]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid update profile request")
+		problem.Bind(c, err)
+		return
+	}
+
+	user, err := h.ps.UpdateProfile(userID, &domain.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Country:     req.Country,
+		Bio:         req.Bio,
+	})
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+// GetPublic returns the public profile of a user.
+//
+// @Summary Get a player's profile
+// @Description Returns the public profile of a user with a summary of their scores. Private fields such as the email are left out.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.PublicProfileResponse
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/users/{id} [get]
+func (h *ProfileHandler) GetPublic(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		problem.Write(c, domain.ErrUserNotFound)
+		return
+	}
+
+	profile, err := h.ps.GetPublicProfile(userID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	// Keys restricted to some games only see the scores of those games.
+	key := apiKeyFrom(c)
+
+	var scores []domain.Score
+	for _, score := range profile.Scores {
+		if score.Points <= 0 || (key != nil && !key.AllowsGame(score.GameID)) {
+			continue
+		}
+		scores = append(scores, score)
+	}
+
+	user := profile.User
+	response := dto.PublicProfileResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Country:     user.Country,
+		Bio:         user.Bio,
+		CreatedAt:   user.CreatedAt,
+		Scores:      []dto.ScoreResponse{},
+	}
+	for _, score := range scores {
+		response.Scores = append(response.Scores, newScoreResponse(&score))
+	}
+
+	summary := domain.SummarizeScores(scores)
+	response.Summary = dto.ScoreSummaryResponse{
+		GamesPlayed: summary.GamesPlayed,
+		TotalPoints: summary.TotalPoints,
+	}
+	if summary.Best != nil {
+		best := newScoreResponse(summary.Best)
+		response.Summary.Best = &best
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// List returns a page of users.
+//
+// @Summary List users
+// @Description Lists users ordered by username. The search matches username, display name and email.
+// @Tags users
+// @Produce json
+// @Param search query string false "Text to search for"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param page_size query int false "Users per page" default(20)
+// @Success 200 {object} dto.UserListResponse
+// @Failure 400 {object} problem.Problem "Invalid paging"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users [get]
+func (h *ProfileHandler) List(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		problem.Invalid(c, problem.FieldError{Field: "page", Code: "type", Message: "must be an integer"})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "0"))
+	if err != nil {
+		problem.Invalid(c, problem.FieldError{Field: "page_size", Code: "type", Message: "must be an integer"})
+		return
+	}
+
+	result, err := h.ps.ListUsers(&domain.UserQuery{
+		Search:   c.Query("search"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	response := dto.UserListResponse{
+		Items:    make([]dto.ProfileResponse, 0, len(result.Users)),
+		Total:    result.Total,
+		Page:     result.Page,
+		PageSize: result.PageSize,
+	}
+	for i := range result.Users {
+		response.Items = append(response.Items, newProfileResponse(&result.Users[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Rename changes the username of the logged in user.
+//
+// @Summary Change own username
+// @Description Renames the current user. Usernames are unique regardless of case; a rename is allowed once per cooldown and the old username stays held for a while so nobody else can take it. Access tokens carry the new username from the next refresh.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.RenameRequest true "New username"
+// @Success 200 {object} dto.ProfileResponse
+// @Failure 400 {object} problem.Problem "Invalid request or username"
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "Username taken, reserved or held"
+// @Failure 429 {object} problem.Problem "Renamed too recently, see Retry-After"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/username [put]
+func (h *ProfileHandler) Rename(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+
+	var req dto.RenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid rename request")
+		problem.Bind(c, err)
+		return
+	}
+
+	user, err := h.ps.Rename(userID, req.Username)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileResponse(user))
+}
+
+// UsernameHistory lists the usernames a user had before.
+//
+// @Summary Get the username history of a user
+// @Description Lists the usernames a user gave up, newest first.
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} dto.UsernameChangeResponse
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/usernames [get]
+func (h *ProfileHandler) UsernameHistory(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		problem.Write(c, domain.ErrUserNotFound)
+		return
+	}
+
+	history, err := h.ps.UsernameHistory(userID)
+	if err != nil {
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUsernameChangeResponses(*history))
+}
+
+func newUsernameChangeResponses(changes []domain.UsernameChange) []dto.UsernameChangeResponse {
+	response := make([]dto.UsernameChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		response = append(response, dto.UsernameChangeResponse{
+			Username:  change.Username,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+	return response
+}
+
+func newProfileResponse(user *domain.User) dto.ProfileResponse {
+	// A suspension that ran out is no longer reported.
+	status := user.Status
+	if status.Check(time.Now()) == nil {
+		status = domain.AccountStatus{State: domain.StatusActive}
+	}
+
+	return dto.ProfileResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        user.Role,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Country:     user.Country,
+		Bio:         user.Bio,
+		CreatedAt:   user.CreatedAt,
+
+		Guest:          user.IsGuest,
+		Status:         status.State,
+		StatusReason:   status.Reason,
+		SuspendedUntil: status.SuspendedUntil,
+	}
+}
+
+func newScoreResponse(score *domain.Score) dto.ScoreResponse {
+	return dto.ScoreResponse{
+		UserID:   score.UserID,
+		Username: score.Username,
+		GameID:   score.GameID,
+		GameSlug: score.GameSlug,
+		GameName: score.GameName,
+		Points:   score.Points,
+	}
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// requiredQuery lists the given query parameters missing from the request.
+func requiredQuery(c *gin.Context, names ...string) []problem.FieldError {
+	var missing []problem.FieldError
+	for _, name := range names {
+		if c.Query(name) == "" {
+			missing = append(missing, problem.FieldError{Field: name, Code: "required", Message: "is required"})
+		}
+	}
+	return missing
+}
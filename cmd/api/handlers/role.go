@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type RoleHandler struct {
+	rs ports.RoleService
+}
+
+func NewRoleHandler(rs ports.RoleService) *RoleHandler {
+	return &RoleHandler{rs: rs}
+}
+
+// List returns all roles with their permissions.
+//
+// @Summary Get list of roles
+// @Description Retrieves every role and the permissions it grants.
+// @Tags roles
+// @Produce json
+// @Success 200 {array} dto.RoleResponse "List of roles"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/roles [get]
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.rs.ListRoles()
+	if err != nil {
+		log.Error().Err(err).Msg("error listing roles")
+		problem.Write(c, err)
+		return
+	}
+
+	var response []dto.RoleResponse
+	for _, role := range *roles {
+		perms := make([]string, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			perms = append(perms, string(p))
+		}
+		response = append(response, dto.RoleResponse{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: perms,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Assign sets the role of a user.
+//
+// @Summary Assign a role to a user
+// @Description Replaces the role of a user. The new permissions apply from the user's next access token.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role to assign"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} problem.Problem "Invalid request or unknown role"
+// @Failure 403 {object} problem.Problem "Forbidden"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 409 {object} problem.Problem "Last admin cannot be demoted"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security BearerAuth
+// @Router /api/v1/users/{id}/role [put]
+func (h *RoleHandler) Assign(c *gin.Context) {
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Warn().Err(err).Msg("invalid assign role request")
+		problem.Bind(c, err)
+		return
+	}
+
+	userID := c.Param("id")
+	if err := h.rs.AssignRole(userID, req.Role); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("role", req.Role).Msg("role could not be assigned")
+		problem.Write(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{Message: "role assigned successfully"})
+}
@@ -1,48 +1,62 @@
 package handlers
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/export"
+	"github.com/Martin-Arias/go-scoring-api/internal/httpcache"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 type ScoreHandler struct {
 	ss ports.ScoreService
+	gs ports.GameService
 }
 
-func NewScoreHandler(ss ports.ScoreService) *ScoreHandler {
-	return &ScoreHandler{ss: ss}
+func NewScoreHandler(ss ports.ScoreService, gs ports.GameService) *ScoreHandler {
+	return &ScoreHandler{ss: ss, gs: gs}
 }
 
 // Submit submits or updates a user's score for a game.
 //
 // @Summary Submit a score
-// @Description Submits or updates the score for a user in a specific game
+// @Description Submits or updates the score for a user in a specific game, identified by ID or slug
 // @Tags scores
 // @Accept json
 // @Produce json
 // @Param request body dto.SubmitScoreRequest true "Score data"
+// @Param Idempotency-Key header string false "Key to retry the request safely with"
 // @Success 201 {object} map[string]string "Score submitted successfully"
-// @Failure 400 {object} map[string]string "Invalid request"
-// @Failure 404 {object} map[string]string "User or game not found"
-// @Failure 409 {object} map[string]string "Score not allowed"
-// @Failure 500 {object} map[string]string "Internal error"
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "User or game not found"
+// @Failure 409 {object} problem.Problem "Score not allowed, or a request with the same Idempotency-Key in progress"
+// @Failure 422 {object} problem.Problem "Idempotency-Key used for a different request"
+// @Failure 413 {object} problem.Problem "Body over 1 MiB sent with an Idempotency-Key"
+// @Failure 500 {object} problem.Problem "Internal error"
 // @Security BearerAuth
-// @Router /api/scores [put]
+// @Security APIKeyAuth
+// @Router /api/v1/scores [put]
 func (h *ScoreHandler) Submit(c *gin.Context) {
 	var req dto.SubmitScoreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Warn().Err(err).Msg("invalid submit score request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		problem.Bind(c, err)
 		return
 	}
 	log.Debug().Str("user_id", req.UserID).Str("game_id", req.GameID).Int("points", req.Points).Msg("submitting score")
 
+	if !gameAllowed(c, h.gs, req.GameID) {
+		return
+	}
+
 	err := h.ss.Submit(&domain.Score{
 		GameID: req.GameID,
 		UserID: req.UserID,
@@ -51,153 +65,398 @@ func (h *ScoreHandler) Submit(c *gin.Context) {
 
 	if err != nil {
 		log.Warn().Err(err).Any("req", req).Msg("score could not be submitted")
-		switch {
-		case errors.Is(err, domain.ErrScoreNotAllowed):
-			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrScoreNotAllowed.Error()})
-			return
-
-		case errors.Is(err, domain.ErrGameNotFound), errors.Is(err, domain.ErrUserNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed submitting score"})
-			return
-		}
+		problem.Write(c, err)
+		return
 	}
 
 	log.Info().Str("user_id", req.UserID).Str("game_id", req.GameID).Int("points", req.Points).Msg("score submitted successfully")
 	c.JSON(http.StatusCreated, gin.H{"message": "score submitted successfully"})
 }
 
+// ListGameScores returns the scores of a game.
+//
+// @Summary Get scores of a game
+// @Description Lists the user scores of a game, identified by ID or slug
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param id path string true "Game ID or slug"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {array} dto.ScoreResponse
+// @Header 200 {string} ETag "Version of the leaderboard"
+// @Header 200 {string} Last-Modified "Time of the last change to the leaderboard"
+// @Success 304 "Not modified"
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "Game not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/games/{id}/scores [get]
+func (h *ScoreHandler) ListGameScores(c *gin.Context) {
+	h.gameScores(c, c.Param("id"))
+}
+
 // GetGameScores returns all scores for a given game.
 //
 // @Summary Get scores by game
-// @Description Lists user scores for a specific game
+// @Description Lists user scores for a specific game. Use GET /api/v1/games/{id}/scores instead.
 // @Tags scores
-// @Produce json
-// @Param game_id query string true "Game ID"
+// @Produce json,text/csv,application/x-ndjson
+// @Param game_id query string true "Game ID or slug"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
 // @Success 200 {array} dto.ScoreResponse
-// @Failure 400 {object} map[string]string "Invalid game ID"
-// @Failure 404 {object} map[string]string "Game or scores not found"
-// @Failure 500 {object} map[string]string "Internal error"
+// @Header 200 {string} ETag "Version of the leaderboard"
+// @Header 200 {string} Last-Modified "Time of the last change to the leaderboard"
+// @Success 304 "Not modified"
+// @Failure 400 {object} problem.Problem "Invalid game ID"
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "Game or scores not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
 // @Security BearerAuth
-// @Router /api/scores/game [get]
+// @Security APIKeyAuth
+// @Deprecated
+// @Router /api/v1/scores/game [get]
 func (h *ScoreHandler) GetGameScores(c *gin.Context) {
 	gameID := c.Query("game_id")
 	if gameID == "" {
 		log.Warn().Msg("missing game_id in query")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
+		problem.Invalid(c, requiredQuery(c, "game_id")...)
 		return
 	}
+	h.gameScores(c, gameID)
+}
 
-	scores, err := h.ss.GetGameScores(gameID)
+func (h *ScoreHandler) gameScores(c *gin.Context, gameID string) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	game, ok := allowedGame(c, h.gs, gameID)
+	if !ok || httpcache.NotModified(c, leaderboardValidators(game, format)) {
+		return
+	}
+
+	if format != export.JSON {
+		streamScores(c, format, func(fn func(*domain.Score) error) error {
+			return h.ss.StreamGameScores(c.Request.Context(), game.ID, fn)
+		})
+		return
+	}
+
+	scores, err := h.ss.GetGameScores(game.ID)
 	if err != nil {
 		log.Warn().Err(err).Msg("game scores could not be retrieved")
-		if errors.Is(err, domain.ErrGameNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrGameNotFound.Error()})
-			return
-		}
-		if errors.Is(err, domain.ErrScoreNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrScoreNotFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed retrieving game scores"})
+		problem.Write(c, err)
 		return
 	}
 
-	var response []dto.ScoreResponse
+	response := []dto.ScoreResponse{}
 	for _, score := range *scores {
-		response = append(response, dto.ScoreResponse{
-			UserID:   score.UserID,
-			Username: score.Username,
-			GameID:   score.GameID,
-			GameName: score.GameName,
-			Points:   score.Points,
-		})
+		response = append(response, newScoreResponse(&score))
 	}
 
 	log.Info().Str("game_id", gameID).Int("count", len(*scores)).Msg("scores retrieved successfully")
 	c.JSON(http.StatusOK, response)
 }
 
+// ListUserScores returns the scores of a user.
+//
+// @Summary Get scores of a user
+// @Description Lists the game scores of a user
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param id path string true "User ID"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {array} dto.ScoreResponse
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/users/{id}/scores [get]
+func (h *ScoreHandler) ListUserScores(c *gin.Context) {
+	h.userScores(c, c.Param("id"))
+}
+
+// ListMyScores returns the scores of the authenticated user.
+//
+// @Summary Get my scores
+// @Description Lists the game scores of the authenticated user
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {array} dto.ScoreResponse
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/scores [get]
+func (h *ScoreHandler) ListMyScores(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+	h.userScores(c, userID)
+}
+
 // GetUserScores returns all scores for a specific User.
 //
 // @Summary Get scores by user
-// @Description Lists game scores for a specific user
+// @Description Lists game scores for a specific user. Use GET /api/v1/users/{id}/scores instead.
 // @Tags scores
-// @Produce json
+// @Produce json,text/csv,application/x-ndjson
 // @Param user_id query string true "User ID"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
 // @Success 200 {array} dto.ScoreResponse
-// @Failure 400 {object} map[string]string "Invalid user ID"
-// @Failure 404 {object} map[string]string "User not found"
-// @Failure 500 {object} map[string]string "Internal error"
+// @Failure 400 {object} problem.Problem "Invalid user ID"
+// @Failure 404 {object} problem.Problem "User not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
 // @Security BearerAuth
-// @Router /api/scores/user [get]
+// @Security APIKeyAuth
+// @Deprecated
+// @Router /api/v1/scores/user [get]
 func (h *ScoreHandler) GetUserScores(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
 		log.Warn().Msg("missing user_id in query")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		problem.Invalid(c, requiredQuery(c, "user_id")...)
+		return
+	}
+	h.userScores(c, userID)
+}
+
+func (h *ScoreHandler) userScores(c *gin.Context, userID string) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		problem.Write(c, domain.ErrUserNotFound)
+		return
+	}
+
+	// Keys restricted to some games only see the scores of those games.
+	key := apiKeyFrom(c)
+	visible := func(score *domain.Score) bool {
+		return key == nil || key.AllowsGame(score.GameID)
+	}
+
+	if format != export.JSON {
+		streamScores(c, format, func(fn func(*domain.Score) error) error {
+			return h.ss.StreamUserScores(c.Request.Context(), userID, func(score *domain.Score) error {
+				if !visible(score) {
+					return nil
+				}
+				return fn(score)
+			})
+		})
 		return
 	}
 
 	scores, err := h.ss.GetUserScores(userID)
 	if err != nil {
 		log.Warn().Err(err).Msg("user scores could not be retrieved")
-		if errors.Is(err, domain.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrUserNotFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed retrieving user scores"})
+		problem.Write(c, err)
 		return
 	}
 
-	var response []dto.ScoreResponse
+	response := []dto.ScoreResponse{}
 	for _, score := range *scores {
-		response = append(response, dto.ScoreResponse{
-			UserID:   score.UserID,
-			Username: score.Username,
-			GameID:   score.GameID,
-			GameName: score.GameName,
-			Points:   score.Points,
-		})
+		if !visible(&score) {
+			continue
+		}
+		response = append(response, newScoreResponse(&score))
 	}
 
 	log.Info().Str("user_id", userID).Int("count", len(*scores)).Msg("user scores retrieved successfully")
 	c.JSON(http.StatusOK, response)
 }
 
-// GetStatisticsByGameID returns score statistics (mean, median, mode) for a game.
+// GetUserGameScore returns the score of a user in a game.
+//
+// @Summary Get the score of a user in a game
+// @Description Returns the score of a user in a game, identified by ID or slug
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param id path string true "User ID"
+// @Param gameId path string true "Game ID or slug"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {object} dto.ScoreResponse
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "User, game or score not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/users/{id}/games/{gameId}/score [get]
+func (h *ScoreHandler) GetUserGameScore(c *gin.Context) {
+	h.userGameScore(c, c.Param("id"), c.Param("gameId"))
+}
+
+// GetMyGameScore returns the score of the authenticated user in a game.
+//
+// @Summary Get my score in a game
+// @Description Returns the score of the authenticated user in a game, identified by ID or slug
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param gameId path string true "Game ID or slug"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {object} dto.ScoreResponse
+// @Failure 403 {object} problem.Problem "Not a user token"
+// @Failure 404 {object} problem.Problem "Game or score not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Router /api/v1/users/me/games/{gameId}/score [get]
+func (h *ScoreHandler) GetMyGameScore(c *gin.Context) {
+	userID := c.GetString("uid")
+	if userID == "" {
+		problem.Write(c, domain.ErrUserTokenRequired)
+		return
+	}
+	h.userGameScore(c, userID, c.Param("gameId"))
+}
+
+func (h *ScoreHandler) userGameScore(c *gin.Context, userID, gameID string) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		problem.Write(c, domain.ErrUserNotFound)
+		return
+	}
+
+	if !gameAllowed(c, h.gs, gameID) {
+		return
+	}
+
+	score, err := h.ss.GetUserGameScore(userID, gameID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("game_id", gameID).Msg("user game score could not be retrieved")
+		problem.Write(c, err)
+		return
+	}
+	export.Write(c, http.StatusOK, format, newScoreResponse(score))
+}
+
+// GameStats returns score statistics (mean, median, mode) for a game.
 //
 // @Summary Get game score statistics
-// @Description Calculates mean, median, and mode for a game's scores
+// @Description Calculates mean, median, and mode for the scores of a game, identified by ID or slug
 // @Tags scores
-// @Produce json
-// @Param game_id query string true "Game ID"
+// @Produce json,text/csv,application/x-ndjson
+// @Param id path string true "Game ID or slug"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
+// @Success 200 {object} dto.ScoreStatisticsDTO
+// @Header 200 {string} ETag "Version of the leaderboard"
+// @Header 200 {string} Last-Modified "Time of the last change to the leaderboard"
+// @Success 304 "Not modified"
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "Game or scores not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Security BearerAuth
+// @Security APIKeyAuth
+// @Router /api/v1/games/{id}/stats [get]
+func (h *ScoreHandler) GameStats(c *gin.Context) {
+	h.gameStats(c, c.Param("id"))
+}
+
+// GetGameStats returns score statistics (mean, median, mode) for a game.
+//
+// @Summary Get game score statistics by query
+// @Description Calculates mean, median, and mode for a game's scores. Use GET /api/v1/games/{id}/stats instead.
+// @Tags scores
+// @Produce json,text/csv,application/x-ndjson
+// @Param game_id query string true "Game ID or slug"
+// @Param If-None-Match header string false "ETag of a cached response"
+// @Param format query string false "Response format, overrides Accept" Enums(json, csv, ndjson)
 // @Success 200 {object} dto.ScoreStatisticsDTO
-// @Failure 400 {object} map[string]string "Invalid game ID"
-// @Failure 404 {object} map[string]string "No scores found"
-// @Failure 500 {object} map[string]string "Internal error"
+// @Header 200 {string} ETag "Version of the leaderboard"
+// @Header 200 {string} Last-Modified "Time of the last change to the leaderboard"
+// @Success 304 "Not modified"
+// @Failure 400 {object} problem.Problem "Invalid game ID"
+// @Failure 403 {object} problem.Problem "Game not allowed for this API key"
+// @Failure 404 {object} problem.Problem "Game or scores not found"
+// @Failure 406 {object} problem.Problem "None of the accepted formats is supported"
+// @Failure 500 {object} problem.Problem "Internal error"
 // @Security BearerAuth
-// @Router /api/scores/game/stats [get]
+// @Security APIKeyAuth
+// @Deprecated
+// @Router /api/v1/scores/game/stats [get]
 func (h *ScoreHandler) GetGameStats(c *gin.Context) {
 	gameID := c.Query("game_id")
 	if gameID == "" {
 		log.Warn().Msg("missing game_id in query")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid game ID"})
+		problem.Invalid(c, requiredQuery(c, "game_id")...)
+		return
+	}
+	h.gameStats(c, gameID)
+}
+
+func (h *ScoreHandler) gameStats(c *gin.Context, gameID string) {
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+
+	game, ok := allowedGame(c, h.gs, gameID)
+	if !ok || httpcache.NotModified(c, leaderboardValidators(game, format)) {
 		return
 	}
 
-	stats, err := h.ss.GetGameStats(gameID)
+	stats, err := h.ss.GetGameStats(game.ID)
 	if err != nil {
 		log.Warn().Err(err).Msg("game stats could not be retrieved")
-		if errors.Is(err, domain.ErrScoreNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrScoreNotFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed retrieving game stats"})
+		problem.Write(c, err)
+		return
+	}
+	export.Write(c, http.StatusOK, format, stats)
+}
+
+// leaderboardValidators identify the version of the leaderboard of game. The
+// version is read before the scores, so a submission in between only costs
+// the client one more full response.
+func leaderboardValidators(game *domain.Game, format export.Format) httpcache.Validators {
+	etag := fmt.Sprintf("%s.%d", game.ID, game.Version)
+	if format != export.JSON {
+		etag += "." + string(format)
+	}
+	return httpcache.Validators{
+		ETag:         `W/"` + etag + `"`,
+		LastModified: game.ScoresUpdatedAt,
+	}
+}
+
+// streamScores writes the scores stream hands over as they are read. Once
+// the first row has been sent an error can no longer be answered, so it is
+// only logged and the export ends early.
+func streamScores(c *gin.Context, format export.Format, stream func(fn func(*domain.Score) error) error) {
+	enc := export.NewEncoder(c, format, dto.ScoreResponse{})
+	err := stream(func(score *domain.Score) error {
+		return enc.Encode(newScoreResponse(score))
+	})
+	if err == nil {
+		err = enc.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !enc.Started() {
+		problem.Write(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, stats)
+	log.Error().Err(err).Str("path", c.Request.URL.Path).Msg("score export interrupted")
 }
@@ -1,12 +1,11 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
-	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -22,33 +21,32 @@ func NewUserHandler(us ports.UserService) *UserHandler {
 // Register creates a new user account.
 //
 // @Summary Register a new user
-// @Description Creates a user with a username and password
+// @Description Creates a user with a username, a password and, optionally, an email used to reset a forgotten password
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body dto.AuthRequest true "User credentials"
+// @Param request body dto.RegisterRequest true "User credentials"
+// @Param Idempotency-Key header string false "Key to retry the request safely with"
 // @Success 201 {object} dto.RegisterResponse "User registered successfully"
-// @Failure 400 {object} map[string]interface{} "error: Invalid request"
-// @Failure 409 {object} map[string]interface{} "error: Username already exists"
-// @Failure 500 {object} map[string]interface{} "error: Internal error"
-// @Router /auth/register [post]
+// @Failure 400 {object} problem.Problem "Invalid request, username or password against the policy"
+// @Failure 409 {object} problem.Problem "Username taken, reserved or held, email already exists, or a request with the same Idempotency-Key in progress"
+// @Failure 422 {object} problem.Problem "Idempotency-Key used for a different request"
+// @Failure 413 {object} problem.Problem "Body over 1 MiB sent with an Idempotency-Key"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/register [post]
 func (uh *UserHandler) Register(c *gin.Context) {
-	var req dto.AuthRequest
+	var req dto.RegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Warn().Err(err).Msg("invalid register request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		problem.Bind(c, err)
 		return
 	}
 
-	createdUser, err := uh.us.RegisterUser(req.Username, req.Password)
+	createdUser, err := uh.us.RegisterUser(req.Username, req.Email, req.Password)
 	if err != nil {
 		log.Error().Err(err).Str("user_name", req.Username).Msg("failed to register user")
-		if errors.Is(err, domain.ErrUsernameAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrUsernameAlreadyExists.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error registering user"})
+		problem.Write(c, err)
 		return
 	}
 
@@ -61,38 +59,33 @@ func (uh *UserHandler) Register(c *gin.Context) {
 // Login authenticates a user and returns a JWT.
 //
 // @Summary Login user
-// @Description Authenticates a user and returns a JWT token
+// @Description Authenticates a user and returns a short-lived JWT access token together with a refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dto.AuthRequest true "User credentials"
 // @Success 200 {object} dto.LoginResponse
-// @Failure 400 {object} map[string]interface{} "error: Invalid request"
-// @Failure 404 {object} map[string]interface{} "error: User not found"
-// @Failure 500 {object} map[string]interface{} "error: Internal error"
-// @Router /auth/login [post]
+// @Failure 400 {object} problem.Problem "Invalid request"
+// @Failure 401 {object} problem.Problem "Unknown user or wrong password (invalid_credentials)"
+// @Failure 403 {object} problem.Problem "Account banned or suspended"
+// @Failure 429 {object} problem.Problem "Too many failed attempts, see Retry-After"
+// @Failure 500 {object} problem.Problem "Internal error"
+// @Router /api/v1/auth/login [post]
 func (uh *UserHandler) Login(c *gin.Context) {
 	var req dto.AuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Warn().Err(err).Msg("invalid login request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		problem.Bind(c, err)
 		return
 	}
 
-	token, err := uh.us.LoginUser(req.Username, req.Password)
+	tokens, err := uh.us.LoginUser(req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		log.Warn().Err(err).Str("username", req.Username).Msg("failed to login user")
-
-		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrAuthInvalid) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrAuthInvalid.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		problem.Write(c, err)
 		return
 	}
 
 	log.Info().Str("username", req.Username).Msg("user logged in successfully")
-	c.JSON(http.StatusOK, dto.LoginResponse{
-		Token: token,
-	})
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
 }
@@ -0,0 +1,178 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// caller is who a call was authenticated as: a user, through the claims of
+// an access token, or an API key.
+type caller struct {
+	claims      *domain.AccessClaims
+	apiKey      *domain.APIKey
+	permissions []domain.Permission
+}
+
+type callerKey struct{}
+
+func callerFrom(ctx context.Context) *caller {
+	c, _ := ctx.Value(callerKey{}).(*caller)
+	return c
+}
+
+// userID returns the ID of the calling user, or fails for API keys.
+func (c *caller) userID() (string, error) {
+	if c == nil || c.claims == nil {
+		return "", domain.ErrUserTokenRequired
+	}
+	return c.claims.UserID, nil
+}
+
+// allowsGame tells whether the caller may act on the game. Only API keys
+// restricted to a set of games can be refused.
+func (c *caller) allowsGame(gameID string) bool {
+	return c == nil || c.apiKey == nil || c.apiKey.AllowsGame(gameID)
+}
+
+func (c *caller) has(perm domain.Permission) bool {
+	return c != nil && domain.HasPermission(c.permissions, perm)
+}
+
+// access is what a method asks of the caller.
+type access struct {
+	// public methods need no credentials.
+	public bool
+	// perms must all be held by the caller.
+	perms []domain.Permission
+	// duringPasswordChange lets through users that still have to replace a
+	// handed-out password.
+	duringPasswordChange bool
+}
+
+// methods lists the access rules of every method, matching the routes of the
+// REST API. Methods not listed are refused.
+var methods = map[string]access{
+	scoringv1.AuthService_Register_FullMethodName: {public: true},
+	scoringv1.AuthService_Login_FullMethodName:    {public: true},
+	scoringv1.AuthService_Refresh_FullMethodName:  {public: true},
+	scoringv1.AuthService_Logout_FullMethodName:   {duringPasswordChange: true},
+
+	scoringv1.GameService_CreateGame_FullMethodName: {perms: []domain.Permission{domain.PermGamesRead, domain.PermGamesCreate}},
+	scoringv1.GameService_ListGames_FullMethodName:  {perms: []domain.Permission{domain.PermGamesRead}},
+	scoringv1.GameService_GetGame_FullMethodName:    {perms: []domain.Permission{domain.PermGamesRead}},
+
+	scoringv1.ScoreService_SubmitScore_FullMethodName: {perms: []domain.Permission{domain.PermScoresRead, domain.PermScoresSubmit}},
+	// scores:read is checked by the methods, as "me" doesn't need it.
+	scoringv1.ScoreService_ListUserScores_FullMethodName:   {},
+	scoringv1.ScoreService_GetUserGameScore_FullMethodName: {},
+
+	scoringv1.LeaderboardService_GetLeaderboard_FullMethodName:    {perms: []domain.Permission{domain.PermGamesRead, domain.PermScoresRead}},
+	scoringv1.LeaderboardService_StreamLeaderboard_FullMethodName: {perms: []domain.Permission{domain.PermGamesRead, domain.PermScoresRead}},
+	scoringv1.LeaderboardService_GetGameStats_FullMethodName:      {perms: []domain.Permission{domain.PermGamesRead, domain.PermScoresRead}},
+}
+
+// publicServices are infrastructure services open to everyone.
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// authorizer does for gRPC what AuthMiddleware, RequirePasswordChanged and
+// RequirePermission do for the REST API.
+type authorizer struct {
+	ts ports.TokenService
+	ks ports.APIKeyService
+}
+
+func (a *authorizer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return handler(ctx, req)
+}
+
+func (a *authorizer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return toStatus(err)
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize authenticates the caller and checks the access rules of method.
+// The caller is added to the returned context.
+func (a *authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+	rule, ok := methods[method]
+	if !ok {
+		return ctx, domain.ErrForbidden
+	}
+	if rule.public {
+		return ctx, nil
+	}
+
+	c, err := a.authenticate(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	if c.claims != nil && c.claims.MustChangePassword && !rule.duringPasswordChange {
+		return ctx, domain.ErrPasswordChangeRequired
+	}
+	for _, perm := range rule.perms {
+		if !c.has(perm) {
+			return ctx, domain.ErrForbidden
+		}
+	}
+	return context.WithValue(ctx, callerKey{}, c), nil
+}
+
+// authenticate reads an API key from the x-api-key metadata or a bearer
+// access token from authorization.
+func (a *authorizer) authenticate(ctx context.Context) (*caller, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if rawKey := first(md, "x-api-key"); rawKey != "" {
+		key, err := a.ks.Authenticate(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		return &caller{apiKey: key, permissions: key.Scopes}, nil
+	}
+
+	token := first(md, "authorization")
+	if token == "" {
+		return nil, domain.ErrAuthRequired
+	}
+	if scheme, value, ok := strings.Cut(token, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		token = value
+	}
+	claims, err := a.ts.ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &caller{claims: claims, permissions: claims.Permissions}, nil
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// authorizedStream carries the context with the caller to stream handlers.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/rs/zerolog/log"
+)
+
+type authServer struct {
+	scoringv1.UnimplementedAuthServiceServer
+	us ports.UserService
+	ts ports.TokenService
+}
+
+func (s *authServer) Register(ctx context.Context, req *scoringv1.RegisterRequest) (*scoringv1.RegisterResponse, error) {
+	if err := validate(&dto.RegisterRequest{Username: req.Username, Email: req.Email, Password: req.Password}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	user, err := s.us.RegisterUser(req.Username, req.Email, req.Password)
+	if err != nil {
+		log.Error().Err(err).Str("user_name", req.Username).Msg("failed to register user")
+		return nil, toStatus(err)
+	}
+	return &scoringv1.RegisterResponse{Id: user.ID, Username: user.Username}, nil
+}
+
+func (s *authServer) Login(ctx context.Context, req *scoringv1.LoginRequest) (*scoringv1.TokenResponse, error) {
+	if err := validate(&dto.AuthRequest{Username: req.Username, Password: req.Password}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	tokens, err := s.us.LoginUser(req.Username, req.Password, clientIP(ctx))
+	if err != nil {
+		log.Warn().Err(err).Str("username", req.Username).Msg("failed to login user")
+		return nil, toStatus(err)
+	}
+	return newTokenResponse(tokens), nil
+}
+
+func (s *authServer) Refresh(ctx context.Context, req *scoringv1.RefreshRequest) (*scoringv1.TokenResponse, error) {
+	if err := validate(&dto.RefreshRequest{RefreshToken: req.RefreshToken}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	tokens, err := s.ts.Refresh(req.RefreshToken)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to refresh tokens")
+		return nil, toStatus(err)
+	}
+	return newTokenResponse(tokens), nil
+}
+
+func (s *authServer) Logout(ctx context.Context, req *scoringv1.LogoutRequest) (*scoringv1.LogoutResponse, error) {
+	c := callerFrom(ctx)
+	if c.claims == nil {
+		// API keys have no session to end.
+		return nil, toStatus(domain.ErrUserTokenRequired)
+	}
+	if err := s.ts.Logout(c.claims, req.RefreshToken); err != nil {
+		log.Error().Err(err).Str("user_id", c.claims.UserID).Msg("failed to logout")
+		return nil, toStatus(err)
+	}
+	return &scoringv1.LogoutResponse{}, nil
+}
+
+func newTokenResponse(tokens *domain.TokenPair) *scoringv1.TokenResponse {
+	return &scoringv1.TokenResponse{
+		AccessToken:        tokens.AccessToken,
+		RefreshToken:       tokens.RefreshToken,
+		ExpiresIn:          int64(tokens.ExpiresIn.Seconds()),
+		MustChangePassword: tokens.MustChangePassword,
+	}
+}
@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/rs/zerolog/log"
+)
+
+type gameServer struct {
+	scoringv1.UnimplementedGameServiceServer
+	gs ports.GameService
+}
+
+func (s *gameServer) CreateGame(ctx context.Context, req *scoringv1.CreateGameRequest) (*scoringv1.Game, error) {
+	if err := validate(&dto.CreateRequest{Name: req.Name, Slug: req.Slug}); err != nil {
+		return nil, toStatus(err)
+	}
+
+	game, err := s.gs.CreateGame(req.Name, req.Slug)
+	if err != nil {
+		log.Warn().Err(err).Str("name", req.Name).Msg("game could not be created")
+		return nil, toStatus(err)
+	}
+	log.Info().Str("game_id", game.ID).Str("slug", game.Slug).Msg("game created successfully")
+	return newGame(game), nil
+}
+
+func (s *gameServer) ListGames(ctx context.Context, req *scoringv1.ListGamesRequest) (*scoringv1.ListGamesResponse, error) {
+	games, err := s.gs.GetGames()
+	if err != nil {
+		log.Error().Err(err).Msg("error listing games")
+		return nil, toStatus(err)
+	}
+
+	resp := &scoringv1.ListGamesResponse{Games: make([]*scoringv1.Game, 0, len(*games))}
+	for _, game := range *games {
+		resp.Games = append(resp.Games, newGame(&game))
+	}
+	return resp, nil
+}
+
+func (s *gameServer) GetGame(ctx context.Context, req *scoringv1.GetGameRequest) (*scoringv1.Game, error) {
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return newGame(game), nil
+}
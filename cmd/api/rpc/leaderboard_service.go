@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/rs/zerolog/log"
+)
+
+type leaderboardServer struct {
+	scoringv1.UnimplementedLeaderboardServiceServer
+	ss ports.ScoreService
+	gs ports.GameService
+}
+
+func (s *leaderboardServer) GetLeaderboard(ctx context.Context, req *scoringv1.GetLeaderboardRequest) (*scoringv1.Leaderboard, error) {
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	scores, err := s.ss.GetGameScores(game.ID)
+	if err != nil {
+		log.Warn().Err(err).Msg("game scores could not be retrieved")
+		return nil, toStatus(err)
+	}
+
+	resp := &scoringv1.Leaderboard{Game: newGame(game), Scores: make([]*scoringv1.Score, 0, len(*scores))}
+	for _, score := range *scores {
+		resp.Scores = append(resp.Scores, newScore(&score))
+	}
+	return resp, nil
+}
+
+func (s *leaderboardServer) StreamLeaderboard(req *scoringv1.GetLeaderboardRequest, stream scoringv1.LeaderboardService_StreamLeaderboardServer) error {
+	ctx := stream.Context()
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	err = s.ss.StreamGameScores(ctx, game.ID, func(score *domain.Score) error {
+		return stream.Send(newScore(score))
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("game_id", game.ID).Msg("leaderboard stream ended early")
+		return toStatus(err)
+	}
+	return nil
+}
+
+func (s *leaderboardServer) GetGameStats(ctx context.Context, req *scoringv1.GetGameStatsRequest) (*scoringv1.GameStats, error) {
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	stats, err := s.ss.GetGameStats(game.ID)
+	if err != nil {
+		log.Warn().Err(err).Msg("game stats could not be retrieved")
+		return nil, toStatus(err)
+	}
+
+	resp := &scoringv1.GameStats{
+		GameId:   stats.GameID,
+		GameSlug: stats.GameSlug,
+		GameName: stats.GameName,
+		Mean:     stats.Mean,
+		Median:   stats.Median,
+		Mode:     make([]int64, 0, len(stats.Mode)),
+	}
+	for _, mode := range stats.Mode {
+		resp.Mode = append(resp.Mode, int64(mode))
+	}
+	return resp, nil
+}
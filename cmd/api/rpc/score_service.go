@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// me stands for the calling user in user IDs.
+const me = "me"
+
+type scoreServer struct {
+	scoringv1.UnimplementedScoreServiceServer
+	ss ports.ScoreService
+	gs ports.GameService
+}
+
+func (s *scoreServer) SubmitScore(ctx context.Context, req *scoringv1.SubmitScoreRequest) (*scoringv1.SubmitScoreResponse, error) {
+	submit := dto.SubmitScoreRequest{UserID: req.UserId, GameID: req.Game, Points: int(req.Points)}
+	if err := validate(&submit); err != nil {
+		return nil, toStatus(err)
+	}
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	submit.GameID = game.ID
+
+	err = s.ss.Submit(&domain.Score{GameID: submit.GameID, UserID: submit.UserID, Points: submit.Points})
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", submit.UserID).Str("game_id", submit.GameID).Msg("score could not be submitted")
+		return nil, toStatus(err)
+	}
+	log.Info().Str("user_id", submit.UserID).Str("game_id", submit.GameID).Int("points", submit.Points).Msg("score submitted successfully")
+	return &scoringv1.SubmitScoreResponse{}, nil
+}
+
+func (s *scoreServer) ListUserScores(ctx context.Context, req *scoringv1.ListUserScoresRequest) (*scoringv1.ListScoresResponse, error) {
+	userID, err := resolveUser(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	scores, err := s.ss.GetUserScores(userID)
+	if err != nil {
+		log.Warn().Err(err).Msg("user scores could not be retrieved")
+		return nil, toStatus(err)
+	}
+
+	// Keys restricted to some games only see the scores of those games.
+	c := callerFrom(ctx)
+	resp := &scoringv1.ListScoresResponse{Scores: make([]*scoringv1.Score, 0, len(*scores))}
+	for _, score := range *scores {
+		if c.allowsGame(score.GameID) {
+			resp.Scores = append(resp.Scores, newScore(&score))
+		}
+	}
+	return resp, nil
+}
+
+func (s *scoreServer) GetUserGameScore(ctx context.Context, req *scoringv1.GetUserGameScoreRequest) (*scoringv1.Score, error) {
+	userID, err := resolveUser(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	game, err := allowedGame(ctx, s.gs, req.Game)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	score, err := s.ss.GetUserGameScore(userID, game.ID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return newScore(score), nil
+}
+
+// resolveUser returns the ID of the user a request is about. Other users
+// need the scores:read permission, like /users/:id on the REST API.
+func resolveUser(ctx context.Context, userID string) (string, error) {
+	c := callerFrom(ctx)
+	if userID == me {
+		return c.userID()
+	}
+	if !c.has(domain.PermScoresRead) {
+		return "", domain.ErrForbidden
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		return "", domain.ErrUserNotFound
+	}
+	return userID, nil
+}
@@ -0,0 +1,153 @@
+// Package rpc serves the gRPC API defined in proto/scoring/v1 on top of the
+// same services as the REST handlers.
+package rpc
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/metrics"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Services are what the gRPC API is served with.
+type Services struct {
+	Users  ports.UserService
+	Tokens ports.TokenService
+	Games  ports.GameService
+	Scores ports.ScoreService
+	Keys   ports.APIKeyService
+}
+
+// NewServer returns a gRPC server with every service of the API registered,
+// along with the health and reflection services.
+func NewServer(s Services) *grpc.Server {
+	auth := &authorizer{ts: s.Tokens, ks: s.Keys}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary, observeUnary, auth.unary),
+		grpc.ChainStreamInterceptor(recoverStream, observeStream, auth.stream),
+	)
+
+	scoringv1.RegisterAuthServiceServer(server, &authServer{us: s.Users, ts: s.Tokens})
+	scoringv1.RegisterGameServiceServer(server, &gameServer{gs: s.Games})
+	scoringv1.RegisterScoreServiceServer(server, &scoreServer{ss: s.Scores, gs: s.Games})
+	scoringv1.RegisterLeaderboardServiceServer(server, &leaderboardServer{ss: s.Scores, gs: s.Games})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+	return server
+}
+
+// recoverUnary turns a panic in a call into an internal error, as
+// gin.CustomRecovery does for REST, instead of letting it take the process
+// down.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(method string, r any) error {
+	log.Error().Str("method", method).Interface("panic", r).Bytes("stack", debug.Stack()).Msg("grpc call panicked")
+	metrics.GRPCRequests.WithLabelValues(method, codes.Internal.String()).Inc()
+	return status.Error(codes.Internal, "internal error")
+}
+
+// observeUnary logs and counts unary calls, as RequestMetricsMiddleware does
+// for REST.
+func observeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+func observeStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observe(info.FullMethod, start, err)
+	return err
+}
+
+func observe(method string, start time.Time, err error) {
+	code := status.Code(err)
+	metrics.GRPCRequests.WithLabelValues(method, code.String()).Inc()
+	log.Debug().Str("method", method).Str("code", code.String()).Dur("duration", time.Since(start)).Msg("grpc call")
+}
+
+// validate checks a request against the binding rules of the matching REST
+// request.
+func validate(req any) error {
+	return binding.Validator.ValidateStruct(req)
+}
+
+// allowedGame loads the game referenced by ref (ID or slug) and checks that
+// the caller may act on it.
+func allowedGame(ctx context.Context, gs ports.GameService, ref string) (*domain.Game, error) {
+	game, err := gs.GetGame(ref)
+	if err != nil {
+		return nil, err
+	}
+	if c := callerFrom(ctx); !c.allowsGame(game.ID) {
+		log.Warn().Str("api_key_id", c.apiKey.ID).Str("game_id", game.ID).Msg("api key used outside its games")
+		return nil, domain.ErrGameNotAllowed
+	}
+	return game, nil
+}
+
+// clientIP is the address of the peer, which lockouts count attempts by.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func newGame(game *domain.Game) *scoringv1.Game {
+	pb := &scoringv1.Game{Id: game.ID, Name: game.Name, Slug: game.Slug, Version: game.Version}
+	if !game.ScoresUpdatedAt.IsZero() {
+		pb.ScoresUpdatedAt = timestamppb.New(game.ScoresUpdatedAt)
+	}
+	return pb
+}
+
+func newScore(score *domain.Score) *scoringv1.Score {
+	return &scoringv1.Score{
+		UserId:   score.UserID,
+		Username: score.Username,
+		GameId:   score.GameID,
+		GameSlug: score.GameSlug,
+		GameName: score.GameName,
+		Points:   int64(score.Points),
+	}
+}
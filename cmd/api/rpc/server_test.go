@@ -0,0 +1,278 @@
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/rpc"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/dto"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/service"
+	scoringv1 "github.com/Martin-Arias/go-scoring-api/proto/scoring/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const userID = "8c1f2d0e-6a5b-4c3d-9e8f-7a6b5c4d3e2f"
+
+type env struct {
+	conn *grpc.ClientConn
+	us   *mocks.UserServiceMock
+	ts   *mocks.TokenServiceMock
+	gs   *mocks.GameServiceMock
+	ss   *mocks.ScoreServiceMock
+	ks   *mocks.APIKeyServiceMock
+}
+
+func newEnv(t *testing.T) *env {
+	t.Helper()
+	e := &env{
+		us: new(mocks.UserServiceMock),
+		ts: new(mocks.TokenServiceMock),
+		gs: new(mocks.GameServiceMock),
+		ss: new(mocks.ScoreServiceMock),
+		ks: new(mocks.APIKeyServiceMock),
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := rpc.NewServer(rpc.Services{Users: e.us, Tokens: e.ts, Games: e.gs, Scores: e.ss, Keys: e.ks})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	e.conn = conn
+	return e
+}
+
+// asUser authenticates the calls made with the returned context as a player
+// holding perms.
+func (e *env) asUser(perms ...domain.Permission) context.Context {
+	e.ts.On("ParseAccessToken", "access").Return(&domain.AccessClaims{UserID: userID, Permissions: perms}, nil)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer access")
+}
+
+func reason(t *testing.T, err error) string {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestLogin(t *testing.T) {
+	e := newEnv(t)
+	e.us.On("LoginUser", "ana", "secret", mock.Anything).Return(&domain.TokenPair{AccessToken: "a", RefreshToken: "r"}, nil)
+
+	resp, err := scoringv1.NewAuthServiceClient(e.conn).Login(context.Background(), &scoringv1.LoginRequest{Username: "ana", Password: "secret"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.AccessToken)
+	assert.Equal(t, "r", resp.RefreshToken)
+}
+
+func TestLogin_ValidatesRequest(t *testing.T) {
+	e := newEnv(t)
+
+	_, err := scoringv1.NewAuthServiceClient(e.conn).Login(context.Background(), &scoringv1.LoginRequest{Username: "ana"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	e.us.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListGames_RequiresCredentials(t *testing.T) {
+	e := newEnv(t)
+
+	_, err := scoringv1.NewGameServiceClient(e.conn).ListGames(context.Background(), &scoringv1.ListGamesRequest{})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "authentication_required", reason(t, err))
+}
+
+func TestCreateGame_RequiresPermission(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead)
+
+	_, err := scoringv1.NewGameServiceClient(e.conn).CreateGame(ctx, &scoringv1.CreateGameRequest{Name: "Chess"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	e.gs.AssertNotCalled(t, "CreateGame", mock.Anything, mock.Anything)
+}
+
+func TestCreateGame(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead, domain.PermGamesCreate)
+	e.gs.On("CreateGame", "Chess", "").Return(&domain.Game{ID: "g1", Name: "Chess", Slug: "chess"}, nil)
+
+	game, err := scoringv1.NewGameServiceClient(e.conn).CreateGame(ctx, &scoringv1.CreateGameRequest{Name: "Chess"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "chess", game.Slug)
+}
+
+func TestCreateGame_Conflict(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead, domain.PermGamesCreate)
+	e.gs.On("CreateGame", "Chess", "").Return(nil, domain.ErrGameAlreadyExists)
+
+	_, err := scoringv1.NewGameServiceClient(e.conn).CreateGame(ctx, &scoringv1.CreateGameRequest{Name: "Chess"})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestPasswordChangeRequired(t *testing.T) {
+	e := newEnv(t)
+	e.ts.On("ParseAccessToken", "access").Return(&domain.AccessClaims{UserID: userID, MustChangePassword: true, Permissions: []domain.Permission{domain.PermGamesRead}}, nil)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer access")
+
+	_, err := scoringv1.NewGameServiceClient(e.conn).ListGames(ctx, &scoringv1.ListGamesRequest{})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "password_change_required", reason(t, err))
+}
+
+func TestListUserScores_Me(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser()
+	e.ss.On("GetUserScores", userID).Return(&[]domain.Score{{UserID: userID, GameID: "g1", Points: 10}}, nil)
+
+	resp, err := scoringv1.NewScoreServiceClient(e.conn).ListUserScores(ctx, &scoringv1.ListUserScoresRequest{UserId: "me"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Scores, 1)
+	assert.EqualValues(t, 10, resp.Scores[0].Points)
+}
+
+func TestListUserScores_OtherUserNeedsScoresRead(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser()
+
+	_, err := scoringv1.NewScoreServiceClient(e.conn).ListUserScores(ctx, &scoringv1.ListUserScoresRequest{UserId: "0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestListUserScores_FiltersGamesOfAPIKey(t *testing.T) {
+	e := newEnv(t)
+	e.ks.On("Authenticate", "sk_test").Return(&domain.APIKey{ID: "k1", Scopes: []domain.Permission{domain.PermScoresRead}, GameIDs: []string{"g1"}}, nil)
+	e.ss.On("GetUserScores", userID).Return(&[]domain.Score{{GameID: "g1", Points: 10}, {GameID: "g2", Points: 20}}, nil)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "sk_test")
+
+	resp, err := scoringv1.NewScoreServiceClient(e.conn).ListUserScores(ctx, &scoringv1.ListUserScoresRequest{UserId: userID})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Scores, 1)
+	assert.Equal(t, "g1", resp.Scores[0].GameId)
+}
+
+func TestSubmitScore_GameNotAllowed(t *testing.T) {
+	e := newEnv(t)
+	e.ks.On("Authenticate", "sk_test").Return(&domain.APIKey{ID: "k1", Scopes: []domain.Permission{domain.PermScoresRead, domain.PermScoresSubmit}, GameIDs: []string{"g1"}}, nil)
+	e.gs.On("GetGame", "g2").Return(&domain.Game{ID: "g2"}, nil)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "sk_test")
+
+	_, err := scoringv1.NewScoreServiceClient(e.conn).SubmitScore(ctx, &scoringv1.SubmitScoreRequest{UserId: userID, Game: "g2", Points: 5})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "game_not_allowed", reason(t, err))
+	e.ss.AssertNotCalled(t, "Submit", mock.Anything)
+}
+
+func TestSubmitScore(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermScoresRead, domain.PermScoresSubmit)
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("Submit", &domain.Score{UserID: userID, GameID: "g1", Points: 5}).Return(nil)
+
+	_, err := scoringv1.NewScoreServiceClient(e.conn).SubmitScore(ctx, &scoringv1.SubmitScoreRequest{UserId: userID, Game: "chess", Points: 5})
+
+	require.NoError(t, err)
+	e.ss.AssertExpectations(t)
+}
+
+func TestStreamLeaderboard(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead, domain.PermScoresRead)
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("StreamGameScores", mock.Anything, "g1", mock.Anything).
+		Return(&[]domain.Score{{Username: "ana", Points: 30}, {Username: "bob", Points: 20}}, nil)
+
+	stream, err := scoringv1.NewLeaderboardServiceClient(e.conn).StreamLeaderboard(ctx, &scoringv1.GetLeaderboardRequest{Game: "chess"})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		score, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, score.Username)
+	}
+	assert.Equal(t, []string{"ana", "bob"}, names)
+}
+
+func TestPanicIsInternalError(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead)
+	// No expectation is set, so the mock panics.
+
+	_, err := scoringv1.NewGameServiceClient(e.conn).ListGames(ctx, &scoringv1.ListGamesRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// The server is still up.
+	e.gs.On("GetGames").Return(&[]domain.Game{}, nil)
+	_, err = scoringv1.NewGameServiceClient(e.conn).ListGames(ctx, &scoringv1.ListGamesRequest{})
+	assert.NoError(t, err)
+}
+
+func TestStreamPanicIsInternalError(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead, domain.PermScoresRead)
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+
+	stream, err := scoringv1.NewLeaderboardServiceClient(e.conn).StreamLeaderboard(ctx, &scoringv1.GetLeaderboardRequest{Game: "chess"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetGameStats(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.asUser(domain.PermGamesRead, domain.PermScoresRead)
+	e.gs.On("GetGame", "chess").Return(&domain.Game{ID: "g1"}, nil)
+	e.ss.On("GetGameStats", "g1").Return(&dto.ScoreStatisticsDTO{GameID: "g1", Mean: 15, Median: 15, Mode: []int{10, 20}}, nil)
+
+	stats, err := scoringv1.NewLeaderboardServiceClient(e.conn).GetGameStats(ctx, &scoringv1.GetGameStatsRequest{Game: "chess"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{10, 20}, stats.Mode)
+}
+
+func TestLogout_RefusesAPIKeys(t *testing.T) {
+	e := newEnv(t)
+	e.ks.On("Authenticate", "sk_test").Return(&domain.APIKey{ID: "k1"}, nil)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "sk_test")
+
+	_, err := scoringv1.NewAuthServiceClient(e.conn).Logout(ctx, &scoringv1.LogoutRequest{})
+
+	assert.Equal(t, "user_token_required", reason(t, err))
+}
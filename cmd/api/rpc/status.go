@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain is the domain of the google.rpc.ErrorInfo details.
+const errorDomain = "scoring-api"
+
+// grpcCodes maps the HTTP statuses of the REST API to gRPC codes.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusBadGateway:          codes.Unavailable,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+}
+
+// toStatus turns err into the gRPC status the call fails with. Domain errors
+// keep their REST error code as the reason of an ErrorInfo detail; other
+// errors become internal errors and their message is left out.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var fields validator.ValidationErrors
+	if errors.As(err, &fields) {
+		return invalidFields(fields)
+	}
+
+	code := problem.CodeOf(err)
+	grpcCode, ok := grpcCodes[problem.Status(err)]
+	if !ok {
+		grpcCode = codes.Internal
+	}
+	message := err.Error()
+	if code == problem.CodeInternal {
+		message = "internal error"
+	}
+
+	info := &errdetails.ErrorInfo{Reason: string(code), Domain: errorDomain}
+	var (
+		restricted *domain.RestrictedError
+		locked     *domain.LockedError
+	)
+	if errors.As(err, &restricted) {
+		grpcCode = codes.PermissionDenied
+		info.Metadata = map[string]string{"reason": restricted.Reason}
+		if restricted.Until != nil {
+			info.Metadata["until"] = restricted.Until.Format(time.RFC3339)
+		}
+	}
+
+	details := []protoadapt.MessageV1{info}
+	if errors.As(err, &locked) {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(locked.RetryAfter)})
+	}
+	return withDetails(status.New(grpcCode, message), details...)
+}
+
+// invalidFields fails a call whose request didn't pass the validation of the
+// matching REST request.
+func invalidFields(fields validator.ValidationErrors) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for _, fe := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field(),
+			Description: fe.Tag(),
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, domain.ErrInvalidRequest.Error()),
+		&errdetails.ErrorInfo{Reason: string(problem.CodeInvalidRequest), Domain: errorDomain},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+}
+
+// withDetails returns st with details, or without them if they can't be
+// encoded.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+)
+
+const importUsage = `usage:
+  app import -kind users|games|scores -file <path> [-format csv|ndjson]
+             [-conflict keep-best|overwrite|skip] [-dry-run] [-errors <path>]
+
+-file - reads standard input. Without -format the format is told from the
+file extension. -errors writes the rejected rows to a CSV file.
+`
+
+// runImportCommand implements the import subcommand and returns the exit
+// code: 0 when every row was imported, 3 when some were rejected.
+func runImportCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, importUsage) }
+	kind := fs.String("kind", "", "users, games or scores")
+	path := fs.String("file", "", "file to import, - for standard input")
+	format := fs.String("format", "", "csv or ndjson")
+	conflict := fs.String("conflict", string(domain.ConflictKeepBest), "keep-best, overwrite or skip")
+	dryRun := fs.Bool("dry-run", false, "validate and report without writing")
+	errorsPath := fs.String("errors", "", "CSV file to write the rejected rows to")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *kind == "" || *path == "" {
+		fs.Usage()
+		return 2
+	}
+	if *format == "" {
+		*format = formatFromExtension(*path)
+	}
+
+	file := os.Stdin
+	if *path != "-" {
+		var err error
+		if file, err = os.Open(*path); err != nil {
+			fmt.Fprintln(os.Stderr, "could not open file:", err)
+			return 1
+		}
+		defer file.Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	is := services.NewImportService(repository.NewImportRepository(db), repository.NewUserRepository(db), hasher.New())
+	report, err := is.Import(ctx, file, domain.ImportOptions{
+		Kind:     domain.ImportKind(*kind),
+		Format:   domain.ImportFormat(*format),
+		Conflict: domain.ConflictMode(*conflict),
+		DryRun:   *dryRun,
+	})
+	if report != nil {
+		printImportReport(report)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		return 1
+	}
+
+	if *errorsPath != "" && report.Rejected > 0 {
+		if err := writeRejectedRows(*errorsPath, report.Errors); err != nil {
+			fmt.Fprintln(os.Stderr, "could not write rejected rows:", err)
+			return 1
+		}
+		fmt.Printf("rejected rows written to %s\n", *errorsPath)
+	}
+	if report.Rejected > 0 {
+		return 3
+	}
+	return 0
+}
+
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return string(domain.ImportNDJSON)
+	}
+	return string(domain.ImportCSV)
+}
+
+func printImportReport(report *domain.ImportReport) {
+	mode := ""
+	if report.DryRun {
+		mode = " (dry run, nothing written)"
+	}
+	fmt.Printf("%s import%s: %d rows, %d created, %d updated, %d skipped, %d rejected\n",
+		report.Kind, mode, report.Rows, report.Created, report.Updated, report.Skipped, report.Rejected)
+	for _, rowErr := range report.Errors {
+		field := ""
+		if rowErr.Field != "" {
+			field = " " + rowErr.Field
+		}
+		fmt.Fprintf(os.Stderr, "  line %d:%s %s\n", rowErr.Line, field, rowErr.Message)
+	}
+	if report.ErrorsTruncated {
+		fmt.Fprintf(os.Stderr, "  only the first %d rejected rows are listed\n", len(report.Errors))
+	}
+}
+
+// writeRejectedRows writes the rejected rows with the columns of the report
+// the API answers with.
+func writeRejectedRows(path string, rows []domain.ImportRowError) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	_ = w.Write([]string{"line", "field", "message", "row"})
+	for _, row := range rows {
+		_ = w.Write([]string{strconv.Itoa(row.Line), row.Field, row.Message, row.Row})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -2,16 +2,32 @@ package main
 
 import (
 	"log"
+	"net"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/graph"
 	"github.com/Martin-Arias/go-scoring-api/cmd/api/handlers"
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/rpc"
 	_ "github.com/Martin-Arias/go-scoring-api/docs"
+	"github.com/Martin-Arias/go-scoring-api/internal/apiversion"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	"github.com/Martin-Arias/go-scoring-api/internal/httpcache"
+	"github.com/Martin-Arias/go-scoring-api/internal/metrics"
 	"github.com/Martin-Arias/go-scoring-api/internal/middleware"
+	"github.com/Martin-Arias/go-scoring-api/internal/notifier"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
 	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
 	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,6 +36,11 @@ import (
 
 var db *gorm.DB
 
+const (
+	keyRotationCheckInterval = 5 * time.Minute
+	idempotencyPurgeInterval = time.Hour
+)
+
 // Custom registry (without default Go metrics)
 var customRegistry = prometheus.NewRegistry()
 
@@ -36,40 +57,253 @@ func setupRouter() *gin.Engine {
 	sr := repository.NewScoreRepository(db)
 	ur := repository.NewUserRepository(db)
 	gr := repository.NewGameRepository(db)
+	tr := repository.NewTokenRepository(db)
+	rr := repository.NewRoleRepository(db)
+	kr := repository.NewAPIKeyRepository(db)
+	skr := repository.NewSigningKeyRepository(db)
+	prr := repository.NewPasswordResetRepository(db)
+	lr := repository.NewLoginAttemptRepository(db)
+	ar := repository.NewAccountRepository(db)
+	oidcr := repository.NewOIDCRepository(db)
+	mr := repository.NewModerationRepository(db)
+	gsr := repository.NewGuestRepository(db)
+	ir := repository.NewImportRepository(db)
+	idr := repository.NewIdempotencyRepository(db)
+
+	sks := services.NewKeyService(skr)
+	if err := sks.Rotate(); err != nil {
+		log.Fatal("signing keys could not be loaded:", err)
+	}
+	go rotateSigningKeys(sks)
+
+	ph := hasher.New()
+	pp, err := services.NewPasswordPolicy()
+	if err != nil {
+		log.Fatal("password policy could not be loaded:", err)
+	}
 
-	us := services.NewUserService(ur)
+	nt, err := notifier.New()
+	if err != nil {
+		log.Fatal("notifier could not be configured:", err)
+	}
+
+	ts := services.NewTokenService(tr, ur, rr, sks)
+	ls := services.NewLockoutService(lr, ur)
+	us := services.NewUserService(ur, ts, ls, ph, pp)
 	ss := services.NewScoreService(sr, ur, gr)
 	gs := services.NewGameService(gr)
+	rs := services.NewRoleService(rr, ur)
+	ks := services.NewAPIKeyService(kr, gr)
+	ps := services.NewPasswordService(ur, prr, ts, nt, ph, pp)
+	pfs := services.NewProfileService(ur, sr)
+	as := services.NewAccountService(ar, ur, ts, ls, ph)
+	ms := services.NewModerationService(mr, ur, ts)
+	guests := services.NewGuestService(gsr, ur, sr, ts, ph, pp)
+	oidcs := services.NewOIDCService(oidcr, ur, ts, services.OIDCProvidersFromEnv())
+	is := services.NewImportService(ir, ur, ph)
+	ids := services.NewIdempotencyService(idr)
+	go purgeIdempotencyKeys(ids)
+	go serveGRPC(rpc.NewServer(rpc.Services{Users: us, Tokens: ts, Games: gs, Scores: ss, Keys: ks}))
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware.RequestID(), gin.Logger(), gin.CustomRecovery(func(c *gin.Context, _ any) {
+		problem.Abort(c, domain.ErrUnexpected)
+	}))
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *gin.Context) { problem.Write(c, domain.ErrRouteNotFound) })
+	r.NoMethod(func(c *gin.Context) { problem.Write(c, domain.ErrMethodNotAllowed) })
+	// Login attempts are counted per client IP, so X-Forwarded-For is only
+	// honoured when sent by one of TRUSTED_PROXIES.
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatal("invalid TRUSTED_PROXIES:", err)
+	}
 	r.GET("/metrics", PrometheusHandler())
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.Use(RequestMetricsMiddleware())
 
 	userHandler := handlers.NewUserHandler(us)
 	gameHandler := handlers.NewGameHandler(gs)
-	scoreHandler := handlers.NewScoreHandler(ss)
+	scoreHandler := handlers.NewScoreHandler(ss, gs)
+	authHandler := handlers.NewAuthHandler(ts)
+	roleHandler := handlers.NewRoleHandler(rs)
+	apiKeyHandler := handlers.NewAPIKeyHandler(ks)
+	jwksHandler := handlers.NewJWKSHandler(sks)
+	passwordHandler := handlers.NewPasswordHandler(ps)
+	lockoutHandler := handlers.NewLockoutHandler(ls)
+	profileHandler := handlers.NewProfileHandler(pfs)
+	accountHandler := handlers.NewAccountHandler(as)
+	oidcHandler := handlers.NewOIDCHandler(oidcs)
+	moderationHandler := handlers.NewModerationHandler(ms)
+	guestHandler := handlers.NewGuestHandler(guests)
+	importHandler := handlers.NewImportHandler(is)
+	graphQLHandler := handlers.NewGraphQLHandler(graph.NewSchema(graph.Services{Games: gs, Scores: ss, Profiles: pfs}))
+
+	r.GET("/.well-known/jwks.json", jwksHandler.Get)
+
+	// Writes clients retry on timeouts can be sent with an Idempotency-Key.
+	idempotent := middleware.Idempotency(ids)
+
+	// Routes of API v1, served by mountVersions.
+	v1 := apiversion.NewRoutes()
+
 	// Public routes
-	auth := r.Group("/auth")
-	auth.POST("/register", userHandler.Register)
+	auth := v1.Group("/auth")
+	auth.POST("/register", idempotent, userHandler.Register)
 	auth.POST("/login", userHandler.Login)
+	auth.POST("/guest", guestHandler.Login)
+	auth.POST("/refresh", authHandler.Refresh)
+	auth.POST("/logout", middleware.AuthMiddleware(ts, ks), authHandler.Logout)
+	auth.POST("/password/forgot", passwordHandler.Forgot)
+	auth.POST("/password/reset", passwordHandler.Reset)
+	auth.GET("/oidc/:provider/login", oidcHandler.Login)
+	auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 
 	// Protected routes
-	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware())
+	api := v1.Group("", middleware.AuthMiddleware(ts, ks))
+
+	// Users that must replace a handed-out password can only reach the
+	// password change; the guard applies to the routes registered after it.
+	api.PUT("/users/me/password", passwordHandler.Change)
+	api.Use(middleware.RequirePasswordChanged())
+
+	// Leaderboards are revalidated with their ETag by default.
+	scoresCache := httpcache.Control(utils.EnvString("CACHE_CONTROL_GAME_SCORES", "private, no-cache"))
+	statsCache := httpcache.Control(utils.EnvString("CACHE_CONTROL_GAME_STATS", "private, no-cache"))
+
+	games := api.Group("/games", middleware.RequirePermission(domain.PermGamesRead))
+	games.POST("", middleware.RequirePermission(domain.PermGamesCreate), idempotent, gameHandler.Create)
+	games.GET("", gameHandler.List)
+	games.GET("/:id", gameHandler.Get)
+	games.GET("/:id/scores", middleware.RequirePermission(domain.PermScoresRead), scoresCache, scoreHandler.ListGameScores)
+	games.GET("/:id/stats", middleware.RequirePermission(domain.PermScoresRead), statsCache, scoreHandler.GameStats)
+
+	scores := api.Group("/scores", middleware.RequirePermission(domain.PermScoresRead))
+	scores.PUT("", middleware.RequirePermission(domain.PermScoresSubmit), idempotent, scoreHandler.Submit)
+	// Deprecated in favour of the game and user score routes.
+	scores.GET("/user", scoreHandler.GetUserScores)
+	scores.GET("/game", scoresCache, scoreHandler.GetGameScores)
+	scores.GET("/game/stats", statsCache, scoreHandler.GetGameStats)
+
+	users := api.Group("/users")
+	users.GET("/me", profileHandler.GetMe)
+	users.PATCH("/me", profileHandler.UpdateMe)
+	users.DELETE("/me", accountHandler.Delete)
+	users.GET("/me/export", accountHandler.Export)
+	users.POST("/me/upgrade", guestHandler.Upgrade)
+	users.POST("/me/merge", guestHandler.Merge)
+	users.PUT("/me/username", profileHandler.Rename)
+	users.POST("/me/identities/:provider", oidcHandler.Link)
+	users.GET("/me/scores", scoreHandler.ListMyScores)
+	users.GET("/me/games/:gameId/score", scoreHandler.GetMyGameScore)
+	users.GET("/:id/scores", middleware.RequirePermission(domain.PermScoresRead), scoreHandler.ListUserScores)
+	users.GET("/:id/games/:gameId/score", middleware.RequirePermission(domain.PermScoresRead), scoreHandler.GetUserGameScore)
+	users.GET("/:id", middleware.RequirePermission(domain.PermScoresRead), profileHandler.GetPublic)
+	users.GET("", middleware.RequirePermission(domain.PermUsersRead), profileHandler.List)
+	users.GET("/:id/usernames", middleware.RequirePermission(domain.PermUsersRead), profileHandler.UsernameHistory)
+
+	roles := api.Group("", middleware.RequirePermission(domain.PermRolesAssign))
+	roles.GET("/roles", roleHandler.List)
+	roles.PUT("/users/:id/role", roleHandler.Assign)
 
-	api.POST("/games", middleware.AdminMiddleware(), gameHandler.Create)
-	api.GET("/games", gameHandler.List)
+	roles.POST("/users/:id/promote", moderationHandler.Promote)
+	roles.POST("/users/:id/demote", moderationHandler.Demote)
 
-	api.PUT("/scores", middleware.AdminMiddleware(), scoreHandler.Submit)
-	api.GET("/scores/user", scoreHandler.GetUserScores)
-	api.GET("/scores/game", scoreHandler.GetGameScores)
-	api.GET("/scores/game/stats", scoreHandler.GetGameStats)
+	moderation := api.Group("/users/:id", middleware.RequirePermission(domain.PermUsersBan))
+	moderation.DELETE("/lockout", lockoutHandler.Unlock)
+	moderation.POST("/suspend", moderationHandler.Suspend)
+	moderation.POST("/ban", moderationHandler.Ban)
+	moderation.POST("/reinstate", moderationHandler.Reinstate)
+	moderation.GET("/moderation", moderationHandler.History)
+
+	apiKeys := api.Group("/api-keys", middleware.RequirePermission(domain.PermAPIKeys))
+	apiKeys.POST("", apiKeyHandler.Create)
+	apiKeys.GET("", apiKeyHandler.List)
+	apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+
+	imports := api.Group("/imports", middleware.RequirePermission(domain.PermDataImport))
+	imports.POST("/:kind", importHandler.Import)
+
+	// Every field checks the permissions of the matching REST endpoint.
+	api.GET("/graphql", graphQLHandler.QueryGet)
+	api.POST("/graphql", graphQLHandler.Query)
+
+	mountVersions(r, v1)
 
 	return r
 }
+
+// legacyDeprecated is when the unversioned routes gave way to /api/v1.
+var legacyDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// mountVersions serves the route table of every API version under
+// /api/<version>. A new version starts as a copy of the previous one and
+// overrides the handlers whose contract changes, e.g.:
+//
+//	v2 := v1.Clone()
+//	v2.Override(http.MethodGet, "/scores/game", scoreHandler.GetGameScoresV2)
+//	v2.Mount(r.Group("/api/v2", apiversion.FromEnv("v2").Middleware()))
+//
+// v1 is also served, deprecated, at the paths used before versioning:
+// /auth/... at the root and everything else under /api.
+func mountVersions(r *gin.Engine, v1 *apiversion.Routes) {
+	v1.Mount(r.Group("/api/v1", apiversion.FromEnv("v1").Middleware()))
+
+	legacy := apiversion.FromEnv("legacy")
+	if legacy.Deprecated.IsZero() {
+		legacy.Deprecated = legacyDeprecated
+	}
+	unversioned := r.Group("", legacy.Middleware())
+	v1.Each(func(method, path string, handlers gin.HandlersChain) {
+		if !strings.HasPrefix(path, "/auth/") {
+			path = "/api" + path
+		}
+		unversioned.Handle(method, path, handlers...)
+	})
+}
+
+func trustedProxies() []string {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// rotateSigningKeys periodically retires due signing keys and picks up keys
+// created by other instances.
+func rotateSigningKeys(ks ports.KeyService) {
+	for range time.Tick(keyRotationCheckInterval) {
+		if err := ks.Rotate(); err != nil {
+			log.Println("signing key rotation failed:", err)
+		}
+	}
+}
+
+// purgeIdempotencyKeys periodically deletes the stored responses past their
+// TTL.
+func purgeIdempotencyKeys(is ports.IdempotencyService) {
+	for range time.Tick(idempotencyPurgeInterval) {
+		if err := is.Purge(); err != nil {
+			log.Println("idempotency key purge failed:", err)
+		}
+	}
+}
+
+// serveGRPC runs the gRPC API on GRPC_ADDR, next to the REST API.
+func serveGRPC(server *grpc.Server) {
+	addr := utils.EnvString("GRPC_ADDR", ":50051")
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal("gRPC listen failed:", err)
+	}
+	if err := server.Serve(lis); err != nil {
+		log.Fatal("gRPC server failed:", err)
+	}
+}
+
 func init() {
 	customRegistry.MustRegister(HttpRequestTotal, HttpRequestErrorTotal)
+	customRegistry.MustRegister(metrics.Collectors()...)
 	var err error
 	db, err = repository.Connect()
 	if err != nil {
@@ -95,7 +329,20 @@ func init() {
 // @in header
 // @name Authorization
 
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(runAdminCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImportCommand(os.Args[2:]))
+	}
+
+	bootstrapAdmin()
+
 	r := setupRouter()
 	// Listen and Server in 0.0.0.0:8080
 	r.Run(":8080")
@@ -0,0 +1,122 @@
+package apiversion_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/apiversion"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func reply(body string) gin.HandlerFunc {
+	return func(c *gin.Context) { c.String(http.StatusOK, body) }
+}
+
+func header(name, value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(name, value)
+		c.Next()
+	}
+}
+
+func get(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+	return resp
+}
+
+func newV1() *apiversion.Routes {
+	v1 := apiversion.NewRoutes()
+	api := v1.Group("", header("X-Auth", "yes"))
+	api.GET("/open", reply("open v1"))
+	api.Use(header("X-Guard", "yes"))
+	games := api.Group("/games")
+	games.GET("", reply("games v1"))
+	games.GET("/:id", reply("game v1"))
+	return v1
+}
+
+func TestRoutes_OverrideInNewVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v1 := newV1()
+	v2 := v1.Clone()
+	v2.Override(http.MethodGet, "/games", reply("games v2"))
+	v2.Remove(http.MethodGet, "/open")
+
+	r := gin.New()
+	v1.Mount(r.Group("/api/v1"))
+	v2.Mount(r.Group("/api/v2"))
+
+	assert.Equal(t, "games v1", get(r, "/api/v1/games").Body.String())
+	assert.Equal(t, "open v1", get(r, "/api/v1/open").Body.String())
+
+	resp := get(r, "/api/v2/games")
+	assert.Equal(t, "games v2", resp.Body.String())
+	assert.Equal(t, "yes", resp.Header().Get("X-Auth"), "overrides keep the middleware")
+	assert.Equal(t, "yes", resp.Header().Get("X-Guard"))
+	assert.Equal(t, "game v1", get(r, "/api/v2/games/1").Body.String())
+	assert.Equal(t, http.StatusNotFound, get(r, "/api/v2/open").Code)
+}
+
+func TestRoutes_UseOnlyAppliesToLaterRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	newV1().Mount(r)
+
+	assert.Empty(t, get(r, "/open").Header().Get("X-Guard"))
+	assert.Equal(t, "yes", get(r, "/games").Header().Get("X-Guard"))
+}
+
+func TestRoutes_OverrideUnknownRoutePanics(t *testing.T) {
+	assert.Panics(t, func() {
+		newV1().Override(http.MethodPost, "/games", reply("nope"))
+	})
+}
+
+func TestVersion_DeprecationHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deprecated := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Now().Add(24 * time.Hour).UTC()
+
+	r := gin.New()
+	newV1().Mount(r.Group("/api/v1", apiversion.Version{Name: "v1", Deprecated: deprecated, Sunset: sunset}.Middleware()))
+	newV1().Mount(r.Group("/api/v2", apiversion.Version{Name: "v2"}.Middleware()))
+
+	resp := get(r, "/api/v1/games")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "@"+strconv.FormatInt(deprecated.Unix(), 10), resp.Header().Get("Deprecation"))
+	assert.Equal(t, sunset.Format(http.TimeFormat), resp.Header().Get("Sunset"))
+
+	resp = get(r, "/api/v2/games")
+	assert.Empty(t, resp.Header().Get("Deprecation"))
+	assert.Empty(t, resp.Header().Get("Sunset"))
+}
+
+func TestVersion_GoneAfterSunset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v := apiversion.Version{Name: "v1", Deprecated: time.Now().Add(-48 * time.Hour), Sunset: time.Now().Add(-time.Hour)}
+
+	r := gin.New()
+	newV1().Mount(r.Group("/api/v1", v.Middleware()))
+
+	resp := get(r, "/api/v1/games")
+	assert.Equal(t, http.StatusGone, resp.Code)
+	assert.Contains(t, resp.Body.String(), "version_sunset")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("API_V1_DEPRECATED", "2026-10-01")
+	t.Setenv("API_V1_SUNSET", "2027-01-31T12:00:00Z")
+
+	v := apiversion.FromEnv("v1")
+	assert.Equal(t, "v1", v.Name)
+	assert.True(t, v.Deprecated.Equal(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, v.Sunset.Equal(time.Date(2027, time.January, 31, 12, 0, 0, 0, time.UTC)))
+
+	t.Setenv("API_V1_SUNSET", "soon")
+	assert.True(t, apiversion.FromEnv("v1").Sunset.IsZero())
+}
@@ -0,0 +1,176 @@
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes is the route table of one version of the API. Tables are built like
+// gin groups and mounted on a router afterwards, so that a new version can
+// start as a copy of the previous one and only replace the handlers whose
+// contract changes.
+type Routes struct {
+	routes []*route
+}
+
+type route struct {
+	method     string
+	path       string
+	middleware []gin.HandlerFunc
+	handler    gin.HandlerFunc
+}
+
+// NewRoutes returns an empty table.
+func NewRoutes() *Routes {
+	return &Routes{}
+}
+
+// Group returns a group of the table rooted at relativePath.
+func (rs *Routes) Group(relativePath string, middleware ...gin.HandlerFunc) *Group {
+	return &Group{rs: rs, prefix: join("/", relativePath), middleware: middleware}
+}
+
+// Clone returns a copy of the table that can be changed on its own.
+func (rs *Routes) Clone() *Routes {
+	clone := &Routes{routes: make([]*route, 0, len(rs.routes))}
+	for _, r := range rs.routes {
+		copied := *r
+		clone.routes = append(clone.routes, &copied)
+	}
+	return clone
+}
+
+// Override replaces the handler of an existing route, keeping its
+// middleware. It panics when the route doesn't exist, as that is a mistake in
+// how the router is set up.
+func (rs *Routes) Override(method, relativePath string, handler gin.HandlerFunc) {
+	r := rs.find(method, join("/", relativePath))
+	if r == nil {
+		panic(fmt.Sprintf("apiversion: no route %s %s to override", method, relativePath))
+	}
+	r.handler = handler
+}
+
+// Remove drops a route, e.g. one a newer version no longer serves. It panics
+// when the route doesn't exist.
+func (rs *Routes) Remove(method, relativePath string) {
+	target := rs.find(method, join("/", relativePath))
+	if target == nil {
+		panic(fmt.Sprintf("apiversion: no route %s %s to remove", method, relativePath))
+	}
+	kept := rs.routes[:0]
+	for _, r := range rs.routes {
+		if r != target {
+			kept = append(kept, r)
+		}
+	}
+	rs.routes = kept
+}
+
+// Each calls fn with every route of the table, in registration order.
+func (rs *Routes) Each(fn func(method, path string, handlers gin.HandlersChain)) {
+	for _, r := range rs.routes {
+		fn(r.method, r.path, r.chain())
+	}
+}
+
+// Mount registers every route of the table on r.
+func (rs *Routes) Mount(r gin.IRouter) {
+	rs.Each(func(method, path string, handlers gin.HandlersChain) {
+		r.Handle(method, path, handlers...)
+	})
+}
+
+func (rs *Routes) find(method, fullPath string) *route {
+	for _, r := range rs.routes {
+		if r.method == method && r.path == fullPath {
+			return r
+		}
+	}
+	return nil
+}
+
+func (r *route) chain() gin.HandlersChain {
+	chain := make(gin.HandlersChain, 0, len(r.middleware)+1)
+	chain = append(chain, r.middleware...)
+	return append(chain, r.handler)
+}
+
+// Group registers routes on a table the way gin.RouterGroup does on a router:
+// Use only applies to the routes registered after it.
+type Group struct {
+	rs         *Routes
+	prefix     string
+	middleware []gin.HandlerFunc
+}
+
+// Group returns a subgroup rooted at relativePath.
+func (g *Group) Group(relativePath string, middleware ...gin.HandlerFunc) *Group {
+	return &Group{
+		rs:         g.rs,
+		prefix:     join(g.prefix, relativePath),
+		middleware: combine(g.middleware, middleware),
+	}
+}
+
+// Use adds middleware to the routes registered on the group from now on.
+func (g *Group) Use(middleware ...gin.HandlerFunc) {
+	g.middleware = combine(g.middleware, middleware)
+}
+
+// Handle registers a route. The last handler is the one Override replaces;
+// the ones before it are route middleware.
+func (g *Group) Handle(method, relativePath string, handlers ...gin.HandlerFunc) {
+	if len(handlers) == 0 {
+		panic("apiversion: route without handler")
+	}
+	last := len(handlers) - 1
+	g.rs.routes = append(g.rs.routes, &route{
+		method:     method,
+		path:       join(g.prefix, relativePath),
+		middleware: combine(g.middleware, handlers[:last]),
+		handler:    handlers[last],
+	})
+}
+
+func (g *Group) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, relativePath, handlers...)
+}
+
+func (g *Group) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, relativePath, handlers...)
+}
+
+func (g *Group) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, relativePath, handlers...)
+}
+
+func (g *Group) PATCH(relativePath string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, relativePath, handlers...)
+}
+
+func (g *Group) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, relativePath, handlers...)
+}
+
+// combine returns a new slice so groups never share a backing array.
+func combine(a, b []gin.HandlerFunc) []gin.HandlerFunc {
+	out := make([]gin.HandlerFunc, 0, len(a)+len(b))
+	out = append(out, a...)
+	return append(out, b...)
+}
+
+// join joins paths like gin does, keeping a trailing slash.
+func join(base, relative string) string {
+	if relative == "" {
+		return base
+	}
+	joined := path.Join(base, relative)
+	if relative[len(relative)-1] == '/' && joined[len(joined)-1] != '/' {
+		return joined + "/"
+	}
+	return joined
+}
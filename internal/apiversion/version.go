@@ -0,0 +1,93 @@
+// Package apiversion mounts the versions of the API and tells clients of
+// deprecated ones about it.
+package apiversion
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/metrics"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ContextKey is the gin context key holding the version name of a request.
+const ContextKey = "api_version"
+
+// Version is a version of the API as announced to clients.
+type Version struct {
+	Name string
+	// Deprecated is when the version was deprecated; zero while supported.
+	Deprecated time.Time
+	// Sunset is when the version stops being served; zero when not planned.
+	Sunset time.Time
+}
+
+// FromEnv returns the version name with the deprecation and sunset dates of
+// API_<NAME>_DEPRECATED and API_<NAME>_SUNSET, in RFC 3339 or as a date.
+// Invalid dates are logged and ignored.
+func FromEnv(name string) Version {
+	prefix := "API_" + strings.ToUpper(name) + "_"
+	return Version{
+		Name:       name,
+		Deprecated: envTime(prefix + "DEPRECATED"),
+		Sunset:     envTime(prefix + "SUNSET"),
+	}
+}
+
+// Middleware tags the requests of the version. Requests to a deprecated
+// version get the Deprecation and Sunset headers, and are refused with 410
+// once the sunset has passed. Every request is counted per version, route and
+// caller.
+func (v Version) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKey, v.Name)
+
+		if !v.Deprecated.IsZero() {
+			// RFC 9745 structured date.
+			c.Header("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+		}
+		if !v.Sunset.IsZero() {
+			c.Header("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			if time.Now().After(v.Sunset) {
+				problem.Abort(c, domain.ErrVersionSunset)
+				metrics.APIRequests.WithLabelValues(v.Name, c.FullPath(), caller(c)).Inc()
+				return
+			}
+		}
+
+		c.Next()
+		metrics.APIRequests.WithLabelValues(v.Name, c.FullPath(), caller(c)).Inc()
+	}
+}
+
+// caller names who made a request without exploding the metric: API keys
+// by name, everyone else by kind.
+func caller(c *gin.Context) string {
+	if _, ok := c.Get("api_key"); ok {
+		return "api_key:" + c.GetString("username")
+	}
+	if c.GetString("uid") != "" {
+		return "user"
+	}
+	return "anonymous"
+}
+
+func envTime(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	log.Warn().Str("key", key).Str("value", value).Msg("invalid date, ignoring it")
+	return time.Time{}
+}
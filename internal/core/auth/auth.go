@@ -1,8 +1,13 @@
 package auth
 
+import "github.com/Martin-Arias/go-scoring-api/internal/domain"
+
 type AuthUserData struct {
 	ID           string
 	Username     string
 	PasswordHash string
-	IsAdmin      bool
+	Role         string
+
+	MustChangePassword bool
+	Status             domain.AccountStatus
 }
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// How accounts are removed when their owner deletes them.
+const (
+	// DeletionAnonymize scrubs the personal data but keeps the user row, so
+	// leaderboards keep their scores under a placeholder name.
+	DeletionAnonymize = "anonymize"
+	// DeletionHard removes the user and everything that belongs to them.
+	DeletionHard = "delete"
+)
+
+// UserExport is everything the API stores about a user, handed out on
+// request as a JSON archive.
+type UserExport struct {
+	ExportedAt time.Time
+	User       User
+	Scores     []Score
+	Sessions   []Session
+	APIKeys    []APIKey
+	Identities []ExternalIdentity
+	Usernames  []UsernameChange
+}
+
+// Session is a refresh token as shown to its owner.
+type Session struct {
+	ID        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
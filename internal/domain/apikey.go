@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// APIKey lets a service account call the API without a user session. The key
+// value is only known when it is created; afterwards just its prefix is kept
+// for display.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	Scopes     []Permission
+	GameIDs    []string
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// AllowsGame reports whether the key may act on the given game. Keys without
+// a game list are valid for every game.
+func (k *APIKey) AllowsGame(gameID string) bool {
+	if len(k.GameIDs) == 0 {
+		return true
+	}
+	for _, id := range k.GameIDs {
+		if id == gameID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,24 +2,83 @@ package domain
 
 import "errors"
 
-// ErrorResponse defines a standard error response.
-type ErrorResponse struct {
-	Error string `json:"error" example:"error message"`
-}
-
 var (
-	ErrUnexpected    = errors.New("unexpected error")
-	ErrAuthInvalid   = errors.New("invalid username or password")
-	ErrScoreNotFound = errors.New("score not found")
-	ErrGameNotFound  = errors.New("game not found")
-	ErrUserNotFound  = errors.New("user not found")
+	ErrUnexpected       = errors.New("unexpected error")
+	ErrInvalidRequest   = errors.New("invalid request")
+	ErrRouteNotFound    = errors.New("route not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	ErrVersionSunset    = errors.New("this api version is no longer served")
+	ErrNotAcceptable    = errors.New("none of the accepted media types can be produced")
+	ErrInvalidImport    = errors.New("invalid import file")
+	ErrQueryTooLarge    = errors.New("query resolves too many objects")
+	ErrRequestTooLarge  = errors.New("request body is too large")
+
+	ErrIdempotencyKeyInvalid    = errors.New("Idempotency-Key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused     = errors.New("Idempotency-Key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is still in progress")
+
+	ErrAuthRequired      = errors.New("missing authorization header")
+	ErrAuthInvalid       = errors.New("invalid username or password")
+	ErrForbidden         = errors.New("forbidden resource")
+	ErrUserTokenRequired = errors.New("only users can use this endpoint")
+
+	ErrCurrentPasswordInvalid = errors.New("current password is incorrect")
+	ErrResetTokenInvalid      = errors.New("invalid or expired reset token")
+	ErrPasswordPolicy         = errors.New("password does not meet the password policy")
+	ErrPasswordChangeRequired = errors.New("password change required")
+	ErrLoginLocked            = errors.New("too many failed login attempts, try again later")
+	ErrUserBanned             = errors.New("account is banned")
+	ErrUserSuspended          = errors.New("account is suspended")
+
+	ErrOIDCProviderNotFound    = errors.New("unknown identity provider")
+	ErrOIDCStateInvalid        = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed         = errors.New("identity provider login failed")
+	ErrOIDCProviderUnavailable = errors.New("identity provider is unavailable")
+	ErrIdentityLinked          = errors.New("identity is already linked to a user")
+
+	ErrTokenInvalid        = errors.New("invalid access token")
+	ErrTokenRevoked        = errors.New("access token has been revoked")
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	ErrAPIKeyInvalid       = errors.New("invalid api key")
+
+	ErrScoreNotFound  = errors.New("score not found")
+	ErrGameNotFound   = errors.New("game not found")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrGuestNotFound  = errors.New("guest not found")
+	ErrRoleNotFound   = errors.New("role not found")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	ErrSigningKeyNotFound   = errors.New("signing key not found")
+	ErrUnsupportedAlgorithm = errors.New("unsupported token signing algorithm")
 
 	ErrGameAlreadyExists     = errors.New("game with the same name already exists")
+	ErrGameSlugAlreadyExists = errors.New("game with the same slug already exists")
 	ErrUsernameAlreadyExists = errors.New("user with the same username already exists")
+	ErrEmailAlreadyExists    = errors.New("user with the same email already exists")
+
+	ErrUsernameInvalid  = errors.New("username must have 3 to 30 letters, digits, dots, dashes or underscores and start and end with a letter or digit")
+	ErrUsernameReserved = errors.New("username is reserved")
+	ErrUsernameHeld     = errors.New("username was recently used by another user")
+	ErrUsernameCooldown = errors.New("username was changed too recently")
 
 	ErrGameCreation   = errors.New("error creating game")
 	ErrFetchingUsers  = errors.New("error fetching users")
 	ErrCreatingScores = errors.New("error creating initial scores")
 
 	ErrScoreNotAllowed = errors.New("new score must be higher than previous score")
+
+	ErrNotGuest    = errors.New("account is not a guest")
+	ErrMergeTarget = errors.New("guest scores can only be merged into a registered player account")
+
+	ErrLastAdmin         = errors.New("the last admin cannot be demoted")
+	ErrUserNotAdmin      = errors.New("user is not an admin")
+	ErrUserAlreadyAdmin  = errors.New("user is already an admin")
+	ErrModerateAdmin     = errors.New("admins must be demoted before they can be suspended or banned")
+	ErrInvalidSuspension = errors.New("suspension must end in the future")
+
+	ErrInvalidScope   = errors.New("unknown or forbidden api key scope")
+	ErrGameNotAllowed = errors.New("api key is not allowed for this game")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+
+	ErrInvalidGameSlug = errors.New("slug must contain only lowercase letters, digits and single dashes")
 )
@@ -1,6 +1,13 @@
 package domain
 
+import "time"
+
 type Game struct {
 	ID   string
 	Name string
+	Slug string
+	// Version changes whenever the leaderboard of the game does, and
+	// ScoresUpdatedAt tells when that last happened.
+	Version         int64
+	ScoresUpdatedAt time.Time
 }
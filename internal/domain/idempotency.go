@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord remembers a write sent with an Idempotency-Key, so that a
+// retry gets the first response instead of running it again.
+type IdempotencyRecord struct {
+	// Scope keeps the keys of different callers and routes apart.
+	Scope string
+	Key   string
+	// RequestHash identifies the request the key was first used for.
+	RequestHash string
+	// Status is zero while the first request is still running.
+	Status int
+	Header map[string][]string
+	Body   []byte
+	// LockedUntil is when a request still running is given up for lost and
+	// a retry may take the key over.
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
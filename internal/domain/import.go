@@ -0,0 +1,99 @@
+package domain
+
+// ImportKind is what an import file holds.
+type ImportKind string
+
+const (
+	ImportUsers  ImportKind = "users"
+	ImportGames  ImportKind = "games"
+	ImportScores ImportKind = "scores"
+)
+
+// ImportFormat is the encoding of an import file.
+type ImportFormat string
+
+const (
+	ImportCSV    ImportFormat = "csv"
+	ImportNDJSON ImportFormat = "ndjson"
+)
+
+// ConflictMode decides what happens to a row whose user, game or score
+// already exists.
+type ConflictMode string
+
+const (
+	// ConflictKeepBest updates a score only when the imported one is better.
+	// Existing users and games are left as they are.
+	ConflictKeepBest ConflictMode = "keep-best"
+	// ConflictOverwrite replaces what exists with the imported row.
+	ConflictOverwrite ConflictMode = "overwrite"
+	// ConflictSkip leaves what exists untouched.
+	ConflictSkip ConflictMode = "skip"
+)
+
+type ImportOptions struct {
+	Kind     ImportKind
+	Format   ImportFormat
+	Conflict ConflictMode
+	// DryRun validates the file against the database and reports what would
+	// change, without changing anything.
+	DryRun bool
+}
+
+// ImportUser is a user row. Users without a known password hash have to
+// reset their password before they can log in.
+type ImportUser struct {
+	Line         int
+	Username     string
+	Email        string
+	PasswordHash string
+}
+
+type ImportGame struct {
+	Line int
+	Name string
+	Slug string
+}
+
+// ImportScore is a score row. User is an ID or a username and Game an ID or a
+// slug.
+type ImportScore struct {
+	Line   int
+	User   string
+	Game   string
+	Points int
+}
+
+// ImportRowError is a rejected row of an import file.
+type ImportRowError struct {
+	Line    int
+	Field   string
+	Message string
+	// Row is the rejected row as it appears in the file.
+	Row string
+}
+
+// ImportBatchResult is what writing one batch of rows did.
+type ImportBatchResult struct {
+	Created int
+	Updated int
+	Skipped int
+	// Rejected are the rows the database refused, e.g. scores of unknown
+	// users.
+	Rejected []ImportRowError
+}
+
+// ImportReport sums up an import.
+type ImportReport struct {
+	Kind     ImportKind
+	Conflict ConflictMode
+	DryRun   bool
+	Rows     int
+	Created  int
+	Updated  int
+	Skipped  int
+	Rejected int
+	// Errors lists the rejected rows, up to a limit.
+	Errors          []ImportRowError
+	ErrorsTruncated bool
+}
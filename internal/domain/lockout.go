@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// LoginAttempt counts the failed logins of a username or a client IP.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// LockedError is returned while logins are refused after too many failures.
+// It matches ErrLoginLocked with errors.Is.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// Account states. A suspension ends on its own once SuspendedUntil passes.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
+
+// Moderation actions taken by admins and moderators on a user.
+const (
+	ActionPromote   = "promote"
+	ActionDemote    = "demote"
+	ActionSuspend   = "suspend"
+	ActionBan       = "ban"
+	ActionReinstate = "reinstate"
+)
+
+// AccountStatus tells whether a user may sign in.
+type AccountStatus struct {
+	State          string
+	Reason         string
+	SuspendedUntil *time.Time
+	// SessionsRevokedAt is when every session of the user was last ended.
+	// Access tokens issued before it are no longer accepted.
+	SessionsRevokedAt *time.Time
+}
+
+// Check returns a *RestrictedError when the account is banned or suspended
+// at now, and nil otherwise.
+func (s AccountStatus) Check(now time.Time) error {
+	switch s.State {
+	case StatusBanned:
+		return &RestrictedError{Err: ErrUserBanned, Reason: s.Reason}
+	case StatusSuspended:
+		if s.SuspendedUntil != nil && now.Before(*s.SuspendedUntil) {
+			return &RestrictedError{Err: ErrUserSuspended, Reason: s.Reason, Until: s.SuspendedUntil}
+		}
+	}
+	return nil
+}
+
+// RestrictedError is returned when a banned or suspended user tries to sign
+// in. It matches ErrUserBanned or ErrUserSuspended with errors.Is.
+type RestrictedError struct {
+	Err    error
+	Reason string
+	Until  *time.Time
+}
+
+func (e *RestrictedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RestrictedError) Is(target error) bool {
+	return target == e.Err
+}
+
+// ModerationAction records who changed the role or the status of a user and
+// why. ActorID is the user ID, or api_key:<id> for actions taken with an API
+// key.
+type ModerationAction struct {
+	ID        string
+	UserID    string
+	ActorID   string
+	Action    string
+	Reason    string
+	Until     *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// OIDCProvider is an OpenID Connect identity provider players can sign in with.
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// ExternalIdentity is an account at an identity provider, linked to a user.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	UserID        string
+	Email         string
+	EmailVerified bool
+	// PreferredUsername seeds the username of users created on first login.
+	PreferredUsername string
+	CreatedAt         time.Time
+}
+
+// OIDCRedirect starts a login at a provider: the URL to send the player to,
+// and a secret binding the login to their browser. The secret is kept in a
+// cookie and has to come back with the callback, so a login started by
+// someone else can't be completed in the player's browser.
+type OIDCRedirect struct {
+	URL     string
+	Binding string
+}
+
+// OIDCLoginState is kept between redirecting a player to the provider and the
+// callback. The state value itself is never stored, only its hash.
+type OIDCLoginState struct {
+	Provider     string
+	CodeVerifier string // PKCE
+	Nonce        string
+	// UserID is the logged in user linking the identity, empty for logins.
+	UserID string
+	// BindingHash is the hash of the binding of the browser that started
+	// the login.
+	BindingHash string
+	ExpiresAt   time.Time
+}
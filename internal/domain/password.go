@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ResetToken lets a user who forgot their password set a new one. It can be
+// used once and only its hash is stored.
+type ResetToken struct {
+	ID        string
+	UserID    string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// Notification is a message sent to a user out of band, e.g. by email.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// PasswordPolicyError lists the rules a new password breaks. It matches
+// ErrPasswordPolicy with errors.Is.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrPasswordPolicy.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
@@ -0,0 +1,55 @@
+package domain
+
+// ProfileUpdate holds the profile fields a user wants to change. Nil fields
+// are left untouched; an empty string clears the field.
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	Country     *string
+	Bio         *string
+}
+
+// PublicProfile is what other players see of a user: the profile without
+// private data such as the email, and the games the user has scored in.
+type PublicProfile struct {
+	User   *User
+	Scores []Score
+}
+
+// ScoreSummary condenses the scores of a user.
+type ScoreSummary struct {
+	GamesPlayed int
+	TotalPoints int
+	Best        *Score
+}
+
+// SummarizeScores counts the games with points and picks the best score.
+// Games the user never scored in are skipped.
+func SummarizeScores(scores []Score) ScoreSummary {
+	var summary ScoreSummary
+	for i, score := range scores {
+		if score.Points <= 0 {
+			continue
+		}
+		summary.GamesPlayed++
+		summary.TotalPoints += score.Points
+		if summary.Best == nil || score.Points > summary.Best.Points {
+			summary.Best = &scores[i]
+		}
+	}
+	return summary
+}
+
+// UserQuery filters and pages the user listing.
+type UserQuery struct {
+	Search   string // matched against username, display name and email
+	Page     int    // 1-based
+	PageSize int
+}
+
+type UserPage struct {
+	Users    []User
+	Total    int64
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,78 @@
+package domain
+
+// Permission is a fine-grained capability checked by the API, written as
+// "<resource>:<action>".
+type Permission string
+
+const (
+	PermGamesRead    Permission = "games:read"
+	PermGamesCreate  Permission = "games:create"
+	PermScoresRead   Permission = "scores:read"
+	PermScoresSubmit Permission = "scores:submit"
+	PermUsersRead    Permission = "users:read"
+	PermUsersBan     Permission = "users:ban"
+	PermRolesAssign  Permission = "roles:assign"
+	PermAPIKeys      Permission = "apikeys:manage"
+	PermDataImport   Permission = "data:import"
+)
+
+// AllPermissions lists every permission known to the API. The admin role is
+// always granted all of them.
+var AllPermissions = []Permission{
+	PermGamesRead,
+	PermGamesCreate,
+	PermScoresRead,
+	PermScoresSubmit,
+	PermUsersRead,
+	PermUsersBan,
+	PermRolesAssign,
+	PermAPIKeys,
+	PermDataImport,
+}
+
+const (
+	RolePlayer      = "player"
+	RoleModerator   = "moderator"
+	RoleGameManager = "game-manager"
+	RoleAdmin       = "admin"
+)
+
+type Role struct {
+	Name        string
+	Description string
+	Permissions []Permission
+}
+
+// DefaultRoles are seeded into the database on startup.
+var DefaultRoles = []Role{
+	{
+		Name:        RolePlayer,
+		Description: "Plays games and browses leaderboards",
+		Permissions: []Permission{PermGamesRead, PermScoresRead},
+	},
+	{
+		Name:        RoleModerator,
+		Description: "Keeps the player community in order",
+		Permissions: []Permission{PermGamesRead, PermScoresRead, PermUsersRead, PermUsersBan},
+	},
+	{
+		Name:        RoleGameManager,
+		Description: "Manages games and submits scores",
+		Permissions: []Permission{PermGamesRead, PermGamesCreate, PermScoresRead, PermScoresSubmit},
+	},
+	{
+		Name:        RoleAdmin,
+		Description: "Full access to the API",
+		Permissions: AllPermissions,
+	},
+}
+
+// HasPermission reports whether perm is contained in perms.
+func HasPermission(perms []Permission, perm Permission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
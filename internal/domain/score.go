@@ -5,5 +5,12 @@ type Score struct {
 	UserID   string
 	Points   int
 	GameName string
+	GameSlug string
 	Username string
 }
+
+// BetterScore reports whether points beats best under the best-score rule:
+// every game keeps the highest score of each player.
+func BetterScore(points, best int) bool {
+	return points > best
+}
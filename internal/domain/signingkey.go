@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"crypto"
+	"time"
+)
+
+// Algorithms access tokens can be signed with.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWKSMaxAge is how long verifiers may cache the published key set.
+const JWKSMaxAge = 5 * time.Minute
+
+// SigningKey is one of the keys access tokens are signed with. A key is
+// published as soon as it is created, signs new tokens from ActivatesAt until
+// RotatesAt and is kept for verification until ExpiresAt, so tokens issued
+// right before a rotation stay valid.
+type SigningKey struct {
+	ID          string // sent as the kid header of the tokens it signs
+	Algorithm   string
+	PrivateKey  crypto.Signer
+	CreatedAt   time.Time
+	ActivatesAt time.Time
+	RotatesAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// JSONWebKey is the public half of a SigningKey as published in the JWKS
+// (RFC 7517). Binary values are base64url encoded.
+type JSONWebKey struct {
+	KeyType   string
+	KeyID     string
+	Use       string
+	Algorithm string
+	Modulus   string // RSA
+	Exponent  string // RSA
+	Curve     string // OKP
+	X         string // OKP
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// TokenPair is handed to a client after a successful login or refresh.
+type TokenPair struct {
+	AccessToken        string
+	RefreshToken       string
+	ExpiresIn          time.Duration
+	MustChangePassword bool
+}
+
+// AccessClaims are the verified contents of an access token.
+type AccessClaims struct {
+	TokenID     string
+	UserID      string
+	Username    string
+	Role        string
+	Permissions []Permission
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+
+	MustChangePassword bool
+}
+
+// RefreshToken is the server-side record of an issued refresh token. The
+// token value itself is never stored, only its hash.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
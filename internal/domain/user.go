@@ -1,7 +1,29 @@
 package domain
 
+import "time"
+
 type User struct {
 	ID       string
 	Username string
-	IsAdmin  bool
+	Email    string
+	Role     string
+
+	DisplayName string
+	AvatarURL   string
+	Country     string // ISO 3166-1 alpha-2
+	Bio         string
+	// MustChangePassword is set on accounts whose password was handed out,
+	// e.g. a bootstrapped admin. Such users may only change their password.
+	MustChangePassword bool
+	// IsGuest marks accounts created from a device identifier, without a
+	// password, until they are upgraded to a registered account.
+	IsGuest   bool
+	Status    AccountStatus
+	CreatedAt time.Time
+}
+
+// IsAdmin reports whether the user holds the admin role. Admins manage the
+// API and never take part in games.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ReservedUsernames can't be registered or taken in a rename, whatever their
+// case. RESERVED_USERNAMES adds more.
+var ReservedUsernames = []string{
+	"admin", "administrator", "root", "system", "support", "moderator",
+	"api", "me", "null", "undefined", "anonymous", "guest", "deleted",
+}
+
+// ReservedUsernamePrefixes start the usernames the API generates itself for
+// guests and anonymized accounts.
+var ReservedUsernamePrefixes = []string{"guest-", "deleted-"}
+
+// UsernameKey is the form usernames are compared in: two usernames that only
+// differ in case belong to the same user.
+func UsernameKey(username string) string {
+	return strings.ToLower(username)
+}
+
+// UsernameChange records a username a user gave up.
+type UsernameChange struct {
+	UserID    string
+	Username  string
+	ChangedAt time.Time
+}
+
+// RenameCooldownError is returned when a user renames again before the
+// cooldown passed. It matches ErrUsernameCooldown with errors.Is.
+type RenameCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RenameCooldownError) Error() string {
+	return ErrUsernameCooldown.Error()
+}
+
+func (e *RenameCooldownError) Is(target error) bool {
+	return target == ErrUsernameCooldown
+}
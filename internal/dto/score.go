@@ -5,11 +5,13 @@ type UserScoreDTO struct {
 	GameID   string `json:"game_id"   gorm:"column:game_id"`
 	Username string `json:"username"  gorm:"column:username"`
 	GameName string `json:"game_name" gorm:"column:game_name"`
+	GameSlug string `json:"game_slug" gorm:"column:game_slug"`
 	Points   int    `json:"points"    gorm:"column:points"`
 }
 
 type ScoreStatisticsDTO struct {
 	GameID   string  `json:"game_id"`
+	GameSlug string  `json:"game_slug"`
 	GameName string  `json:"game_name"`
 	Mean     float64 `json:"mean"`
 	Median   float64 `json:"median"`
@@ -0,0 +1,248 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// flushEvery is how many rows are buffered before they are sent.
+const flushEvery = 100
+
+// Encoder writes a list to the response one row at a time, so that exports
+// never hold the whole list in memory. The response starts with the first
+// row, or on Close for an empty list: until then the handler can still
+// answer with an error.
+type Encoder struct {
+	c       *gin.Context
+	format  Format
+	columns []column
+	csv     *csv.Writer
+	rows    int
+	started bool
+}
+
+// NewEncoder returns an encoder of rows of the struct type of row in format.
+func NewEncoder(c *gin.Context, format Format, row any) *Encoder {
+	return &Encoder{c: c, format: format, columns: columnsOf(reflect.TypeOf(row))}
+}
+
+// Started reports whether the response has been started, after which errors
+// can only be logged.
+func (e *Encoder) Started() bool {
+	return e.started
+}
+
+// Encode writes one row.
+func (e *Encoder) Encode(row any) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	var err error
+	switch e.format {
+	case CSV:
+		err = e.csv.Write(record(e.columns, reflect.ValueOf(row)))
+	case NDJSON:
+		err = writeJSONLine(e.c.Writer, row)
+	default:
+		if e.rows > 0 {
+			if _, err = e.c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		err = writeJSON(e.c.Writer, row)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+// Close ends the list and sends what is buffered.
+func (e *Encoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if e.format == JSON {
+		if _, err := e.c.Writer.WriteString("]"); err != nil {
+			return err
+		}
+	}
+	e.flush()
+	return nil
+}
+
+func (e *Encoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	e.c.Header("Content-Type", e.format.ContentType())
+	e.c.Status(http.StatusOK)
+
+	switch e.format {
+	case CSV:
+		e.csv = csv.NewWriter(e.c.Writer)
+		return e.csv.Write(header(e.columns))
+	case JSON:
+		_, err := e.c.Writer.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (e *Encoder) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	e.c.Writer.Flush()
+}
+
+// Write answers with a single value in format: a JSON object, a CSV header
+// and row, or one NDJSON line.
+func Write(c *gin.Context, status int, format Format, v any) {
+	switch format {
+	case CSV:
+		c.Header("Content-Type", format.ContentType())
+		c.Status(status)
+		columns := columnsOf(reflect.TypeOf(v))
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(header(columns))
+		_ = w.Write(record(columns, reflect.ValueOf(v)))
+		w.Flush()
+	case NDJSON:
+		c.Header("Content-Type", format.ContentType())
+		c.Status(status)
+		_ = writeJSONLine(c.Writer, v)
+	default:
+		c.JSON(status, v)
+	}
+}
+
+func writeJSON(w gin.ResponseWriter, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func writeJSONLine(w gin.ResponseWriter, v any) error {
+	if err := writeJSON(w, v); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\n")
+	return err
+}
+
+// column is a field of a row, named as in JSON.
+type column struct {
+	name  string
+	index int
+}
+
+func columnsOf(t reflect.Type) []column {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("export: rows must be structs, not %s", t))
+	}
+
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, column{name: name, index: i})
+	}
+	return columns
+}
+
+func header(columns []column) []string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+func record(columns []column, row reflect.Value) []string {
+	for row.Kind() == reflect.Pointer {
+		row = row.Elem()
+	}
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = cell(row.Field(col.index))
+	}
+	return cells
+}
+
+// cell formats a value for CSV. Lists are joined with semicolons.
+func cell(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return safeText(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = cell(v.Index(i))
+		}
+		return strings.Join(items, ";")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// safeText keeps spreadsheets from running user text, such as a game named
+// "=HYPERLINK(...)", as a formula.
+func safeText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package export_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/export"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	Username string  `json:"username"`
+	Points   int     `json:"points"`
+	Mean     float64 `json:"mean"`
+	Mode     []int   `json:"mode"`
+	Secret   string  `json:"-"`
+}
+
+var rows = []row{
+	{Username: "ana", Points: 30, Mean: 12.5, Mode: []int{1, 2}, Secret: "x"},
+	{Username: "=cmd()", Points: 20},
+}
+
+func newContext(target, accept string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	resp := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(resp)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	return c, resp
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		target, accept string
+		want           export.Format
+		err            error
+	}{
+		{"/", "", export.JSON, nil},
+		{"/", "text/csv", export.CSV, nil},
+		{"/", "application/x-ndjson", export.NDJSON, nil},
+		{"/", "application/json;q=0.5, text/csv", export.CSV, nil},
+		{"/", "text/html, */*;q=0.8", export.JSON, nil},
+		{"/", "text/*", export.CSV, nil},
+		{"/?format=ndjson", "text/csv", export.NDJSON, nil},
+		{"/?format=CSV", "", export.CSV, nil},
+		{"/?format=xml", "", "", export.ErrUnknownFormat},
+		{"/", "application/xml", "", domain.ErrNotAcceptable},
+	}
+	for _, tc := range cases {
+		c, _ := newContext(tc.target, tc.accept)
+		got, err := export.Negotiate(c)
+		assert.Equal(t, tc.want, got, "%s %q", tc.target, tc.accept)
+		assert.True(t, errors.Is(err, tc.err), "%s %q: %v", tc.target, tc.accept, err)
+	}
+}
+
+func encode(t *testing.T, format export.Format, list []row) *httptest.ResponseRecorder {
+	t.Helper()
+	c, resp := newContext("/", "")
+	enc := export.NewEncoder(c, format, row{})
+	for _, r := range list {
+		require.NoError(t, enc.Encode(r))
+	}
+	require.NoError(t, enc.Close())
+	return resp
+}
+
+func TestEncoder_CSV(t *testing.T) {
+	resp := encode(t, export.CSV, rows)
+
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "username,points,mean,mode\nana,30,12.5,1;2\n'=cmd(),20,0,\n", resp.Body.String())
+}
+
+func TestEncoder_NDJSON(t *testing.T) {
+	resp := encode(t, export.NDJSON, rows[:1])
+
+	assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `{"username":"ana","points":30,"mean":12.5,"mode":[1,2]}`+"\n", resp.Body.String())
+}
+
+func TestEncoder_JSON(t *testing.T) {
+	resp := encode(t, export.JSON, rows)
+	assert.JSONEq(t, `[
+		{"username":"ana","points":30,"mean":12.5,"mode":[1,2]},
+		{"username":"=cmd()","points":20,"mean":0,"mode":null}
+	]`, resp.Body.String())
+
+	resp = encode(t, export.JSON, nil)
+	assert.Equal(t, "[]", resp.Body.String())
+}
+
+func TestEncoder_EmptyCSVHasHeader(t *testing.T) {
+	resp := encode(t, export.CSV, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "username,points,mean,mode\n", resp.Body.String())
+}
+
+func TestEncoder_NotStartedUntilFirstRow(t *testing.T) {
+	c, _ := newContext("/", "")
+	enc := export.NewEncoder(c, export.CSV, row{})
+	assert.False(t, enc.Started())
+	require.NoError(t, enc.Encode(rows[0]))
+	assert.True(t, enc.Started())
+}
+
+func TestWrite_Single(t *testing.T) {
+	c, resp := newContext("/", "")
+	export.Write(c, http.StatusOK, export.CSV, &rows[0])
+	assert.Equal(t, "username,points,mean,mode\nana,30,12.5,1;2\n", resp.Body.String())
+}
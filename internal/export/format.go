@@ -0,0 +1,111 @@
+// Package export writes responses as JSON, CSV or NDJSON, as the client asks,
+// and streams lists row by row.
+package export
+
+import (
+	"errors"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Format is an encoding of a response.
+type Format string
+
+const (
+	JSON   Format = "json"
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// FormatParam is the query parameter that picks the format over Accept.
+const FormatParam = "format"
+
+// ErrUnknownFormat is returned by Negotiate for a format parameter naming no
+// supported format.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// mediaTypes maps the media types clients may ask for to formats, in the
+// order preferred when a wildcard matches several.
+var mediaTypes = []struct {
+	mediaType string
+	format    Format
+}{
+	{"application/json", JSON},
+	{"text/csv", CSV},
+	{"application/x-ndjson", NDJSON},
+	{"application/ndjson", NDJSON},
+}
+
+// ContentType returns the media type of responses in f.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Negotiate picks the format of the response: the format query parameter
+// when present, otherwise the preferred type of the Accept header. JSON is
+// the default. It returns ErrUnknownFormat for an unknown parameter and
+// domain.ErrNotAcceptable when Accept admits no supported type.
+func Negotiate(c *gin.Context) (Format, error) {
+	if param := c.Query(FormatParam); param != "" {
+		switch f := Format(strings.ToLower(param)); f {
+		case JSON, CSV, NDJSON:
+			return f, nil
+		}
+		return "", ErrUnknownFormat
+	}
+
+	accept := c.GetHeader("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	var (
+		best  Format
+		bestQ float64
+	)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for _, supported := range mediaTypes {
+			if matches(mediaType, supported.mediaType) {
+				best, bestQ = supported.format, q
+				break
+			}
+		}
+	}
+
+	if best == "" {
+		return "", domain.ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// matches reports whether the media range accepted admits mediaType.
+func matches(accepted, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+	kind, _, _ := strings.Cut(mediaType, "/")
+	return accepted == kind+"/*"
+}
@@ -0,0 +1,45 @@
+package hasher
+
+import (
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewBcrypt(cost int) ports.PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Verify(hash, password string) bool {
+	return verify(hash, password)
+}
+
+// NeedsRehash is true for hashes of other algorithms and of another cost.
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	if !isBcrypt(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+func (h *bcryptHasher) Recognizes(hash string) bool {
+	return recognizes(hash)
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
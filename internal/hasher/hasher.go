@@ -0,0 +1,60 @@
+// Package hasher hashes and verifies passwords. Every hasher verifies hashes
+// of all supported algorithms, which are told apart by their prefix, so the
+// configured algorithm can change without locking anyone out.
+package hasher
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// New builds the hasher selected by PASSWORD_HASH_ALG: "bcrypt" or
+// "argon2id", the default.
+func New() ports.PasswordHasher {
+	switch alg := strings.ToLower(os.Getenv("PASSWORD_HASH_ALG")); alg {
+	case "bcrypt":
+		return NewBcrypt(utils.EnvInt("BCRYPT_COST", bcrypt.DefaultCost))
+	case "", "argon2id":
+		return NewArgon2id(Argon2Params{
+			Memory:      uint32(utils.EnvInt("ARGON2_MEMORY", int(DefaultArgon2Params.Memory))),
+			Iterations:  uint32(utils.EnvInt("ARGON2_ITERATIONS", int(DefaultArgon2Params.Iterations))),
+			Parallelism: uint8(utils.EnvInt("ARGON2_PARALLELISM", int(DefaultArgon2Params.Parallelism))),
+			SaltLength:  DefaultArgon2Params.SaltLength,
+			KeyLength:   DefaultArgon2Params.KeyLength,
+		})
+	default:
+		log.Warn().Str("alg", alg).Msg("unknown PASSWORD_HASH_ALG, using argon2id")
+		return NewArgon2id(DefaultArgon2Params)
+	}
+}
+
+// verify checks password against a hash of any supported algorithm.
+func verify(hash, password string) bool {
+	switch {
+	case isBcrypt(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return verifyArgon2id(hash, password)
+	default:
+		// Includes the empty hash of accounts without a password.
+		return false
+	}
+}
+
+// recognizes reports whether hash is well formed for a supported algorithm.
+func recognizes(hash string) bool {
+	switch {
+	case isBcrypt(hash):
+		_, err := bcrypt.Cost([]byte(hash))
+		return err == nil
+	case strings.HasPrefix(hash, argon2idPrefix):
+		_, _, _, err := decodeArgon2id(hash)
+		return err == nil
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package hasher_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testParams = hasher.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2id_HashAndVerify(t *testing.T) {
+	h := hasher.NewArgon2id(testParams)
+
+	hash, err := h.Hash("s3cret-pass")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	assert.True(t, h.Verify(hash, "s3cret-pass"))
+	assert.False(t, h.Verify(hash, "wrong"))
+	assert.False(t, h.NeedsRehash(hash))
+
+	other, err := h.Hash("s3cret-pass")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "every hash gets its own salt")
+}
+
+func TestArgon2id_NeedsRehash(t *testing.T) {
+	h := hasher.NewArgon2id(testParams)
+
+	weaker, err := hasher.NewArgon2id(hasher.Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("pass")
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(weaker))
+	assert.True(t, h.Verify(weaker, "pass"), "old parameters still verify")
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.MinCost)
+	assert.True(t, h.NeedsRehash(string(legacy)))
+	assert.True(t, h.Verify(string(legacy), "pass"), "bcrypt hashes still verify")
+}
+
+func TestBcrypt_NeedsRehash(t *testing.T) {
+	h := hasher.NewBcrypt(bcrypt.MinCost + 1)
+
+	hash, err := h.Hash("pass")
+	require.NoError(t, err)
+	assert.False(t, h.NeedsRehash(hash))
+
+	cheaper, _ := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.MinCost)
+	assert.True(t, h.NeedsRehash(string(cheaper)))
+
+	argon, err := hasher.NewArgon2id(testParams).Hash("pass")
+	require.NoError(t, err)
+	assert.True(t, h.Verify(argon, "pass"))
+	assert.True(t, h.NeedsRehash(argon))
+}
+
+func TestVerify_RejectsMalformedHashes(t *testing.T) {
+	h := hasher.NewArgon2id(testParams)
+
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1024,t=1,p=1$bad", "$argon2id$v=18$m=1,t=1,p=1$c2FsdA$a2V5"} {
+		assert.False(t, h.Verify(hash, ""), hash)
+	}
+}
+
+func TestRecognizes(t *testing.T) {
+	h := hasher.NewArgon2id(testParams)
+
+	argon, err := h.Hash("s3cret-pass")
+	require.NoError(t, err)
+	bc, err := hasher.NewBcrypt(bcrypt.MinCost).Hash("s3cret-pass")
+	require.NoError(t, err)
+
+	assert.True(t, h.Recognizes(argon))
+	assert.True(t, h.Recognizes(bc))
+	assert.False(t, h.Recognizes(""))
+	assert.False(t, h.Recognizes("$2b$10$short"))
+	assert.False(t, h.Recognizes("$argon2id$v=19$broken"))
+	assert.False(t, h.Recognizes("5f4dcc3b5aa765d61d8327deb882cf99"))
+}
+
+func TestArgon2id_RejectsOutOfRangeParameters(t *testing.T) {
+	h := hasher.NewArgon2id(testParams)
+
+	for _, params := range []string{"m=1024,t=1,p=0", "m=1024,t=0,p=1", "m=4294967295,t=1,p=1", "m=1024,t=1000,p=1", "m=1024,t=1,p=255"} {
+		hash := "$argon2id$v=19$" + params + "$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+		assert.False(t, h.Recognizes(hash), params)
+		assert.False(t, h.Verify(hash, "pass"), params)
+		assert.True(t, h.NeedsRehash(hash), params)
+	}
+}
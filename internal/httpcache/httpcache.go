@@ -0,0 +1,80 @@
+// Package httpcache answers conditional GET requests and sets the caching
+// policy of responses.
+package httpcache
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Validators identify the version of a resource a response carries.
+type Validators struct {
+	// ETag is the entity tag, quoted and optionally weak, e.g. W/"3".
+	ETag         string
+	LastModified time.Time
+}
+
+// Control sets the Cache-Control header of the responses of a route. Error
+// responses replace it, see problem.Write.
+func Control(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value != "" {
+			c.Header("Cache-Control", value)
+		}
+		c.Next()
+	}
+}
+
+// NotModified sets the validators on the response and reports whether the
+// client already holds this version of the resource, in which case it has
+// answered 304 and the handler must not write a body. As RFC 9110 asks,
+// If-Modified-Since is only looked at when If-None-Match is absent.
+func NotModified(c *gin.Context, v Validators) bool {
+	if v.ETag != "" {
+		c.Header("ETag", v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		c.Header("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	method := c.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+
+	fresh := false
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		fresh = v.ETag != "" && etagMatches(match, v.ETag)
+	} else if since := c.GetHeader("If-Modified-Since"); since != "" && !v.LastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		// Last-Modified has second precision.
+		fresh = err == nil && !v.LastModified.Truncate(time.Second).After(t)
+	}
+
+	if fresh {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+	}
+	return fresh
+}
+
+// etagMatches compares the tags of an If-None-Match header with etag using
+// the weak comparison.
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if opaque(strings.TrimSpace(tag)) == opaque(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func opaque(tag string) string {
+	return strings.TrimPrefix(tag, "W/")
+}
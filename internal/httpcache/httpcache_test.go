@@ -0,0 +1,75 @@
+package httpcache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/httpcache"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var modified = time.Date(2026, time.October, 19, 10, 30, 0, 0, time.UTC)
+
+func serve(headers map[string]string) (*httptest.ResponseRecorder, bool) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	ran := false
+	r.GET("/leaderboard", httpcache.Control("private, no-cache"), func(c *gin.Context) {
+		if httpcache.NotModified(c, httpcache.Validators{ETag: `W/"g1.7"`, LastModified: modified}) {
+			return
+		}
+		ran = true
+		c.String(http.StatusOK, "scores")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/leaderboard", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp, ran
+}
+
+func TestNotModified_SetsValidators(t *testing.T) {
+	resp, ran := serve(nil)
+
+	assert.True(t, ran)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `W/"g1.7"`, resp.Header().Get("ETag"))
+	assert.Equal(t, "Mon, 19 Oct 2026 10:30:00 GMT", resp.Header().Get("Last-Modified"))
+	assert.Equal(t, "private, no-cache", resp.Header().Get("Cache-Control"))
+}
+
+func TestNotModified_IfNoneMatch(t *testing.T) {
+	resp, ran := serve(map[string]string{"If-None-Match": `"other", "g1.7"`})
+
+	assert.False(t, ran)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+	assert.Equal(t, `W/"g1.7"`, resp.Header().Get("ETag"))
+
+	_, ran = serve(map[string]string{"If-None-Match": `W/"g1.6"`})
+	assert.True(t, ran)
+}
+
+func TestNotModified_IfModifiedSince(t *testing.T) {
+	resp, ran := serve(map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)})
+	assert.False(t, ran)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	_, ran = serve(map[string]string{"If-Modified-Since": modified.Add(-time.Minute).Format(http.TimeFormat)})
+	assert.True(t, ran)
+}
+
+func TestNotModified_IfNoneMatchWins(t *testing.T) {
+	_, ran := serve(map[string]string{
+		"If-None-Match":     `W/"g1.6"`,
+		"If-Modified-Since": modified.Format(http.TimeFormat),
+	})
+	assert.True(t, ran)
+}
@@ -0,0 +1,37 @@
+// Package metrics holds the Prometheus collectors updated outside the HTTP
+// layer. They are registered on the API registry in main.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	LoginFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "api_login_failures_total",
+		Help: "Total number of failed login attempts",
+	})
+
+	LoginLockouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_login_lockouts_total",
+		Help: "Total number of login lockouts, by what was locked (user or ip)",
+	}, []string{"scope"})
+
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_requests_by_version_total",
+		Help: "Total number of requests, by API version, route and caller (api_key:<name>, user or anonymous)",
+	}, []string{"version", "route", "caller"})
+
+	IdempotentReplays = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_idempotent_replays_total",
+		Help: "Total number of responses replayed for a repeated Idempotency-Key, by route",
+	}, []string{"route"})
+
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_grpc_requests_total",
+		Help: "Total number of gRPC calls, by method and status code",
+	}, []string{"method", "code"})
+)
+
+// Collectors returns every collector of the package, for registration.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{LoginFailures, LoginLockouts, APIRequests, IdempotentReplays, GRPCRequests}
+}
@@ -1,23 +1,26 @@
 package middleware
 
 import (
-	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware authenticates the caller with a bearer access token or, as an
+// alternative for service accounts, an API key sent in the X-API-Key header.
+func AuthMiddleware(ts ports.TokenService, ks ports.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader("X-API-Key"); rawKey != "" {
+			authenticateAPIKey(c, ks, rawKey)
+			return
+		}
+
 		tokenStr := c.GetHeader("Authorization")
 		if tokenStr == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
-			c.Abort()
+			problem.Abort(c, domain.ErrAuthRequired)
 			return
 		}
 
@@ -26,25 +29,50 @@ func AuthMiddleware() gin.HandlerFunc {
 			tokenStr = parts[1]
 		}
 
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("internal server error")
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid access token"})
-			c.Abort()
+		claims, err := ts.ParseAccessToken(tokenStr)
+		if err != nil {
+			problem.Abort(c, err)
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("uid", claims["uid"])
-			c.Set("username", claims["username"])
-			c.Set("admin", claims["admin"])
-		}
+		c.Set("uid", claims.UserID)
+		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("permissions", claims.Permissions)
+		c.Set("admin", claims.Role == domain.RoleAdmin)
+		c.Set("must_change_password", claims.MustChangePassword)
+		c.Set("claims", claims)
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey grants the request the scopes of the key. Keys act on
+// their own behalf, so no user ID is set.
+func authenticateAPIKey(c *gin.Context, ks ports.APIKeyService, rawKey string) {
+	key, err := ks.Authenticate(rawKey)
+	if err != nil {
+		problem.Abort(c, err)
+		return
+	}
 
+	c.Set("username", key.Name)
+	c.Set("permissions", key.Scopes)
+	c.Set("admin", false)
+	c.Set("api_key", key)
+
+	c.Next()
+}
+
+// RequirePasswordChanged refuses requests from users that still have to
+// replace a password they were handed, e.g. a bootstrapped admin. It must run
+// after AuthMiddleware and must not guard the password change endpoint.
+func RequirePasswordChanged() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("must_change_password") {
+			problem.Abort(c, domain.ErrPasswordChangeRequired)
+			return
+		}
 		c.Next()
 	}
 }
@@ -52,10 +80,25 @@ func AuthMiddleware() gin.HandlerFunc {
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if isAdmin := c.GetBool("admin"); !isAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden resource"})
-			c.Abort()
+			problem.Abort(c, domain.ErrForbidden)
 			return
 		}
 		c.Next()
 	}
 }
+
+// RequirePermission only lets the request through when the caller holds every
+// one of the given permissions. It must run after AuthMiddleware.
+func RequirePermission(perms ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("permissions")
+		granted, _ := value.([]domain.Permission)
+		for _, perm := range perms {
+			if !domain.HasPermission(granted, perm) {
+				problem.Abort(c, domain.ErrForbidden)
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -3,45 +3,73 @@ package middleware_test
 import (
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"time"
 
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/middleware"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
 )
 
 var _ = Describe("AuthMiddleware", func() {
 	var r *gin.Engine
 	var token string
+	var tr *mocks.TokenRepositoryMock
+	var ur *mocks.UserRepositoryMock
+	var kr *mocks.APIKeyRepositoryMock
+	var signingKey *domain.SigningKey
+	var err error
 
 	BeforeEach(func() {
 		gin.SetMode(gin.TestMode)
 		r = gin.Default()
 
-		r.GET("/protected", middleware.AuthMiddleware(), func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"message": "Authorized"})
+		tr = new(mocks.TokenRepositoryMock)
+		skr := new(mocks.SigningKeyRepositoryMock)
+		skr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+		skr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{}, nil)
+		skr.On("CreateSigningKey", mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(0).(*domain.SigningKey).ID = "key-1"
+		}).Return(nil)
+		sks := services.NewKeyService(skr)
+		ur = new(mocks.UserRepositoryMock)
+		ts := services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), sks)
+
+		kr = new(mocks.APIKeyRepositoryMock)
+		ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+		r.GET("/protected", middleware.AuthMiddleware(ts, ks), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "Authorized", "permissions": c.MustGet("permissions")})
 		})
 
 		// Genera un token válido
-		secret := os.Getenv("JWT_SECRET")
+		signingKey, err = sks.SigningKey()
+		Expect(err).To(BeNil())
 		claims := jwt.MapClaims{
-			"uid":      1,
+			"jti":      "token-1",
+			"uid":      "1",
 			"username": "testuser",
-			"admin":    false,
+			"role":     "player",
+			"perms":    []string{"games:read"},
 			"iat":      time.Now().Unix(),
 			"exp":      time.Now().Add(time.Hour).Unix(),
 		}
-		t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		var err error
-		token, err = t.SignedString([]byte(secret))
+		t := jwt.NewWithClaims(jwt.GetSigningMethod(signingKey.Algorithm), claims)
+		t.Header["kid"] = signingKey.ID
+		token, err = t.SignedString(signingKey.PrivateKey)
 		Expect(err).To(BeNil())
 	})
 
 	Context("with valid token", func() {
 		It("should return 200", func() {
+			tr.On("IsAccessTokenRevoked", "token-1").Return(false, nil)
+			ur.On("GetAccountStatus", "1").Return(&domain.AccountStatus{State: domain.StatusActive}, nil)
+
 			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
 			req.Header.Set("Authorization", "Bearer "+token)
 			resp := httptest.NewRecorder()
@@ -53,6 +81,56 @@ var _ = Describe("AuthMiddleware", func() {
 		})
 	})
 
+	Context("with token of a banned user", func() {
+		It("should return 403 with the reason", func() {
+			tr.On("IsAccessTokenRevoked", "token-1").Return(false, nil)
+			ur.On("GetAccountStatus", "1").Return(&domain.AccountStatus{State: domain.StatusBanned, Reason: "cheating"}, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			Expect(resp.Body.String()).To(ContainSubstring(`"code":"account_banned"`))
+			Expect(resp.Body.String()).To(ContainSubstring("account is banned"))
+			Expect(resp.Body.String()).To(ContainSubstring("cheating"))
+		})
+	})
+
+	Context("with token of a user whose suspension ended", func() {
+		It("should return 200", func() {
+			until := time.Now().Add(-time.Minute)
+			tr.On("IsAccessTokenRevoked", "token-1").Return(false, nil)
+			ur.On("GetAccountStatus", "1").Return(&domain.AccountStatus{State: domain.StatusSuspended, SuspendedUntil: &until}, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("with token of a deleted user", func() {
+		It("should return 401", func() {
+			tr.On("IsAccessTokenRevoked", "token-1").Return(false, nil)
+			ur.On("GetAccountStatus", "1").Return(nil, domain.ErrUserNotFound)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
 	Context("with missing token", func() {
 		It("should return 401", func() {
 			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
@@ -78,6 +156,96 @@ var _ = Describe("AuthMiddleware", func() {
 		})
 	})
 
+	Context("with revoked token", func() {
+		It("should return 401", func() {
+			tr.On("IsAccessTokenRevoked", "token-1").Return(true, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+			Expect(resp.Body.String()).To(ContainSubstring("access token has been revoked"))
+		})
+	})
+
+	Context("with token without ID", func() {
+		It("should return 401", func() {
+			t := jwt.NewWithClaims(jwt.GetSigningMethod(signingKey.Algorithm), jwt.MapClaims{
+				"uid": "1",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			t.Header["kid"] = signingKey.ID
+			tokenStr, _ := t.SignedString(signingKey.PrivateKey)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenStr)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+			Expect(resp.Body.String()).To(ContainSubstring("invalid access token"))
+			tr.AssertNotCalled(GinkgoT(), "IsAccessTokenRevoked", "")
+		})
+	})
+
+	Context("with valid API key", func() {
+		It("should return 200 with the key scopes", func() {
+			key := &domain.APIKey{ID: "key1", Name: "bot", Scopes: []domain.Permission{domain.PermScoresSubmit}}
+			kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+			kr.On("TouchAPIKey", "key1", mock.AnythingOfType("time.Time")).Return(nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("X-API-Key", "gsk_secret")
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(ContainSubstring("scores:submit"))
+		})
+	})
+
+	Context("with revoked API key", func() {
+		It("should return 401", func() {
+			revokedAt := time.Now().Add(-time.Minute)
+			key := &domain.APIKey{ID: "key1", RevokedAt: &revokedAt}
+			kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("X-API-Key", "gsk_secret")
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+			Expect(resp.Body.String()).To(ContainSubstring("invalid api key"))
+		})
+	})
+
+	Context("with unknown key ID", func() {
+		It("should return 401", func() {
+			t := jwt.NewWithClaims(jwt.GetSigningMethod(signingKey.Algorithm), jwt.MapClaims{
+				"jti": "token-2",
+				"uid": "1",
+				"exp": time.Now().Add(time.Hour).Unix(),
+			})
+			t.Header["kid"] = "unknown"
+			tokenStr, _ := t.SignedString(signingKey.PrivateKey)
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tokenStr)
+			resp := httptest.NewRecorder()
+
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
 	Context("with wrong signing method", func() {
 		It("should return 401", func() {
 			// Generar un token con método incorrecto
@@ -145,3 +313,83 @@ var _ = Describe("AdminMiddleware", func() {
 		})
 	})
 })
+
+var _ = Describe("RequirePermission", func() {
+	var r *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		r = gin.Default()
+		r.Use(func(c *gin.Context) {
+			c.Set("permissions", []domain.Permission{domain.PermGamesRead, domain.PermScoresRead})
+			c.Next()
+		})
+	})
+
+	Context("when the caller holds the permission", func() {
+		It("allows access", func() {
+			r.GET("/games", middleware.RequirePermission(domain.PermGamesRead), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "games"})
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/games", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when the caller lacks one of the permissions", func() {
+		It("returns forbidden", func() {
+			r.POST("/games", middleware.RequirePermission(domain.PermGamesRead, domain.PermGamesCreate), func(c *gin.Context) {
+				c.JSON(http.StatusCreated, gin.H{"message": "created"})
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/games", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			Expect(resp.Code).To(Equal(http.StatusForbidden))
+			Expect(resp.Body.String()).To(ContainSubstring("forbidden resource"))
+		})
+	})
+})
+
+var _ = Describe("RequirePasswordChanged", func() {
+	var r *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		r = gin.Default()
+	})
+
+	It("blocks users that must change their password", func() {
+		r.Use(func(c *gin.Context) {
+			c.Set("must_change_password", true)
+			c.Next()
+		})
+		r.GET("/games", middleware.RequirePasswordChanged(), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "games"})
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/games", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		Expect(resp.Code).To(Equal(http.StatusForbidden))
+		Expect(resp.Body.String()).To(ContainSubstring("password change required"))
+	})
+
+	It("lets other users through", func() {
+		r.GET("/games", middleware.RequirePasswordChanged(), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "games"})
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/games", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+	})
+})
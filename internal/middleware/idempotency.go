@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/metrics"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key a client picks for a write it may
+	// retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotentBody bounds the body read to hash a request. The writes that
+// take a key send a few fields of JSON, and registration is open to anyone.
+const maxIdempotentBody = 1 << 20
+
+// storedHeaders are the response headers replayed along with the body.
+var storedHeaders = []string{"Content-Type", "Location", "ETag", "Last-Modified"}
+
+// Idempotency runs a write sent with an Idempotency-Key once: a retry with
+// the same key and body gets the first response back, and a reuse of the key
+// for another request is refused. Keys are kept apart per caller and route,
+// so it must run after AuthMiddleware on protected routes. Requests without
+// the header are let through.
+//
+// Server errors and 429 answers aren't stored, so those can be retried with
+// the same key.
+func Idempotency(is ports.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validToken(key, 255) {
+			problem.Abort(c, domain.ErrIdempotencyKeyInvalid)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Abort(c, domain.ErrRequestTooLarge)
+			return
+		}
+		if err != nil {
+			problem.Abort(c, domain.ErrInvalidRequest)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope, hash := idempotencyScope(c), requestHash(c, body)
+		stored, err := is.Begin(scope, key, hash)
+		if errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+			c.Header("Retry-After", "1")
+		}
+		if err != nil {
+			problem.Abort(c, err)
+			return
+		}
+		if stored != nil {
+			replay(c, stored)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			_ = is.Release(scope, key)
+			return
+		}
+
+		header := map[string][]string{}
+		for _, name := range storedHeaders {
+			if values := recorder.Header().Values(name); len(values) > 0 {
+				header[name] = values
+			}
+		}
+		_ = is.Complete(&domain.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: hash,
+			Status:      status,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		})
+	}
+}
+
+// idempotencyScope is the caller and the route of the request. Anonymous
+// callers are told apart by their address, so that one can't replay the
+// response another got by guessing their key.
+func idempotencyScope(c *gin.Context) string {
+	caller := "anonymous:" + c.ClientIP()
+	if value, ok := c.Get("api_key"); ok {
+		caller = "api_key:" + value.(*domain.APIKey).ID
+	} else if uid := c.GetString("uid"); uid != "" {
+		caller = "user:" + uid
+	}
+	return caller + " " + c.Request.Method + " " + c.FullPath()
+}
+
+// requestHash tells requests apart by their query and body.
+func requestHash(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(c *gin.Context, stored *domain.IdempotencyRecord) {
+	for name, values := range stored.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(stored.Status)
+	_, _ = c.Writer.Write(stored.Body)
+	c.Abort()
+
+	metrics.IdempotentReplays.WithLabelValues(c.FullPath()).Inc()
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
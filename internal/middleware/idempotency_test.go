@@ -0,0 +1,159 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/middleware"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Idempotency", func() {
+	var r *gin.Engine
+	var ir *mocks.IdempotencyRepositoryMock
+	var calls int
+	var status int
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPut, "/scores", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		r = gin.New()
+		ir = new(mocks.IdempotencyRepositoryMock)
+		calls, status = 0, http.StatusOK
+
+		r.PUT("/scores", func(c *gin.Context) {
+			c.Set("uid", "user-1")
+		}, middleware.Idempotency(services.NewIdempotencyService(ir)), func(c *gin.Context) {
+			calls++
+			c.Header("Location", "/scores/1")
+			c.JSON(status, gin.H{"points": 10})
+		})
+	})
+
+	It("lets requests without a key through", func() {
+		resp := send("", `{"points":10}`)
+
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		Expect(calls).To(Equal(1))
+		ir.AssertNotCalled(GinkgoT(), "ClaimIdempotencyKey", mock.Anything, mock.Anything)
+	})
+
+	It("stores the first response and replays it", func() {
+		var stored *domain.IdempotencyRecord
+		ir.On("ClaimIdempotencyKey", mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+			return r.Scope == "user:user-1 PUT /scores" && r.Key == "retry-1"
+		}), mock.Anything).Return(nil, nil).Once()
+		ir.On("CompleteIdempotencyKey", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*domain.IdempotencyRecord)
+		}).Return(nil).Once()
+
+		first := send("retry-1", `{"points":10}`)
+		Expect(first.Code).To(Equal(http.StatusOK))
+		Expect(stored.Status).To(Equal(http.StatusOK))
+		Expect(stored.Header["Location"]).To(Equal([]string{"/scores/1"}))
+
+		ir.On("ClaimIdempotencyKey", mock.Anything, mock.Anything).Return(stored, nil).Once()
+		replayed := send("retry-1", `{"points":10}`)
+
+		Expect(calls).To(Equal(1))
+		Expect(replayed.Code).To(Equal(http.StatusOK))
+		Expect(replayed.Body.String()).To(Equal(first.Body.String()))
+		Expect(replayed.Header().Get("Location")).To(Equal("/scores/1"))
+		Expect(replayed.Header().Get(middleware.IdempotentReplayedHeader)).To(Equal("true"))
+	})
+
+	It("refuses a key reused with another body", func() {
+		var claimed *domain.IdempotencyRecord
+		ir.On("ClaimIdempotencyKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			claimed = args.Get(0).(*domain.IdempotencyRecord)
+		}).Return(nil, nil).Once()
+		ir.On("CompleteIdempotencyKey", mock.Anything).Return(nil).Once()
+		send("retry-1", `{"points":10}`)
+
+		held := *claimed
+		held.Status = http.StatusOK
+		ir.On("ClaimIdempotencyKey", mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+			return r.RequestHash != held.RequestHash
+		}), mock.Anything).Return(&held, nil).Once()
+		resp := send("retry-1", `{"points":20}`)
+
+		Expect(resp.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(resp.Body.String()).To(ContainSubstring("idempotency_key_reused"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("asks to retry while the first request is running", func() {
+		held := &domain.IdempotencyRecord{}
+		ir.On("ClaimIdempotencyKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			held.RequestHash = args.Get(0).(*domain.IdempotencyRecord).RequestHash
+		}).Return(held, nil).Once()
+
+		resp := send("retry-1", `{"points":10}`)
+
+		Expect(resp.Code).To(Equal(http.StatusConflict))
+		Expect(resp.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(calls).To(Equal(0))
+	})
+
+	It("releases the key after a server error", func() {
+		status = http.StatusInternalServerError
+		ir.On("ClaimIdempotencyKey", mock.Anything, mock.Anything).Return(nil, nil).Once()
+		ir.On("ReleaseIdempotencyKey", "user:user-1 PUT /scores", "retry-1").Return(nil).Once()
+
+		send("retry-1", `{"points":10}`)
+
+		ir.AssertExpectations(GinkgoT())
+		ir.AssertNotCalled(GinkgoT(), "CompleteIdempotencyKey", mock.Anything)
+	})
+
+	It("refuses malformed keys", func() {
+		resp := send("has spaces", `{}`)
+
+		Expect(resp.Code).To(Equal(http.StatusBadRequest))
+		Expect(calls).To(Equal(0))
+	})
+
+	It("refuses bodies too large to hash", func() {
+		resp := send("retry-1", `{"name":"`+strings.Repeat("a", 1<<20)+`"}`)
+
+		Expect(resp.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(resp.Body.String()).To(ContainSubstring("request_too_large"))
+		Expect(calls).To(Equal(0))
+		ir.AssertNotCalled(GinkgoT(), "ClaimIdempotencyKey", mock.Anything, mock.Anything)
+	})
+
+	It("keeps anonymous callers apart by address", func() {
+		r.POST("/register", middleware.Idempotency(services.NewIdempotencyService(ir)), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		ir.On("ClaimIdempotencyKey", mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+			return r.Scope == "anonymous:192.0.2.1 POST /register"
+		}), mock.Anything).Return(nil, nil).Once()
+		ir.On("CompleteIdempotencyKey", mock.Anything).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/register", strings.NewReader(`{}`))
+		req.RemoteAddr = "192.0.2.1:4321"
+		req.Header.Set(middleware.IdempotencyKeyHeader, "retry-1")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		Expect(resp.Code).To(Equal(http.StatusCreated))
+		ir.AssertExpectations(GinkgoT())
+	})
+})
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an ID, echoed in the response and in problem
+// details so a failure a client reports can be found in the logs. An ID sent
+// by the client, e.g. set by a proxy, is kept when it looks sane.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(problem.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	return validToken(id, 128)
+}
+
+// validToken reports whether value is made of 1 to maxLen printable ASCII
+// characters, without spaces.
+func validToken(value string, maxLen int) bool {
+	if value == "" || len(value) > maxLen {
+		return false
+	}
+	for _, r := range value {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type AccountRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AccountRepositoryMock) ExportUserData(userID string) (*domain.UserExport, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserExport), args.Error(1)
+}
+
+func (m *AccountRepositoryMock) DeleteUser(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *AccountRepositoryMock) AnonymizeUser(userID, placeholder string) error {
+	args := m.Called(userID, placeholder)
+	return args.Error(0)
+}
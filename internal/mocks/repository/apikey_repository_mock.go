@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *APIKeyRepositoryMock) CreateAPIKey(key *domain.APIKey, keyHash string) error {
+	args := m.Called(key, keyHash)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepositoryMock) GetAPIKeyByHash(keyHash string) (*domain.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepositoryMock) ListAPIKeys() (*[]domain.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.APIKey), args.Error(1)
+}
+
+func (m *APIKeyRepositoryMock) RevokeAPIKey(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *APIKeyRepositoryMock) TouchAPIKey(id string, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
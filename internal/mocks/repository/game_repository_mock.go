@@ -11,8 +11,8 @@ type GameRepositoryMock struct {
 	mock.Mock
 }
 
-func (m *GameRepositoryMock) CreateGameWithInitialScores(ctx context.Context, name string) (*domain.Game, error) {
-	args := m.Called(ctx, name)
+func (m *GameRepositoryMock) CreateGameWithInitialScores(ctx context.Context, name, slug string) (*domain.Game, error) {
+	args := m.Called(ctx, name, slug)
 	return args.Get(0).(*domain.Game), args.Error(1)
 }
 
@@ -29,6 +29,11 @@ func (m *GameRepositoryMock) GetGameByID(id string) (*domain.Game, error) {
 	return args.Get(0).(*domain.Game), args.Error(1)
 }
 
+func (m *GameRepositoryMock) GetGamesByIDs(ids []string) (*[]domain.Game, error) {
+	args := m.Called(ids)
+	return args.Get(0).(*[]domain.Game), args.Error(1)
+}
+
 func (m *GameRepositoryMock) GetGameByName(name string) (*domain.Game, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
@@ -36,3 +41,11 @@ func (m *GameRepositoryMock) GetGameByName(name string) (*domain.Game, error) {
 	}
 	return args.Get(0).(*domain.Game), args.Error(1)
 }
+
+func (m *GameRepositoryMock) GetGameBySlug(slug string) (*domain.Game, error) {
+	args := m.Called(slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type GuestRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *GuestRepositoryMock) GetGuestByDevice(deviceHash string) (*domain.User, error) {
+	args := m.Called(deviceHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *GuestRepositoryMock) GetOrCreateGuest(ctx context.Context, deviceHash, username string) (*domain.User, error) {
+	args := m.Called(ctx, deviceHash, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *GuestRepositoryMock) UpgradeGuest(userID, username, email, passwordHash string) (*domain.User, error) {
+	args := m.Called(userID, username, email, passwordHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *GuestRepositoryMock) MergeGuest(guestID, userID string) error {
+	args := m.Called(guestID, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type IdempotencyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *IdempotencyRepositoryMock) ClaimIdempotencyKey(record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	args := m.Called(record, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Error(1)
+}
+
+func (m *IdempotencyRepositoryMock) CompleteIdempotencyKey(record *domain.IdempotencyRecord) error {
+	args := m.Called(record)
+	return args.Error(0)
+}
+
+func (m *IdempotencyRepositoryMock) ReleaseIdempotencyKey(scope, key string) error {
+	args := m.Called(scope, key)
+	return args.Error(0)
+}
+
+func (m *IdempotencyRepositoryMock) DeleteExpiredIdempotencyKeys(now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
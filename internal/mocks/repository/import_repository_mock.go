@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ImportRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ImportRepositoryMock) ImportUsers(ctx context.Context, rows []domain.ImportUser, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	// The service reuses the batch slice, so the mock records a copy.
+	args := m.Called(ctx, append([]domain.ImportUser(nil), rows...), conflict, dryRun)
+	return batchResult(args)
+}
+
+func (m *ImportRepositoryMock) ImportGames(ctx context.Context, rows []domain.ImportGame, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	args := m.Called(ctx, append([]domain.ImportGame(nil), rows...), conflict, dryRun)
+	return batchResult(args)
+}
+
+func (m *ImportRepositoryMock) ImportScores(ctx context.Context, rows []domain.ImportScore, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	args := m.Called(ctx, append([]domain.ImportScore(nil), rows...), conflict, dryRun)
+	return batchResult(args)
+}
+
+func batchResult(args mock.Arguments) (*domain.ImportBatchResult, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportBatchResult), args.Error(1)
+}
@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *LoginAttemptRepositoryMock) ListLoginAttempts(keys []string) (*[]domain.LoginAttempt, error) {
+	args := m.Called(keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.LoginAttempt), args.Error(1)
+}
+
+func (m *LoginAttemptRepositoryMock) RecordLoginFailure(key string, now time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	args := m.Called(key, now, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoginAttempt), args.Error(1)
+}
+
+func (m *LoginAttemptRepositoryMock) LockLogin(key string, until time.Time) error {
+	args := m.Called(key, until)
+	return args.Error(0)
+}
+
+func (m *LoginAttemptRepositoryMock) ClearLoginAttempts(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ModerationRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ModerationRepositoryMock) UpdateRole(action *domain.ModerationAction, role string) error {
+	args := m.Called(action, role)
+	return args.Error(0)
+}
+
+func (m *ModerationRepositoryMock) UpdateStatus(action *domain.ModerationAction, status *domain.AccountStatus) error {
+	args := m.Called(action, status)
+	return args.Error(0)
+}
+
+func (m *ModerationRepositoryMock) ListActions(userID string) (*[]domain.ModerationAction, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.ModerationAction), args.Error(1)
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type OIDCRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OIDCRepositoryMock) CreateLoginState(state *domain.OIDCLoginState, stateHash string) error {
+	args := m.Called(state, stateHash)
+	return args.Error(0)
+}
+
+func (m *OIDCRepositoryMock) ConsumeLoginState(stateHash string, now time.Time) (*domain.OIDCLoginState, error) {
+	args := m.Called(stateHash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OIDCLoginState), args.Error(1)
+}
+
+func (m *OIDCRepositoryMock) GetUserByIdentity(provider, subject string) (*domain.User, error) {
+	args := m.Called(provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *OIDCRepositoryMock) LinkIdentity(identity *domain.ExternalIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+func (m *OIDCRepositoryMock) CreateUserWithIdentity(ctx context.Context, username string, identity *domain.ExternalIdentity) (*domain.User, error) {
+	args := m.Called(ctx, username, identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordResetRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PasswordResetRepositoryMock) CreateResetToken(token *domain.ResetToken, tokenHash string) error {
+	args := m.Called(token, tokenHash)
+	return args.Error(0)
+}
+
+func (m *PasswordResetRepositoryMock) GetResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error) {
+	args := m.Called(tokenHash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ResetToken), args.Error(1)
+}
+
+func (m *PasswordResetRepositoryMock) ConsumeResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error) {
+	args := m.Called(tokenHash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ResetToken), args.Error(1)
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type RoleRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RoleRepositoryMock) ListRoles() (*[]domain.Role, error) {
+	args := m.Called()
+	return args.Get(0).(*[]domain.Role), args.Error(1)
+}
+
+func (m *RoleRepositoryMock) GetRole(name string) (*domain.Role, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Role), args.Error(1)
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,3 +27,34 @@ func (m *ScoreRepositoryMock) GetScoresByUserID(userID string) (*[]domain.Score,
 	args := m.Called(userID)
 	return args.Get(0).(*[]domain.Score), args.Error(1)
 }
+func (m *ScoreRepositoryMock) GetScoresByGameIDs(gameIDs []string, limit int) (*[]domain.Score, error) {
+	args := m.Called(gameIDs, limit)
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+func (m *ScoreRepositoryMock) GetScoresByUserIDs(userIDs []string) (*[]domain.Score, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+
+// StreamScoresByGameID hands the scores given to Return to fn, then returns
+// the error given to Return.
+func (m *ScoreRepositoryMock) StreamScoresByGameID(ctx context.Context, gameID string, fn func(*domain.Score) error) error {
+	args := m.Called(ctx, gameID)
+	return streamMocked(args, fn)
+}
+
+func (m *ScoreRepositoryMock) StreamScoresByUserID(ctx context.Context, userID string, fn func(*domain.Score) error) error {
+	args := m.Called(ctx, userID)
+	return streamMocked(args, fn)
+}
+
+func streamMocked(args mock.Arguments, fn func(*domain.Score) error) error {
+	if scores, ok := args.Get(0).(*[]domain.Score); ok && scores != nil {
+		for i := range *scores {
+			if err := fn(&(*scores)[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
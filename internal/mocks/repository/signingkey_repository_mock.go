@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type SigningKeyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SigningKeyRepositoryMock) CreateSigningKey(key *domain.SigningKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *SigningKeyRepositoryMock) ListSigningKeys(now time.Time) (*[]domain.SigningKey, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.SigningKey), args.Error(1)
+}
+
+func (m *SigningKeyRepositoryMock) DeleteExpiredSigningKeys(now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *TokenRepositoryMock) CreateRefreshToken(token *domain.RefreshToken, tokenHash string) error {
+	args := m.Called(token, tokenHash)
+	return args.Error(0)
+}
+
+func (m *TokenRepositoryMock) GetRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+func (m *TokenRepositoryMock) RotateRefreshToken(oldID string, next *domain.RefreshToken, nextHash string) error {
+	args := m.Called(oldID, next, nextHash)
+	return args.Error(0)
+}
+
+func (m *TokenRepositoryMock) RevokeRefreshToken(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *TokenRepositoryMock) RevokeUserRefreshTokens(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *TokenRepositoryMock) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	args := m.Called(tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *TokenRepositoryMock) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	args := m.Called(tokenID)
+	return args.Bool(0), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
@@ -17,6 +18,11 @@ func (m *UserRepositoryMock) GetUserByID(userID string) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *UserRepositoryMock) GetUsersByIDs(ids []string) (*[]domain.User, error) {
+	args := m.Called(ids)
+	return args.Get(0).(*[]domain.User), args.Error(1)
+}
+
 func (m *UserRepositoryMock) GetUserByUsername(userID string) (*domain.User, error) {
 	args := m.Called(userID)
 	return args.Get(0).(*domain.User), args.Error(1)
@@ -27,7 +33,88 @@ func (m *UserRepositoryMock) GetUserCreds(username string) (*auth.AuthUserData,
 	return args.Get(0).(*auth.AuthUserData), args.Error(1)
 }
 
-func (m *UserRepositoryMock) CreateUserWithInitialScores(ctx context.Context, username string, passwordHash string) (*domain.User, error) {
-	args := m.Called(ctx, username, passwordHash)
+func (m *UserRepositoryMock) GetUserByEmail(email string) (*domain.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *UserRepositoryMock) GetUserCredsByID(id string) (*auth.AuthUserData, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.AuthUserData), args.Error(1)
+}
+
+func (m *UserRepositoryMock) GetAccountStatus(userID string) (*domain.AccountStatus, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccountStatus), args.Error(1)
+}
+
+func (m *UserRepositoryMock) CreateUserWithInitialScores(ctx context.Context, username, email, passwordHash string) (*domain.User, error) {
+	args := m.Called(ctx, username, email, passwordHash)
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *UserRepositoryMock) CreateAdmin(username, passwordHash string, mustChangePassword bool) (*domain.User, error) {
+	args := m.Called(username, passwordHash, mustChangePassword)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *UserRepositoryMock) UpdatePassword(userID, passwordHash string, mustChange bool) error {
+	args := m.Called(userID, passwordHash, mustChange)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) SetUserRole(userID, role string) error {
+	args := m.Called(userID, role)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) CountUsersByRole(role string) (int64, error) {
+	args := m.Called(role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *UserRepositoryMock) UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*domain.User), args.Error(1)
 }
+
+func (m *UserRepositoryMock) ListUsers(query *domain.UserQuery) (*[]domain.User, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).(*[]domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *UserRepositoryMock) RenameUser(userID, username string) error {
+	args := m.Called(userID, username)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) ListUsernameHistory(userID string) (*[]domain.UsernameChange, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.UsernameChange), args.Error(1)
+}
+
+func (m *UserRepositoryMock) IsUsernameHeld(username, userID string, since time.Time) (bool, error) {
+	args := m.Called(username, userID, since)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyServiceMock struct {
+	mock.Mock
+}
+
+func (m *APIKeyServiceMock) CreateAPIKey(key *domain.APIKey, gameRefs []string) (*domain.APIKey, string, error) {
+	args := m.Called(key, gameRefs)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*domain.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *APIKeyServiceMock) ListAPIKeys() (*[]domain.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.APIKey), args.Error(1)
+}
+
+func (m *APIKeyServiceMock) RevokeAPIKey(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *APIKeyServiceMock) Authenticate(rawKey string) (*domain.APIKey, error) {
+	args := m.Called(rawKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.APIKey), args.Error(1)
+}
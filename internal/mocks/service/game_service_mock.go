@@ -1 +1,42 @@
 package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type GameServiceMock struct {
+	mock.Mock
+}
+
+func (m *GameServiceMock) CreateGame(gameName, slug string) (*domain.Game, error) {
+	args := m.Called(gameName, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
+
+func (m *GameServiceMock) GetGames() (*[]domain.Game, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Game), args.Error(1)
+}
+
+func (m *GameServiceMock) GetGame(ref string) (*domain.Game, error) {
+	args := m.Called(ref)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Game), args.Error(1)
+}
+
+func (m *GameServiceMock) GetGamesByIDs(ids []string) (*[]domain.Game, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Game), args.Error(1)
+}
@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ProfileServiceMock struct {
+	mock.Mock
+}
+
+func (m *ProfileServiceMock) GetProfile(userID string) (*domain.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *ProfileServiceMock) UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *ProfileServiceMock) GetPublicProfile(userID string) (*domain.PublicProfile, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PublicProfile), args.Error(1)
+}
+
+func (m *ProfileServiceMock) GetUsers(ids []string) (*[]domain.User, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.User), args.Error(1)
+}
+
+func (m *ProfileServiceMock) ListUsers(query *domain.UserQuery) (*domain.UserPage, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserPage), args.Error(1)
+}
+
+func (m *ProfileServiceMock) Rename(userID, username string) (*domain.User, error) {
+	args := m.Called(userID, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *ProfileServiceMock) UsernameHistory(userID string) (*[]domain.UsernameChange, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.UsernameChange), args.Error(1)
+}
@@ -1 +1,89 @@
 package mocks
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type ScoreServiceMock struct {
+	mock.Mock
+}
+
+func (m *ScoreServiceMock) Submit(score *domain.Score) error {
+	args := m.Called(score)
+	return args.Error(0)
+}
+
+func (m *ScoreServiceMock) GetGameScores(gameRef string) (*[]domain.Score, error) {
+	args := m.Called(gameRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+
+func (m *ScoreServiceMock) GetUserScores(userID string) (*[]domain.Score, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+
+func (m *ScoreServiceMock) GetScoresByGames(gameIDs []string, limit int) (*[]domain.Score, error) {
+	args := m.Called(gameIDs, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+
+func (m *ScoreServiceMock) GetScoresByUsers(userIDs []string) (*[]domain.Score, error) {
+	args := m.Called(userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*[]domain.Score), args.Error(1)
+}
+
+// StreamGameScores hands the scores set with Return to fn.
+func (m *ScoreServiceMock) StreamGameScores(ctx context.Context, gameRef string, fn func(*domain.Score) error) error {
+	args := m.Called(ctx, gameRef, fn)
+	return stream(args, fn)
+}
+
+// StreamUserScores hands the scores set with Return to fn.
+func (m *ScoreServiceMock) StreamUserScores(ctx context.Context, userID string, fn func(*domain.Score) error) error {
+	args := m.Called(ctx, userID, fn)
+	return stream(args, fn)
+}
+
+func (m *ScoreServiceMock) GetUserGameScore(userID, gameRef string) (*domain.Score, error) {
+	args := m.Called(userID, gameRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Score), args.Error(1)
+}
+
+func (m *ScoreServiceMock) GetGameStats(gameRef string) (*dto.ScoreStatisticsDTO, error) {
+	args := m.Called(gameRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ScoreStatisticsDTO), args.Error(1)
+}
+
+func stream(args mock.Arguments, fn func(*domain.Score) error) error {
+	if scores, ok := args.Get(0).(*[]domain.Score); ok {
+		for i := range *scores {
+			if err := fn(&(*scores)[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
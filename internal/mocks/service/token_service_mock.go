@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenServiceMock struct {
+	mock.Mock
+}
+
+func (m *TokenServiceMock) IssueTokens(user *domain.User) (*domain.TokenPair, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+
+func (m *TokenServiceMock) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+
+func (m *TokenServiceMock) Logout(claims *domain.AccessClaims, refreshToken string) error {
+	args := m.Called(claims, refreshToken)
+	return args.Error(0)
+}
+
+func (m *TokenServiceMock) RevokeUserSessions(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *TokenServiceMock) ParseAccessToken(token string) (*domain.AccessClaims, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AccessClaims), args.Error(1)
+}
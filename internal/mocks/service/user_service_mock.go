@@ -1 +1,26 @@
 package mocks
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserServiceMock struct {
+	mock.Mock
+}
+
+func (m *UserServiceMock) RegisterUser(username, email, password string) (*domain.User, error) {
+	args := m.Called(username, email, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *UserServiceMock) LoginUser(username, password, ip string) (*domain.TokenPair, error) {
+	args := m.Called(username, password, ip)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
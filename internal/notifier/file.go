@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier returns a notifier that appends every message as a JSON
+// line to the file at path. Messages may carry secrets such as reset tokens,
+// so it is not meant for production.
+func NewFileNotifier(path string) ports.Notifier {
+	return &fileNotifier{path: path}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a notifier that writes every message, body
+// included, to the log. Like the file notifier it is for local use only.
+func NewLogNotifier() ports.Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Send(msg *domain.Notification) error {
+	log.Info().Str("to", msg.To).Str("subject", msg.Subject).Str("body", msg.Body).Msg("notification")
+	return nil
+}
+
+type fileMessage struct {
+	SentAt  time.Time `json:"sent_at"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+func (n *fileNotifier) Send(msg *domain.Notification) error {
+	line, err := json.Marshal(fileMessage{
+		SentAt:  time.Now(),
+		To:      msg.To,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,78 @@
+package notifier_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/notifier"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentMessage struct {
+	SentAt  time.Time `json:"sent_at"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+func TestFileNotifier_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	n := notifier.NewFileNotifier(path)
+
+	require.NoError(t, n.Send(&domain.Notification{To: "ana@example.com", Subject: "Reset", Body: "token: abc\nbye"}))
+	require.NoError(t, n.Send(&domain.Notification{To: "bob@example.com", Subject: "Reset", Body: "token: def"}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var messages []sentMessage
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg sentMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		messages = append(messages, msg)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, "ana@example.com", messages[0].To)
+	assert.Equal(t, "token: abc\nbye", messages[0].Body, "line breaks stay inside the JSON line")
+	assert.Equal(t, "bob@example.com", messages[1].To)
+	assert.WithinDuration(t, time.Now(), messages[1].SentAt, time.Minute)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "messages carry secrets")
+}
+
+func TestLogNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+
+	require.NoError(t, notifier.NewLogNotifier().Send(&domain.Notification{To: "ana@example.com", Subject: "Reset", Body: "token: abc"}))
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "notification", entry["message"])
+	assert.Equal(t, "ana@example.com", entry["to"])
+	assert.Equal(t, "Reset", entry["subject"])
+	assert.Equal(t, "token: abc", entry["body"])
+}
+
+func TestFileNotifier_ReportsWriteErrors(t *testing.T) {
+	n := notifier.NewFileNotifier(filepath.Join(t.TempDir(), "missing", "outbox.jsonl"))
+
+	assert.Error(t, n.Send(&domain.Notification{To: "ana@example.com"}))
+}
@@ -0,0 +1,43 @@
+// Package notifier delivers messages such as password reset links to users.
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+)
+
+// ErrNotConfigured is returned by New when NOTIFIER is not set. There is no
+// default: the notifiers meant for local use expose reset tokens.
+var ErrNotConfigured = errors.New("NOTIFIER is not set, use smtp, file or log")
+
+// New builds the notifier selected by NOTIFIER: "smtp" sends emails, "file"
+// appends messages to NOTIFIER_FILE and "log" writes them to the log. The
+// last two are meant for local use only.
+func New() (ports.Notifier, error) {
+	switch kind := strings.ToLower(os.Getenv("NOTIFIER")); kind {
+	case "smtp":
+		return NewSMTPNotifier(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}), nil
+	case "file":
+		path := os.Getenv("NOTIFIER_FILE")
+		if path == "" {
+			return nil, errors.New("NOTIFIER=file needs NOTIFIER_FILE")
+		}
+		return NewFileNotifier(path), nil
+	case "log":
+		return NewLogNotifier(), nil
+	case "":
+		return nil, ErrNotConfigured
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", kind)
+	}
+}
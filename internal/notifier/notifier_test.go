@@ -0,0 +1,32 @@
+package notifier_test
+
+import (
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/notifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_RequiresConfiguration(t *testing.T) {
+	t.Setenv("NOTIFIER", "")
+	t.Setenv("NOTIFIER_FILE", "")
+
+	_, err := notifier.New()
+	assert.ErrorIs(t, err, notifier.ErrNotConfigured)
+
+	t.Setenv("NOTIFIER", "file")
+	_, err = notifier.New()
+	assert.Error(t, err, "the file notifier needs a path")
+
+	t.Setenv("NOTIFIER", "carrier-pigeon")
+	_, err = notifier.New()
+	assert.Error(t, err)
+}
+
+func TestNew_LogIsOptIn(t *testing.T) {
+	t.Setenv("NOTIFIER", "log")
+
+	n, err := notifier.New()
+	assert.NoError(t, err)
+	assert.NotNil(t, n)
+}
@@ -0,0 +1,102 @@
+package notifier_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/notifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts a single message and sends its data on the returned
+// channel.
+func fakeSMTPServer(t *testing.T) (host, port string, data <-chan string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var msg strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					msg.WriteString(line)
+				}
+				out <- msg.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, err = net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+	return host, port, out
+}
+
+func TestSMTPNotifier_Send(t *testing.T) {
+	host, port, data := fakeSMTPServer(t)
+	n := notifier.NewSMTPNotifier(notifier.SMTPConfig{Host: host, Port: port, From: "scores@example.com"})
+
+	require.NoError(t, n.Send(&domain.Notification{To: "ana@example.com", Subject: "Reset your password", Body: "Hi ana,\ntoken: abc"}))
+
+	msg := <-data
+	headers, body, ok := strings.Cut(msg, "\r\n\r\n")
+	require.True(t, ok)
+	assert.Contains(t, headers, "From: scores@example.com\r\n")
+	assert.Contains(t, headers, "To: ana@example.com\r\n")
+	assert.Contains(t, headers, "Subject: Reset your password\r\n")
+	assert.Equal(t, "Hi ana,\r\ntoken: abc\r\n", body)
+}
+
+func TestSMTPNotifier_SanitizesHeaders(t *testing.T) {
+	host, port, data := fakeSMTPServer(t)
+	n := notifier.NewSMTPNotifier(notifier.SMTPConfig{Host: host, Port: port, From: "scores@example.com"})
+
+	require.NoError(t, n.Send(&domain.Notification{
+		To:      "ana@example.com",
+		Subject: "Reset\r\nBcc: mallory@example.com\nX-Injected: yes",
+		Body:    "token: abc",
+	}))
+
+	headers, _, _ := strings.Cut(<-data, "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		assert.False(t, strings.HasPrefix(line, "Bcc:"), line)
+		assert.False(t, strings.HasPrefix(line, "X-Injected:"), line)
+	}
+	assert.Contains(t, headers, "Subject: ResetBcc: mallory@example.comX-Injected: yes\r\n")
+}
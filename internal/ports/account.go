@@ -0,0 +1,14 @@
+package ports
+
+import "github.com/Martin-Arias/go-scoring-api/internal/domain"
+
+type AccountRepository interface {
+	ExportUserData(userID string) (*domain.UserExport, error)
+	DeleteUser(userID string) error
+	AnonymizeUser(userID, placeholder string) error
+}
+
+type AccountService interface {
+	Export(userID string) (*domain.UserExport, error)
+	DeleteAccount(claims *domain.AccessClaims, password string) error
+}
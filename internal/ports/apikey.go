@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *domain.APIKey, keyHash string) error
+	GetAPIKeyByHash(keyHash string) (*domain.APIKey, error)
+	ListAPIKeys() (*[]domain.APIKey, error)
+	RevokeAPIKey(id string) error
+	TouchAPIKey(id string, usedAt time.Time) error
+}
+
+type APIKeyService interface {
+	CreateAPIKey(key *domain.APIKey, gameRefs []string) (*domain.APIKey, string, error)
+	ListAPIKeys() (*[]domain.APIKey, error)
+	RevokeAPIKey(id string) error
+	Authenticate(rawKey string) (*domain.APIKey, error)
+}
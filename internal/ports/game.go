@@ -7,13 +7,17 @@ import (
 )
 
 type GameService interface {
-	CreateGame(gameName string) (*domain.Game, error)
+	CreateGame(gameName, slug string) (*domain.Game, error)
 	GetGames() (*[]domain.Game, error)
+	GetGame(ref string) (*domain.Game, error)
+	GetGamesByIDs(ids []string) (*[]domain.Game, error)
 }
 
 type GameRepository interface {
 	ListGames() (*[]domain.Game, error)
 	GetGameByID(id string) (*domain.Game, error)
+	GetGamesByIDs(ids []string) (*[]domain.Game, error)
 	GetGameByName(name string) (*domain.Game, error)
-	CreateGameWithInitialScores(ctx context.Context, name string) (*domain.Game, error)
+	GetGameBySlug(slug string) (*domain.Game, error)
+	CreateGameWithInitialScores(ctx context.Context, name, slug string) (*domain.Game, error)
 }
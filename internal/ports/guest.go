@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type GuestRepository interface {
+	GetGuestByDevice(deviceHash string) (*domain.User, error)
+	GetOrCreateGuest(ctx context.Context, deviceHash, username string) (*domain.User, error)
+	UpgradeGuest(userID, username, email, passwordHash string) (*domain.User, error)
+	MergeGuest(guestID, userID string) error
+}
+
+type GuestService interface {
+	LoginGuest(deviceID string) (*domain.TokenPair, error)
+	Upgrade(userID, username, email, password string) (*domain.User, error)
+	Merge(userID, deviceID string) (*[]domain.Score, error)
+}
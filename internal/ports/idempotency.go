@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	// ClaimIdempotencyKey stores record unless its key is taken by a record
+	// that hasn't expired or been given up for lost. It returns that record
+	// when it does.
+	ClaimIdempotencyKey(record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error)
+	CompleteIdempotencyKey(record *domain.IdempotencyRecord) error
+	ReleaseIdempotencyKey(scope, key string) error
+	DeleteExpiredIdempotencyKeys(now time.Time) error
+}
+
+type IdempotencyService interface {
+	// Begin claims key for the request. It returns the stored record to
+	// replay when the request was already answered, and nil when the caller
+	// should run it and then call Complete or Release.
+	Begin(scope, key, requestHash string) (*domain.IdempotencyRecord, error)
+	// Complete stores the response of the request record was claimed for.
+	Complete(record *domain.IdempotencyRecord) error
+	// Release forgets the key, so a retry runs the request again.
+	Release(scope, key string) error
+	Purge() error
+}
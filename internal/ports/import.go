@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"io"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+// ImportRepository writes one batch of imported rows in a transaction. With
+// dryRun the transaction is rolled back, so the result tells what would have
+// happened.
+type ImportRepository interface {
+	ImportUsers(ctx context.Context, rows []domain.ImportUser, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error)
+	ImportGames(ctx context.Context, rows []domain.ImportGame, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error)
+	ImportScores(ctx context.Context, rows []domain.ImportScore, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error)
+}
+
+type ImportService interface {
+	Import(ctx context.Context, file io.Reader, opts domain.ImportOptions) (*domain.ImportReport, error)
+}
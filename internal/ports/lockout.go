@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type LoginAttemptRepository interface {
+	ListLoginAttempts(keys []string) (*[]domain.LoginAttempt, error)
+	RecordLoginFailure(key string, now time.Time, window time.Duration) (*domain.LoginAttempt, error)
+	LockLogin(key string, until time.Time) error
+	ClearLoginAttempts(key string) error
+}
+
+type LockoutService interface {
+	Check(username, ip string) error
+	RecordFailure(username, ip string) error
+	RecordSuccess(username string) error
+	Unlock(userID string) error
+}
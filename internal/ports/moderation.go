@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type ModerationRepository interface {
+	UpdateRole(action *domain.ModerationAction, role string) error
+	UpdateStatus(action *domain.ModerationAction, status *domain.AccountStatus) error
+	ListActions(userID string) (*[]domain.ModerationAction, error)
+}
+
+type ModerationService interface {
+	Promote(actorID, userID, reason string) (*domain.ModerationAction, error)
+	Demote(actorID, userID, reason string) (*domain.ModerationAction, error)
+	Suspend(actorID, userID string, until time.Time, reason string) (*domain.ModerationAction, error)
+	Ban(actorID, userID, reason string) (*domain.ModerationAction, error)
+	Reinstate(actorID, userID, reason string) (*domain.ModerationAction, error)
+	History(userID string) (*[]domain.ModerationAction, error)
+}
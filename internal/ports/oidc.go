@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type OIDCRepository interface {
+	CreateLoginState(state *domain.OIDCLoginState, stateHash string) error
+	ConsumeLoginState(stateHash string, now time.Time) (*domain.OIDCLoginState, error)
+	GetUserByIdentity(provider, subject string) (*domain.User, error)
+	LinkIdentity(identity *domain.ExternalIdentity) error
+	CreateUserWithIdentity(ctx context.Context, username string, identity *domain.ExternalIdentity) (*domain.User, error)
+}
+
+type OIDCService interface {
+	AuthCodeURL(ctx context.Context, provider string) (*domain.OIDCRedirect, error)
+	LinkURL(ctx context.Context, provider, userID string) (*domain.OIDCRedirect, error)
+	Callback(ctx context.Context, provider, code, state, binding string) (*domain.TokenPair, error)
+}
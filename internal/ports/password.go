@@ -0,0 +1,45 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type PasswordResetRepository interface {
+	CreateResetToken(token *domain.ResetToken, tokenHash string) error
+	GetResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error)
+	ConsumeResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error)
+}
+
+type PasswordService interface {
+	ChangePassword(userID, currentPassword, newPassword string) (*domain.TokenPair, error)
+	RequestPasswordReset(login string) error
+	ResetPassword(token, newPassword string) error
+}
+
+// Notifier delivers messages to users.
+type Notifier interface {
+	Send(msg *domain.Notification) error
+}
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash. Hashes in an unknown
+	// format, including the empty one, never match.
+	Verify(hash, password string) bool
+	// NeedsRehash reports whether hash was made with another algorithm or
+	// weaker parameters than the hasher uses now.
+	NeedsRehash(hash string) bool
+	// Recognizes reports whether hash is a well-formed hash of a supported
+	// algorithm, such as one imported from another system.
+	Recognizes(hash string) bool
+}
+
+// PasswordPolicy decides which new passwords are acceptable.
+type PasswordPolicy interface {
+	// Validate returns a *domain.PasswordPolicyError listing every rule the
+	// password breaks. username may be empty when it is not known.
+	Validate(password, username string) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "github.com/Martin-Arias/go-scoring-api/internal/domain"
+
+type RoleRepository interface {
+	ListRoles() (*[]domain.Role, error)
+	GetRole(name string) (*domain.Role, error)
+}
+
+type RoleService interface {
+	ListRoles() (*[]domain.Role, error)
+	AssignRole(userID, role string) error
+}
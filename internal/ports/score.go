@@ -1,6 +1,8 @@
 package ports
 
 import (
+	"context"
+
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/dto"
 )
@@ -8,13 +10,22 @@ import (
 type ScoreRepository interface {
 	GetScoresByGameID(gameID string) (*[]domain.Score, error)
 	GetScoresByUserID(playerID string) (*[]domain.Score, error)
+	GetScoresByGameIDs(gameIDs []string, limit int) (*[]domain.Score, error)
+	GetScoresByUserIDs(userIDs []string) (*[]domain.Score, error)
 	GetScore(playerID, gameID string) (*domain.Score, error)
+	StreamScoresByGameID(ctx context.Context, gameID string, fn func(*domain.Score) error) error
+	StreamScoresByUserID(ctx context.Context, userID string, fn func(*domain.Score) error) error
 	SubmitScore(score *domain.Score) error
 }
 
 type ScoreService interface {
 	Submit(score *domain.Score) error
-	GetGameScores(gameID string) (*[]domain.Score, error)
+	GetGameScores(gameRef string) (*[]domain.Score, error)
 	GetUserScores(userID string) (*[]domain.Score, error)
-	GetGameStats(gameID string) (*dto.ScoreStatisticsDTO, error)
+	GetScoresByGames(gameIDs []string, limit int) (*[]domain.Score, error)
+	GetScoresByUsers(userIDs []string) (*[]domain.Score, error)
+	StreamGameScores(ctx context.Context, gameRef string, fn func(*domain.Score) error) error
+	StreamUserScores(ctx context.Context, userID string, fn func(*domain.Score) error) error
+	GetUserGameScore(userID, gameRef string) (*domain.Score, error)
+	GetGameStats(gameRef string) (*dto.ScoreStatisticsDTO, error)
 }
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type SigningKeyRepository interface {
+	CreateSigningKey(key *domain.SigningKey) error
+	ListSigningKeys(now time.Time) (*[]domain.SigningKey, error)
+	DeleteExpiredSigningKeys(now time.Time) error
+}
+
+type KeyService interface {
+	Rotate() error
+	SigningKey() (*domain.SigningKey, error)
+	VerificationKey(kid string) (*domain.SigningKey, error)
+	JWKS() ([]domain.JSONWebKey, error)
+}
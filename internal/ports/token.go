@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *domain.RefreshToken, tokenHash string) error
+	GetRefreshToken(tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshToken(oldID string, next *domain.RefreshToken, nextHash string) error
+	RevokeRefreshToken(id string) error
+	RevokeUserRefreshTokens(userID string) error
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+}
+
+type TokenService interface {
+	IssueTokens(user *domain.User) (*domain.TokenPair, error)
+	Refresh(refreshToken string) (*domain.TokenPair, error)
+	Logout(claims *domain.AccessClaims, refreshToken string) error
+	RevokeUserSessions(userID string) error
+	ParseAccessToken(token string) (*domain.AccessClaims, error)
+}
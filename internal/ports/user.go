@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
@@ -9,12 +10,41 @@ import (
 
 type UserRepository interface {
 	GetUserByID(id string) (*domain.User, error)
+	GetUsersByIDs(ids []string) (*[]domain.User, error)
 	GetUserByUsername(username string) (*domain.User, error)
+	GetUserByEmail(email string) (*domain.User, error)
 	GetUserCreds(username string) (*auth.AuthUserData, error)
-	CreateUserWithInitialScores(ctx context.Context, username string, passwordHash string) (*domain.User, error)
+	GetUserCredsByID(id string) (*auth.AuthUserData, error)
+	GetAccountStatus(userID string) (*domain.AccountStatus, error)
+	CreateUserWithInitialScores(ctx context.Context, username, email, passwordHash string) (*domain.User, error)
+	CreateAdmin(username, passwordHash string, mustChangePassword bool) (*domain.User, error)
+	UpdatePassword(userID, passwordHash string, mustChange bool) error
+	SetUserRole(userID, role string) error
+	CountUsersByRole(role string) (int64, error)
+	UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error)
+	RenameUser(userID, username string) error
+	ListUsernameHistory(userID string) (*[]domain.UsernameChange, error)
+	IsUsernameHeld(username, userID string, since time.Time) (bool, error)
+	ListUsers(query *domain.UserQuery) (*[]domain.User, int64, error)
 }
 
 type UserService interface {
-	RegisterUser(username, password string) (*domain.User, error)
-	LoginUser(username, password string) (string, error)
+	RegisterUser(username, email, password string) (*domain.User, error)
+	LoginUser(username, password, ip string) (*domain.TokenPair, error)
+}
+
+type ProfileService interface {
+	GetProfile(userID string) (*domain.User, error)
+	UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error)
+	GetPublicProfile(userID string) (*domain.PublicProfile, error)
+	GetUsers(ids []string) (*[]domain.User, error)
+	ListUsers(query *domain.UserQuery) (*domain.UserPage, error)
+	Rename(userID, username string) (*domain.User, error)
+	UsernameHistory(userID string) (*[]domain.UsernameChange, error)
+}
+
+type AdminService interface {
+	EnsureAdmin() (*domain.User, string, error)
+	CreateAdmin(username, password string) (*domain.User, string, error)
+	RecoverAdmin(username string) (string, error)
 }
@@ -0,0 +1,197 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+)
+
+// Code identifies an error for clients. Codes are part of the API: once
+// released they keep their meaning, whatever the wording of the detail.
+type Code string
+
+const (
+	CodeInternal          Code = "internal_error"
+	CodeInvalidRequest    Code = "invalid_request"
+	CodeRouteNotFound     Code = "route_not_found"
+	CodeMethodNotAllowed  Code = "method_not_allowed"
+	CodeVersionSunset     Code = "version_sunset"
+	CodeNotAcceptable     Code = "not_acceptable"
+	CodeInvalidImport     Code = "invalid_import"
+	CodeQueryTooLarge     Code = "query_too_large"
+	CodeRequestTooLarge   Code = "request_too_large"
+	CodeAuthRequired      Code = "authentication_required"
+	CodeInvalidCredential Code = "invalid_credentials"
+	CodeForbidden         Code = "forbidden"
+	CodeUserTokenRequired Code = "user_token_required"
+
+	CodeCurrentPasswordInvalid Code = "current_password_invalid"
+	CodeResetTokenInvalid      Code = "reset_token_invalid"
+	CodePasswordPolicy         Code = "password_policy"
+	CodePasswordChangeRequired Code = "password_change_required"
+	CodeLoginLocked            Code = "login_locked"
+	CodeAccountBanned          Code = "account_banned"
+	CodeAccountSuspended       Code = "account_suspended"
+
+	CodeOIDCProviderNotFound    Code = "oidc_provider_not_found"
+	CodeOIDCStateInvalid        Code = "oidc_state_invalid"
+	CodeOIDCLoginFailed         Code = "oidc_login_failed"
+	CodeOIDCProviderUnavailable Code = "oidc_provider_unavailable"
+	CodeIdentityLinked          Code = "identity_linked"
+
+	CodeTokenInvalid        Code = "token_invalid"
+	CodeTokenRevoked        Code = "token_revoked"
+	CodeRefreshTokenInvalid Code = "refresh_token_invalid"
+	CodeAPIKeyInvalid       Code = "api_key_invalid"
+
+	CodeScoreNotFound      Code = "score_not_found"
+	CodeGameNotFound       Code = "game_not_found"
+	CodeUserNotFound       Code = "user_not_found"
+	CodeGuestNotFound      Code = "guest_not_found"
+	CodeRoleNotFound       Code = "role_not_found"
+	CodeAPIKeyNotFound     Code = "api_key_not_found"
+	CodeSigningKeyNotFound Code = "signing_key_not_found"
+
+	CodeGameExists     Code = "game_exists"
+	CodeGameSlugExists Code = "game_slug_exists"
+	CodeUsernameExists Code = "username_exists"
+	CodeEmailExists    Code = "email_exists"
+
+	CodeUsernameInvalid  Code = "username_invalid"
+	CodeUsernameReserved Code = "username_reserved"
+	CodeUsernameHeld     Code = "username_held"
+	CodeUsernameCooldown Code = "username_cooldown"
+
+	CodeScoreNotAllowed Code = "score_not_higher"
+
+	CodeNotGuest    Code = "not_guest"
+	CodeMergeTarget Code = "merge_target_invalid"
+
+	CodeLastAdmin         Code = "last_admin"
+	CodeUserNotAdmin      Code = "user_not_admin"
+	CodeUserAlreadyAdmin  Code = "user_already_admin"
+	CodeModerateAdmin     Code = "moderate_admin"
+	CodeInvalidSuspension Code = "suspension_invalid"
+
+	CodeInvalidScope   Code = "api_key_scope_invalid"
+	CodeGameNotAllowed Code = "game_not_allowed"
+	CodeInvalidExpiry  Code = "api_key_expiry_invalid"
+	CodeInvalidSlug    Code = "game_slug_invalid"
+
+	CodeIdempotencyKeyInvalid    Code = "idempotency_key_invalid"
+	CodeIdempotencyKeyReused     Code = "idempotency_key_reused"
+	CodeIdempotencyKeyInProgress Code = "idempotency_key_in_progress"
+)
+
+// entry is how an error is answered.
+type entry struct {
+	err    error
+	status int
+	code   Code
+	title  string
+}
+
+// entries maps the domain errors to their HTTP status and code. errors.Is
+// is tried in order, so typed errors matching a sentinel land on its entry.
+// Errors not listed here are answered as internal errors without details.
+var entries = []entry{
+	{domain.ErrInvalidRequest, http.StatusBadRequest, CodeInvalidRequest, "Invalid request"},
+	{domain.ErrRouteNotFound, http.StatusNotFound, CodeRouteNotFound, "Route not found"},
+	{domain.ErrMethodNotAllowed, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed"},
+	{domain.ErrVersionSunset, http.StatusGone, CodeVersionSunset, "API version sunset"},
+	{domain.ErrNotAcceptable, http.StatusNotAcceptable, CodeNotAcceptable, "Not acceptable"},
+	{domain.ErrInvalidImport, http.StatusBadRequest, CodeInvalidImport, "Invalid import file"},
+	{domain.ErrQueryTooLarge, http.StatusBadRequest, CodeQueryTooLarge, "Query too large"},
+	{domain.ErrRequestTooLarge, http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "Request too large"},
+	{domain.ErrIdempotencyKeyInvalid, http.StatusBadRequest, CodeIdempotencyKeyInvalid, "Invalid idempotency key"},
+	{domain.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency key reused"},
+	{domain.ErrIdempotencyKeyInProgress, http.StatusConflict, CodeIdempotencyKeyInProgress, "Idempotency key in progress"},
+	{domain.ErrAuthRequired, http.StatusUnauthorized, CodeAuthRequired, "Authentication required"},
+	{domain.ErrAuthInvalid, http.StatusUnauthorized, CodeInvalidCredential, "Invalid credentials"},
+	{domain.ErrForbidden, http.StatusForbidden, CodeForbidden, "Forbidden"},
+	{domain.ErrUserTokenRequired, http.StatusForbidden, CodeUserTokenRequired, "User token required"},
+
+	{domain.ErrCurrentPasswordInvalid, http.StatusBadRequest, CodeCurrentPasswordInvalid, "Current password is incorrect"},
+	{domain.ErrResetTokenInvalid, http.StatusBadRequest, CodeResetTokenInvalid, "Invalid reset token"},
+	{domain.ErrPasswordPolicy, http.StatusBadRequest, CodePasswordPolicy, "Password against the policy"},
+	{domain.ErrPasswordChangeRequired, http.StatusForbidden, CodePasswordChangeRequired, "Password change required"},
+	{domain.ErrLoginLocked, http.StatusTooManyRequests, CodeLoginLocked, "Login locked"},
+	// Banned or suspended users get 403 through a RestrictedError; the bare
+	// errors come from moderation actions that don't apply to them.
+	{domain.ErrUserBanned, http.StatusConflict, CodeAccountBanned, "Account banned"},
+	{domain.ErrUserSuspended, http.StatusConflict, CodeAccountSuspended, "Account suspended"},
+
+	{domain.ErrOIDCProviderNotFound, http.StatusNotFound, CodeOIDCProviderNotFound, "Identity provider not found"},
+	{domain.ErrOIDCStateInvalid, http.StatusBadRequest, CodeOIDCStateInvalid, "Invalid login state"},
+	{domain.ErrOIDCLoginFailed, http.StatusUnauthorized, CodeOIDCLoginFailed, "Identity provider login failed"},
+	{domain.ErrOIDCProviderUnavailable, http.StatusBadGateway, CodeOIDCProviderUnavailable, "Identity provider unavailable"},
+	{domain.ErrIdentityLinked, http.StatusConflict, CodeIdentityLinked, "Identity already linked"},
+
+	{domain.ErrTokenInvalid, http.StatusUnauthorized, CodeTokenInvalid, "Invalid access token"},
+	{domain.ErrTokenRevoked, http.StatusUnauthorized, CodeTokenRevoked, "Access token revoked"},
+	{domain.ErrRefreshTokenInvalid, http.StatusUnauthorized, CodeRefreshTokenInvalid, "Invalid refresh token"},
+	{domain.ErrAPIKeyInvalid, http.StatusUnauthorized, CodeAPIKeyInvalid, "Invalid API key"},
+
+	{domain.ErrScoreNotFound, http.StatusNotFound, CodeScoreNotFound, "Score not found"},
+	{domain.ErrGameNotFound, http.StatusNotFound, CodeGameNotFound, "Game not found"},
+	{domain.ErrUserNotFound, http.StatusNotFound, CodeUserNotFound, "User not found"},
+	{domain.ErrGuestNotFound, http.StatusNotFound, CodeGuestNotFound, "Guest not found"},
+	{domain.ErrRoleNotFound, http.StatusNotFound, CodeRoleNotFound, "Role not found"},
+	{domain.ErrAPIKeyNotFound, http.StatusNotFound, CodeAPIKeyNotFound, "API key not found"},
+	{domain.ErrSigningKeyNotFound, http.StatusNotFound, CodeSigningKeyNotFound, "Signing key not found"},
+
+	{domain.ErrGameAlreadyExists, http.StatusConflict, CodeGameExists, "Game already exists"},
+	{domain.ErrGameSlugAlreadyExists, http.StatusConflict, CodeGameSlugExists, "Game slug already exists"},
+	{domain.ErrUsernameAlreadyExists, http.StatusConflict, CodeUsernameExists, "Username already exists"},
+	{domain.ErrEmailAlreadyExists, http.StatusConflict, CodeEmailExists, "Email already exists"},
+
+	{domain.ErrUsernameInvalid, http.StatusBadRequest, CodeUsernameInvalid, "Invalid username"},
+	{domain.ErrUsernameReserved, http.StatusConflict, CodeUsernameReserved, "Username reserved"},
+	{domain.ErrUsernameHeld, http.StatusConflict, CodeUsernameHeld, "Username held"},
+	{domain.ErrUsernameCooldown, http.StatusTooManyRequests, CodeUsernameCooldown, "Username changed too recently"},
+
+	{domain.ErrScoreNotAllowed, http.StatusConflict, CodeScoreNotAllowed, "Score not higher"},
+
+	{domain.ErrNotGuest, http.StatusConflict, CodeNotGuest, "Not a guest"},
+	{domain.ErrMergeTarget, http.StatusConflict, CodeMergeTarget, "Invalid merge target"},
+
+	{domain.ErrLastAdmin, http.StatusConflict, CodeLastAdmin, "Last admin"},
+	{domain.ErrUserNotAdmin, http.StatusConflict, CodeUserNotAdmin, "User is not an admin"},
+	{domain.ErrUserAlreadyAdmin, http.StatusConflict, CodeUserAlreadyAdmin, "User is already an admin"},
+	{domain.ErrModerateAdmin, http.StatusConflict, CodeModerateAdmin, "Admins cannot be moderated"},
+	{domain.ErrInvalidSuspension, http.StatusBadRequest, CodeInvalidSuspension, "Invalid suspension"},
+
+	{domain.ErrInvalidScope, http.StatusBadRequest, CodeInvalidScope, "Invalid API key scope"},
+	{domain.ErrGameNotAllowed, http.StatusForbidden, CodeGameNotAllowed, "Game not allowed"},
+	{domain.ErrInvalidExpiry, http.StatusBadRequest, CodeInvalidExpiry, "Invalid API key expiry"},
+	{domain.ErrInvalidGameSlug, http.StatusBadRequest, CodeInvalidSlug, "Invalid game slug"},
+}
+
+var internal = entry{domain.ErrUnexpected, http.StatusInternalServerError, CodeInternal, "Internal server error"}
+
+// lookup returns the entry of err and whether it is a known error.
+func lookup(err error) (entry, bool) {
+	for _, e := range entries {
+		if errors.Is(err, e.err) {
+			return e, true
+		}
+	}
+	return internal, false
+}
+
+// Status returns the HTTP status err is answered with.
+func Status(err error) int {
+	var restricted *domain.RestrictedError
+	if errors.As(err, &restricted) {
+		return http.StatusForbidden
+	}
+	e, _ := lookup(err)
+	return e.status
+}
+
+// CodeOf returns the code err is answered with.
+func CodeOf(err error) Code {
+	e, _ := lookup(err)
+	return e.code
+}
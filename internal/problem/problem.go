@@ -0,0 +1,224 @@
+// Package problem answers failed requests with RFC 7807 problem details.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// RequestIDKey is the gin context key holding the ID of the request.
+const RequestIDKey = "request_id"
+
+// Problem is the body of every error response.
+type Problem struct {
+	Type      string       `json:"type" example:"urn:problem:user_not_found"`
+	Title     string       `json:"title" example:"User not found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"user not found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/v1/users/0b6f3c1e-9a51-4f4e-8c55-2d5e4c1b7a10"`
+	Code      Code         `json:"code" example:"user_not_found"`
+	RequestID string       `json:"request_id,omitempty" example:"5f0c8f0e-3a0b-4a39-9a43-2d1c9a1f6d21"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Violations lists the rules a refused password breaks.
+	Violations []string `json:"violations,omitempty"`
+	// Reason and Until describe the ban or suspension of the caller.
+	Reason string     `json:"reason,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// FieldError is a request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field" example:"username"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"is required"`
+}
+
+func init() {
+	// Report the JSON names of invalid fields rather than the Go ones.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// New builds the problem err is answered with. Errors that aren't domain
+// errors become internal errors and their message is left out.
+func New(c *gin.Context, err error) *Problem {
+	e, known := lookup(err)
+	p := &Problem{
+		Type:      "urn:problem:" + string(e.code),
+		Title:     e.title,
+		Status:    e.status,
+		Code:      e.code,
+		Instance:  c.Request.URL.Path,
+		RequestID: c.GetString(RequestIDKey),
+	}
+	if known {
+		p.Detail = err.Error()
+	}
+
+	var (
+		restricted *domain.RestrictedError
+		policy     *domain.PasswordPolicyError
+		locked     *domain.LockedError
+		cooldown   *domain.RenameCooldownError
+	)
+	switch {
+	case errors.As(err, &restricted):
+		p.Status = Status(err)
+		p.Reason = restricted.Reason
+		p.Until = restricted.Until
+
+	case errors.As(err, &policy):
+		p.Violations = policy.Violations
+
+	case errors.As(err, &locked):
+		retryAfter(c, locked.RetryAfter)
+
+	case errors.As(err, &cooldown):
+		retryAfter(c, cooldown.RetryAfter)
+	}
+	return p
+}
+
+// Write answers the request with the problem err maps to.
+func Write(c *gin.Context, err error) {
+	write(c, New(c, err))
+}
+
+// Abort is Write for middleware: it also stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	Write(c, err)
+	c.Abort()
+}
+
+// Invalid answers an invalid request listing the offending fields.
+func Invalid(c *gin.Context, fields ...FieldError) {
+	p := New(c, domain.ErrInvalidRequest)
+	p.Errors = fields
+	write(c, p)
+}
+
+// Bind answers a request whose body or query gin could not bind, listing the
+// fields that failed validation when there are any.
+func Bind(c *gin.Context, err error) {
+	p := New(c, domain.ErrInvalidRequest)
+
+	var (
+		validation validator.ValidationErrors
+		typeErr    *json.UnmarshalTypeError
+		syntaxErr  *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &validation):
+		for _, fe := range validation {
+			p.Errors = append(p.Errors, FieldError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: message(fe),
+			})
+		}
+
+	case errors.As(err, &typeErr):
+		p.Errors = []FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be " + kindName(typeErr.Type),
+		}}
+
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		p.Detail = "request body is not valid JSON"
+
+	case errors.Is(err, io.EOF):
+		p.Detail = "request body is empty"
+	}
+	write(c, p)
+}
+
+func write(c *gin.Context, p *Problem) {
+	c.Header("Content-Type", ContentType)
+	// Errors are never cached, whatever the route allows for its responses.
+	c.Header("Cache-Control", "no-store")
+	c.JSON(p.Status, p)
+}
+
+func retryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// fieldPath drops the name of the request struct from the namespace of fe,
+// e.g. "RegisterRequest.username" becomes "username".
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be an email address"
+	case "http_url":
+		return "must be an http or https URL"
+	case "uuid4":
+		return "must be a UUID"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	case "min", "gte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + fe.Param()
+	}
+	return "fails the " + fe.Tag() + " check"
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
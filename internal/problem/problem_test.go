@@ -0,0 +1,163 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/middleware"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, handler gin.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, problem.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.Any("/test", handler)
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &p))
+	return resp, p
+}
+
+func TestWrite_DomainError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+
+	resp, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, fmt.Errorf("loading profile: %w", domain.ErrUserNotFound))
+	}, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "req-123", resp.Header().Get(middleware.RequestIDHeader))
+	assert.Equal(t, problem.CodeUserNotFound, p.Code)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "urn:problem:user_not_found", p.Type)
+	assert.Equal(t, "/test", p.Instance)
+	assert.Equal(t, "req-123", p.RequestID)
+	assert.Contains(t, p.Detail, "user not found")
+}
+
+func TestWrite_UnknownErrorIsHidden(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	resp, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, errors.New("pq: connection refused"))
+	}, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, problem.CodeInternal, p.Code)
+	assert.Empty(t, p.Detail)
+	assert.NotEmpty(t, p.RequestID)
+}
+
+func TestWrite_Restricted(t *testing.T) {
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	resp, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, &domain.RestrictedError{Err: domain.ErrUserSuspended, Reason: "spam", Until: &until})
+	}, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, problem.CodeAccountSuspended, p.Code)
+	assert.Equal(t, "spam", p.Reason)
+	require.NotNil(t, p.Until)
+	assert.True(t, until.Equal(*p.Until))
+}
+
+func TestWrite_RetryAfter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	resp, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, &domain.LockedError{RetryAfter: 1500 * time.Millisecond})
+	}, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, problem.CodeLoginLocked, p.Code)
+	assert.Equal(t, "2", resp.Header().Get("Retry-After"))
+}
+
+func TestWrite_PasswordPolicy(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+
+	_, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, &domain.PasswordPolicyError{Violations: []string{"must be at least 8 characters long"}})
+	}, req)
+
+	assert.Equal(t, problem.CodePasswordPolicy, p.Code)
+	assert.Equal(t, []string{"must be at least 8 characters long"}, p.Violations)
+}
+
+type bindRequest struct {
+	Username string `json:"username" binding:"required"`
+	DeviceID string `json:"device_id" binding:"required,min=16"`
+	Points   int    `json:"points"`
+}
+
+func bindHandler(c *gin.Context) {
+	var req bindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Bind(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func TestBind_FieldErrors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"device_id":"short"}`))
+
+	resp, p := serve(t, bindHandler, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+	assert.ElementsMatch(t, []problem.FieldError{
+		{Field: "username", Code: "required", Message: "is required"},
+		{Field: "device_id", Code: "min", Message: "must be at least 16 characters long"},
+	}, p.Errors)
+}
+
+func TestBind_TypeError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"points":"many"}`))
+
+	_, p := serve(t, bindHandler, req)
+
+	assert.Equal(t, []problem.FieldError{{Field: "points", Code: "type", Message: "must be an integer"}}, p.Errors)
+}
+
+func TestBind_MalformedBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"username":`))
+
+	_, p := serve(t, bindHandler, req)
+
+	assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+	assert.Equal(t, "request body is not valid JSON", p.Detail)
+	assert.Empty(t, p.Errors)
+}
+
+func TestRequestID_ReplacesInvalidIDs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(middleware.RequestIDHeader, "bad id\n")
+
+	resp, p := serve(t, func(c *gin.Context) {
+		problem.Write(c, domain.ErrForbidden)
+	}, req)
+
+	assert.NotEqual(t, "bad id\n", p.RequestID)
+	assert.Equal(t, resp.Header().Get(middleware.RequestIDHeader), p.RequestID)
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+)
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) ports.AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) ExportUserData(userID string) (*domain.UserExport, error) {
+	var user User
+	if err := r.db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	export := &domain.UserExport{
+		ExportedAt: time.Now().UTC(),
+		User:       *toDomainUser(&user),
+		Scores:     []domain.Score{},
+		Sessions:   []domain.Session{},
+		APIKeys:    []domain.APIKey{},
+		Identities: []domain.ExternalIdentity{},
+		Usernames:  []domain.UsernameChange{},
+	}
+
+	var scores []dto.UserScoreDTO
+	err := r.db.
+		Table("scores").
+		Select("users.username, scores.user_id, games.name as game_name, games.slug as game_slug, scores.game_id, scores.points").
+		Joins("JOIN users ON users.id = scores.user_id").
+		Joins("JOIN games ON games.id = scores.game_id").
+		Where("scores.user_id = ?", userID).
+		Order("games.name").
+		Scan(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, score := range scores {
+		export.Scores = append(export.Scores, domain.Score{
+			Username: score.Username,
+			UserID:   score.UserID,
+			GameName: score.GameName,
+			GameSlug: score.GameSlug,
+			GameID:   score.GameID,
+			Points:   score.Points,
+		})
+	}
+
+	var tokens []RefreshToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		export.Sessions = append(export.Sessions, domain.Session{
+			ID:        token.ID,
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+			RevokedAt: token.RevokedAt,
+		})
+	}
+
+	var keys []APIKey
+	if err := r.db.Where("created_by = ?", userID).Order("created_at").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		export.APIKeys = append(export.APIKeys, toDomainAPIKey(key))
+	}
+
+	var identities []UserIdentity
+	if err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, domain.ExternalIdentity{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			UserID:    identity.UserID,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	var changes []UsernameChange
+	if err := r.db.Where("user_id = ?", userID).Order("changed_at").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		export.Usernames = append(export.Usernames, toDomainUsernameChange(change))
+	}
+
+	return export, nil
+}
+
+// DeleteUser removes the user with their scores, sessions, reset tokens and
+// linked identities.
+// API keys the user created stay listed for auditing but are revoked.
+func (r *accountRepository) DeleteUser(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := removeUserData(tx, userID); err != nil {
+			return err
+		}
+		if err := touchUserGames(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&Score{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.Delete(&User{}, "id = ?", userID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// AnonymizeUser replaces the username with placeholder and clears every
+// personal field. The password hash is emptied, which no password matches,
+// so nobody can log into the account again. Scores are kept.
+func (r *accountRepository) AnonymizeUser(userID, placeholder string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := removeUserData(tx, userID); err != nil {
+			return err
+		}
+
+		res := tx.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":             placeholder,
+			"email":                nil,
+			"password_hash":        "",
+			"role":                 domain.RolePlayer,
+			"display_name":         "",
+			"avatar_url":           "",
+			"country":              "",
+			"bio":                  "",
+			"must_change_password": false,
+			"device_hash":          nil,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		return touchUserGames(tx, userID)
+	})
+}
+
+func removeUserData(tx *gorm.DB, userID string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&PasswordResetToken{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&UserIdentity{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&UsernameChange{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&APIKey{}).
+		Where("created_by = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
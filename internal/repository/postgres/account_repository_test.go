@@ -0,0 +1,48 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountRepository_AnonymizeKeepsScores(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	users := repository.NewUserRepository(db)
+	games := repository.NewGameRepository(db)
+	scores := repository.NewScoreRepository(db)
+	accounts := repository.NewAccountRepository(db)
+
+	user, err := users.CreateUserWithInitialScores(context.Background(), "martin", "martin@example.com", "pass123")
+	assert.NoError(t, err)
+	game, err := games.CreateGameWithInitialScores(context.Background(), "Space Racer", "space-racer")
+	assert.NoError(t, err)
+	assert.NoError(t, scores.SubmitScore(&domain.Score{UserID: user.ID, GameID: game.ID, Points: 50}))
+
+	export, err := accounts.ExportUserData(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "martin@example.com", export.User.Email)
+	assert.Len(t, export.Scores, 1)
+
+	assert.NoError(t, accounts.AnonymizeUser(user.ID, "deleted-"+user.ID))
+
+	anonymized, err := users.GetUserByID(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted-"+user.ID, anonymized.Username)
+	assert.Empty(t, anonymized.Email)
+
+	leaderboard, err := scores.GetScoresByGameID(game.ID)
+	assert.NoError(t, err)
+	assert.Len(t, *leaderboard, 1)
+
+	assert.NoError(t, accounts.DeleteUser(user.ID))
+	_, err = users.GetUserByID(user.ID)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
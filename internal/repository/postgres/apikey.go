@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) ports.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(key *domain.APIKey, keyHash string) error {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, s := range key.Scopes {
+		scopes = append(scopes, string(s))
+	}
+	gameIDs := key.GameIDs
+	if gameIDs == nil {
+		gameIDs = []string{}
+	}
+
+	model := &APIKey{
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		GameIDs:   gameIDs,
+		CreatedBy: key.CreatedBy,
+		ExpiresAt: key.ExpiresAt,
+	}
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	key.ID = model.ID
+	key.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(keyHash string) (*domain.APIKey, error) {
+	var key APIKey
+	err := r.db.First(&key, "key_hash = ?", keyHash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	domainKey := toDomainAPIKey(key)
+	return &domainKey, nil
+}
+
+func (r *apiKeyRepository) ListAPIKeys() (*[]domain.APIKey, error) {
+	var keys []APIKey
+	err := r.db.Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var keysResponse []domain.APIKey
+	for _, key := range keys {
+		keysResponse = append(keysResponse, toDomainAPIKey(key))
+	}
+	return &keysResponse, nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(id string) error {
+	res := r.db.Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	return r.db.Model(&APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+func toDomainAPIKey(key APIKey) domain.APIKey {
+	scopes := make([]domain.Permission, 0, len(key.Scopes))
+	for _, s := range key.Scopes {
+		scopes = append(scopes, domain.Permission(s))
+	}
+
+	return domain.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		GameIDs:    key.GameIDs,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	ID         string   `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Name       string   `gorm:"not null"`
+	Prefix     string   `gorm:"not null"`
+	KeyHash    string   `gorm:"uniqueIndex;not null"`
+	Scopes     []string `gorm:"serializer:json;type:jsonb;not null"`
+	GameIDs    []string `gorm:"serializer:json;type:jsonb;not null"`
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
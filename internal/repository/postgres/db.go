@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -20,7 +23,7 @@ func Connect() (*gorm.DB, error) {
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(openDialector(dsn), &gorm.Config{
 		TranslateError: true,
 	})
 
@@ -47,10 +50,23 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to create extension: %w", err)
 	}
 
-	if err := db.AutoMigrate(&User{}, &Score{}, &Game{}); err != nil {
+	// Roles go first: users reference them by name.
+	if err := db.AutoMigrate(&Role{}, &RolePermission{}); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if err := seedRoles(db); err != nil {
+		return fmt.Errorf("failed to seed roles: %w", err)
+	}
+
+	if err := db.AutoMigrate(&User{}, &Score{}, &Game{}, &RefreshToken{}, &RevokedToken{}, &APIKey{}, &SigningKey{}, &PasswordResetToken{}, &LoginAttempt{}, &UserIdentity{}, &OIDCLoginState{}, &ModerationAction{}, &UsernameChange{}, &IdempotencyKey{}); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	if err := migrateAdminFlag(db); err != nil {
+		return fmt.Errorf("failed to migrate admin flag to roles: %w", err)
+	}
+
 	if err := db.Exec(`ALTER TABLE users ALTER COLUMN id SET DEFAULT uuid_generate_v4()`).Error; err != nil {
 		return err
 	}
@@ -59,25 +75,138 @@ func RunMigrations(db *gorm.DB) error {
 		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+	if err := backfillGameSlugs(db); err != nil {
+		return fmt.Errorf("failed to backfill game slugs: %w", err)
+	}
+
+	if err := db.Exec(`ALTER TABLE games ALTER COLUMN slug SET NOT NULL`).Error; err != nil {
+		return err
+	}
+
+	if err := dedupeUsernames(db); err != nil {
+		return fmt.Errorf("failed to dedupe usernames: %w", err)
+	}
+
+	if err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (lower(username))`).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_username_changes_username_lower ON username_changes (lower(username))`).Error; err != nil {
+		return err
+	}
+
+	if err := lockDefaultAdminPassword(db); err != nil {
+		return fmt.Errorf("failed to check default admin password: %w", err)
+	}
+	return nil
+}
+
+// migrateAdminFlag turns the old users.is_admin column into the admin role.
+func migrateAdminFlag(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&User{}, "is_admin") {
+		return nil
+	}
+
+	if err := db.Model(&User{}).Where("is_admin = TRUE").Update("role", domain.RoleAdmin).Error; err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&User{}, "is_admin")
+}
+
+// lockedPasswordHash matches no password of any hasher, nor the empty hash
+// of accounts without a password.
+const lockedPasswordHash = "!locked"
+
+// lockDefaultAdminPassword locks the admin account older versions created
+// with the well-known password "admin123": forcing a change is not enough,
+// since whoever logs in first picks the new password. The account is only
+// usable again after `admin recover`.
+func lockDefaultAdminPassword(db *gorm.DB) error {
+	var admin User
+	if err := db.First(&admin, "username = ? AND role = ?", "admin", domain.RoleAdmin).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte("admin123")) != nil {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&admin).Updates(map[string]any{
+			"password_hash":        lockedPasswordHash,
+			"must_change_password": true,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", admin.ID).
+			Update("revoked_at", time.Now()).Error
+	})
 	if err != nil {
-		return fmt.Errorf("error creating admin user: %w", err)
-	}
-	// Create the admin user if it doesn't exist
-	var adminUser User
-	if err := db.First(&adminUser, "username = ?", "admin").Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			adminUser = User{
-				Username:     "admin",
-				PasswordHash: string(hash), // hashed password for "admin123"
-				IsAdmin:      true,
+		return err
+	}
+	log.Warn().Str("user_id", admin.ID).Msg("admin account with the default password locked, run `admin recover -username admin` to regain access")
+	return nil
+}
+
+// dedupeUsernames renames users whose username only differs in case from an
+// older user's, which was allowed before usernames became case-insensitive.
+// The newer accounts get the first characters of their ID appended.
+func dedupeUsernames(db *gorm.DB) error {
+	var users []User
+	err := db.Where(`lower(username) IN (
+		SELECT lower(username) FROM users GROUP BY lower(username) HAVING count(*) > 1
+	)`).Order("lower(username), created_at, id").Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, user := range users {
+		key := domain.UsernameKey(user.Username)
+		if !seen[key] {
+			seen[key] = true
+			continue
+		}
+		renamed := user.Username + "-" + user.ID[:8]
+		if err := db.Model(&User{}).Where("id = ?", user.ID).Update("username", renamed).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillGameSlugs derives a slug for games created before slugs existed.
+func backfillGameSlugs(db *gorm.DB) error {
+	var games []Game
+	if err := db.Where("slug IS NULL OR slug = ''").Find(&games).Error; err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		base := utils.Slugify(game.Name)
+		if base == "" {
+			base = "game"
+		}
+
+		slug := base
+		for i := 2; ; i++ {
+			var count int64
+			if err := db.Model(&Game{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+				return err
 			}
-			if err := db.Create(&adminUser).Error; err != nil {
-				return fmt.Errorf("error creating admin user: %w", err)
+			if count == 0 {
+				break
 			}
-		} else {
-			return fmt.Errorf("error creating admin user: %w", err)
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		if err := db.Model(&Game{}).Where("id = ?", game.ID).Update("slug", slug).Error; err != nil {
+			return err
 		}
 	}
+
 	return nil
 }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the Postgres code of a duplicate key.
+const uniqueViolation = "23505"
+
+// dialector is the Postgres dialector, except that duplicate keys keep the
+// name of the constraint they broke. Tables with several unique columns need
+// it to tell which one a racing insert collided on.
+type dialector struct {
+	*postgres.Dialector
+}
+
+func openDialector(dsn string) gorm.Dialector {
+	return &dialector{Dialector: postgres.Open(dsn).(*postgres.Dialector)}
+}
+
+func (d *dialector) Translate(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return &duplicateKeyError{constraint: pgErr.ConstraintName}
+	}
+	return d.Dialector.Translate(err)
+}
+
+// duplicateKeyError is gorm.ErrDuplicatedKey with the constraint it broke.
+type duplicateKeyError struct {
+	constraint string
+}
+
+func (e *duplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: %s", gorm.ErrDuplicatedKey, e.constraint)
+}
+
+func (e *duplicateKeyError) Is(target error) bool {
+	return target == gorm.ErrDuplicatedKey
+}
+
+// duplicateConstraint returns the constraint a duplicate key broke, or ""
+// when err is not a duplicate key.
+func duplicateConstraint(err error) string {
+	var dup *duplicateKeyError
+	if errors.As(err, &dup) {
+		return dup.constraint
+	}
+	return ""
+}
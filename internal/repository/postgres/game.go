@@ -26,10 +26,7 @@ func (r *gameRepository) ListGames() (*[]domain.Game, error) {
 
 	var gamesResponse []domain.Game
 	for _, game := range games {
-		gamesResponse = append(gamesResponse, domain.Game{
-			ID:   game.ID,
-			Name: game.Name,
-		})
+		gamesResponse = append(gamesResponse, *toDomainGame(&game))
 	}
 	return &gamesResponse, nil
 }
@@ -43,10 +40,22 @@ func (r *gameRepository) GetGameByID(id string) (*domain.Game, error) {
 		}
 		return nil, err
 	}
-	return &domain.Game{
-		ID:   game.ID,
-		Name: game.Name,
-	}, nil
+	return toDomainGame(&game), nil
+}
+
+// GetGamesByIDs returns the games among ids that exist, in no particular
+// order.
+func (r *gameRepository) GetGamesByIDs(ids []string) (*[]domain.Game, error) {
+	var games []Game
+	if err := r.db.Where("id IN ?", ids).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Game, 0, len(games))
+	for i := range games {
+		result = append(result, *toDomainGame(&games[i]))
+	}
+	return &result, nil
 }
 
 func (r *gameRepository) GetGameByName(name string) (*domain.Game, error) {
@@ -58,25 +67,40 @@ func (r *gameRepository) GetGameByName(name string) (*domain.Game, error) {
 		}
 		return nil, err
 	}
-	return &domain.Game{
-		ID:   game.ID,
-		Name: game.Name,
-	}, nil
+	return toDomainGame(&game), nil
 }
 
-func (r *gameRepository) CreateGameWithInitialScores(ctx context.Context, name string) (*domain.Game, error) {
-	newGame := &Game{Name: name}
+func (r *gameRepository) GetGameBySlug(slug string) (*domain.Game, error) {
+	var game Game
+	err := r.db.Where("slug = ?", slug).First(&game).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrGameNotFound
+		}
+		return nil, err
+	}
+
+	return toDomainGame(&game), nil
+}
+
+func (r *gameRepository) CreateGameWithInitialScores(ctx context.Context, name, slug string) (*domain.Game, error) {
+	newGame := &Game{Name: name, Slug: slug}
 
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(newGame).Error; err != nil {
-			if errors.Is(err, gorm.ErrDuplicatedKey) {
+			// A concurrent create can take the slug after the service
+			// checked it, so the index tells which field is taken.
+			switch duplicateConstraint(err) {
+			case gameSlugIndex:
+				return domain.ErrGameSlugAlreadyExists
+			case gameNameIndex:
 				return domain.ErrGameAlreadyExists
 			}
 			return err
 		}
 
 		var users []User
-		if err := tx.Where("is_admin = FALSE").Find(&users).Error; err != nil {
+		if err := tx.Where("role <> ?", domain.RoleAdmin).Find(&users).Error; err != nil {
 			return err
 		}
 
@@ -102,8 +126,47 @@ func (r *gameRepository) CreateGameWithInitialScores(ctx context.Context, name s
 		return nil, err
 	}
 
+	return toDomainGame(newGame), nil
+}
+
+// touchGame records a change to the leaderboard of the game.
+func touchGame(tx *gorm.DB, gameID string) error {
+	return tx.Model(&Game{}).Where("id = ?", gameID).Updates(gameTouch()).Error
+}
+
+// touchGames records a change to the leaderboards of the games.
+func touchGames(tx *gorm.DB, gameIDs []string) error {
+	if len(gameIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&Game{}).Where("id IN ?", gameIDs).Updates(gameTouch()).Error
+}
+
+// touchUserGames records a change to the leaderboards the users appear in, as
+// when a user is renamed, banned or deleted. It must run before the scores
+// of the users are deleted.
+func touchUserGames(tx *gorm.DB, userIDs ...string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&Game{}).
+		Where("id IN (?)", tx.Model(&Score{}).Select("game_id").Where("user_id IN ?", userIDs)).
+		Updates(gameTouch()).Error
+}
+
+func gameTouch() map[string]interface{} {
+	return map[string]interface{}{
+		"version":           gorm.Expr("version + 1"),
+		"scores_updated_at": gorm.Expr("now()"),
+	}
+}
+
+func toDomainGame(game *Game) *domain.Game {
 	return &domain.Game{
-		ID:   newGame.ID,
-		Name: newGame.Name,
-	}, nil
+		ID:              game.ID,
+		Name:            game.Name,
+		Slug:            game.Slug,
+		Version:         game.Version,
+		ScoresUpdatedAt: game.ScoresUpdatedAt,
+	}
 }
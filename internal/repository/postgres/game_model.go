@@ -1,8 +1,22 @@
 package repository
 
+import "time"
+
+// The unique indexes of games, named in the tags below.
+const (
+	gameNameIndex = "idx_games_name"
+	gameSlugIndex = "idx_games_slug"
+)
+
 type Game struct {
 	ID   string `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	Name string `gorm:"uniqueIndex;not null"`
+	Name string `gorm:"uniqueIndex:idx_games_name;not null"`
+	Slug string `gorm:"uniqueIndex:idx_games_slug"`
+
+	// Bumped by touchGame and touchUserGames in every transaction that
+	// changes the leaderboard, so reads can be validated without running it.
+	Version         int64     `gorm:"not null;default:1"`
+	ScoresUpdatedAt time.Time `gorm:"not null;default:now()"`
 
 	//FK
 	Scores []Score `gorm:"foreignKey:GameID;constraint:OnDelete:CASCADE"`
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,7 +17,7 @@ func TestGameRepository_CreateAndFetch(t *testing.T) {
 	db := repository.SetupTestDB(t)
 	repo := repository.NewGameRepository(db)
 
-	game, err := repo.CreateGameWithInitialScores(context.Background(), "game-1")
+	game, err := repo.CreateGameWithInitialScores(context.Background(), "game-1", "game-1")
 	assert.NoError(t, err)
 	assert.NotNil(t, game)
 
@@ -28,7 +29,29 @@ func TestGameRepository_CreateAndFetch(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, game.Name, byName.Name)
 
+	bySlug, err := repo.GetGameBySlug(game.Slug)
+	assert.NoError(t, err)
+	assert.Equal(t, game.ID, bySlug.ID)
+
 	allGames, err := repo.ListGames()
 	assert.NoError(t, err)
 	assert.Len(t, *allGames, 1)
 }
+
+func TestGameRepository_CreateConflicts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewGameRepository(db)
+
+	_, err := repo.CreateGameWithInitialScores(context.Background(), "Space Racer", "racer")
+	assert.NoError(t, err)
+
+	_, err = repo.CreateGameWithInitialScores(context.Background(), "Racer", "racer")
+	assert.ErrorIs(t, err, domain.ErrGameSlugAlreadyExists)
+
+	_, err = repo.CreateGameWithInitialScores(context.Background(), "Space Racer", "space-racer")
+	assert.ErrorIs(t, err, domain.ErrGameAlreadyExists)
+}
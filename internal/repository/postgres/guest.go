@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type guestRepository struct {
+	db *gorm.DB
+}
+
+func NewGuestRepository(db *gorm.DB) ports.GuestRepository {
+	return &guestRepository{db: db}
+}
+
+func (r *guestRepository) GetGuestByDevice(deviceHash string) (*domain.User, error) {
+	var user User
+	err := r.db.First(&user, "device_hash = ? AND is_guest", deviceHash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrGuestNotFound
+		}
+		return nil, err
+	}
+	return toDomainUser(&user), nil
+}
+
+// GetOrCreateGuest returns the guest of the device, creating it with
+// username and a zero score for every game on its first visit. When two
+// requests of the same device race, the loser returns the winner's guest.
+func (r *guestRepository) GetOrCreateGuest(ctx context.Context, deviceHash, username string) (*domain.User, error) {
+	guest, err := r.GetGuestByDevice(deviceHash)
+	if !errors.Is(err, domain.ErrGuestNotFound) {
+		return guest, err
+	}
+
+	newUser := &User{
+		Username:   username,
+		Role:       domain.RolePlayer,
+		IsGuest:    true,
+		DeviceHash: &deviceHash,
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createUserWithScores(tx, newUser)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if guest, err := r.GetGuestByDevice(deviceHash); err == nil {
+				return guest, nil
+			}
+			return nil, domain.ErrUsernameAlreadyExists
+		}
+		return nil, err
+	}
+
+	return toDomainUser(newUser), nil
+}
+
+// UpgradeGuest turns a guest into a registered account with the given
+// credentials. The device identifier no longer signs the account in.
+func (r *guestRepository) UpgradeGuest(userID, username, email, passwordHash string) (*domain.User, error) {
+	var user User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return err
+		}
+		if !user.IsGuest {
+			return domain.ErrNotGuest
+		}
+
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"username":      username,
+			"email":         nullableEmail(email),
+			"password_hash": passwordHash,
+			"is_guest":      false,
+			"device_hash":   nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return touchUserGames(tx, userID)
+	})
+	if err != nil {
+		return nil, duplicateUserError(r.db, err, nullableEmail(email))
+	}
+
+	if err := r.db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return toDomainUser(&user), nil
+}
+
+// MergeGuest moves the scores of the guest to the user in one transaction and
+// deletes the guest. Where both played a game, the guest's score only replaces
+// the user's if domain.BetterScore says it beats it. The scores of the user are
+// locked meanwhile, so that a score submitted during the merge isn't lost.
+func (r *guestRepository) MergeGuest(guestID, userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var guest User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&guest, "id = ? AND is_guest", guestID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrGuestNotFound
+			}
+			return err
+		}
+
+		var guestScores, userScores []Score
+		if err := tx.Where("user_id = ?", guestID).Find(&guestScores).Error; err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Find(&userScores).Error
+		if err != nil {
+			return err
+		}
+
+		best := make(map[string]int, len(userScores))
+		for _, score := range userScores {
+			best[score.GameID] = score.Points
+		}
+		for _, score := range guestScores {
+			if points, played := best[score.GameID]; played && !domain.BetterScore(score.Points, points) {
+				continue
+			}
+			if err := tx.Save(&Score{UserID: userID, GameID: score.GameID, Points: score.Points}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := touchUserGames(tx, guestID); err != nil {
+			return err
+		}
+		if err := removeUserData(tx, guestID); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", guestID).Delete(&Score{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&User{}, "id = ?", guestID).Error
+	})
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestRepository_GetOrCreate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	guestRepo := repository.NewGuestRepository(db)
+
+	guest, err := guestRepo.GetOrCreateGuest(context.Background(), "device-hash", "guest-1")
+	require.NoError(t, err)
+	assert.True(t, guest.IsGuest)
+
+	again, err := guestRepo.GetOrCreateGuest(context.Background(), "device-hash", "guest-2")
+	require.NoError(t, err)
+	assert.Equal(t, guest.ID, again.ID)
+	assert.Equal(t, "guest-1", again.Username)
+}
+
+func TestGuestRepository_Upgrade(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	guestRepo := repository.NewGuestRepository(db)
+
+	guest, err := guestRepo.GetOrCreateGuest(context.Background(), "device-hash", "guest-1")
+	require.NoError(t, err)
+
+	user, err := guestRepo.UpgradeGuest(guest.ID, "martin", "Martin@Example.com", "hash")
+	require.NoError(t, err)
+	assert.Equal(t, guest.ID, user.ID)
+	assert.Equal(t, "martin", user.Username)
+	assert.Equal(t, "martin@example.com", user.Email)
+	assert.False(t, user.IsGuest)
+
+	_, err = guestRepo.GetGuestByDevice("device-hash")
+	assert.ErrorIs(t, err, domain.ErrGuestNotFound)
+
+	_, err = guestRepo.UpgradeGuest(guest.ID, "martin2", "", "hash")
+	assert.ErrorIs(t, err, domain.ErrNotGuest)
+}
+
+func TestGuestRepository_MergeKeepsBestScores(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	gameRepo := repository.NewGameRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+	guestRepo := repository.NewGuestRepository(db)
+
+	pong, err := gameRepo.CreateGameWithInitialScores(context.Background(), "pong", "pong")
+	require.NoError(t, err)
+	tetris, err := gameRepo.CreateGameWithInitialScores(context.Background(), "tetris", "tetris")
+	require.NoError(t, err)
+
+	user, err := userRepo.CreateUserWithInitialScores(context.Background(), "martin", "", "hash")
+	require.NoError(t, err)
+	guest, err := guestRepo.GetOrCreateGuest(context.Background(), "device-hash", "guest-1")
+	require.NoError(t, err)
+
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{UserID: user.ID, GameID: pong.ID, Points: 500}))
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{UserID: user.ID, GameID: tetris.ID, Points: 100}))
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{UserID: guest.ID, GameID: pong.ID, Points: 200}))
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{UserID: guest.ID, GameID: tetris.ID, Points: 800}))
+
+	require.NoError(t, guestRepo.MergeGuest(guest.ID, user.ID))
+
+	pongScore, err := scoreRepo.GetScore(user.ID, pong.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 500, pongScore.Points)
+	tetrisScore, err := scoreRepo.GetScore(user.ID, tetris.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 800, tetrisScore.Points)
+
+	_, err = userRepo.GetUserByID(guest.ID)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	assert.ErrorIs(t, guestRepo.MergeGuest(guest.ID, user.ID), domain.ErrGuestNotFound)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimAttempts bounds the retries when the record that held a key is
+// purged between the claim and its lookup.
+const claimAttempts = 3
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) ports.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// ClaimIdempotencyKey inserts the record in a single statement, taking over
+// the key only when the record holding it expired or its request was given
+// up for lost, so of two concurrent requests only one runs.
+func (r *idempotencyRepository) ClaimIdempotencyKey(record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	model := IdempotencyKey{
+		Scope:       record.Scope,
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		LockedUntil: record.LockedUntil,
+		ExpiresAt:   record.ExpiresAt,
+		CreatedAt:   now,
+	}
+
+	for range claimAttempts {
+		result := r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scope"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"request_hash", "status", "header", "body", "locked_until", "expires_at", "created_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{gorm.Expr(
+				"idempotency_keys.expires_at <= ? OR (idempotency_keys.status = 0 AND idempotency_keys.locked_until <= ?)", now, now,
+			)}},
+		}).Create(&model)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		var existing IdempotencyKey
+		err := r.db.Where("scope = ? AND key = ?", record.Scope, record.Key).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		held := toDomainIdempotencyRecord(existing)
+		return &held, nil
+	}
+	return nil, errors.New("idempotency key could not be claimed")
+}
+
+// CompleteIdempotencyKey stores the response, unless a retry took the key
+// over in the meantime.
+func (r *idempotencyRepository) CompleteIdempotencyKey(record *domain.IdempotencyRecord) error {
+	return r.db.Model(&IdempotencyKey{}).
+		Where("scope = ? AND key = ? AND request_hash = ? AND status = 0", record.Scope, record.Key, record.RequestHash).
+		Updates(IdempotencyKey{Status: record.Status, Header: record.Header, Body: record.Body}).Error
+}
+
+func (r *idempotencyRepository) ReleaseIdempotencyKey(scope, key string) error {
+	return r.db.Where("scope = ? AND key = ? AND status = 0", scope, key).Delete(&IdempotencyKey{}).Error
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyKeys(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&IdempotencyKey{}).Error
+}
+
+func toDomainIdempotencyRecord(m IdempotencyKey) domain.IdempotencyRecord {
+	return domain.IdempotencyRecord{
+		Scope:       m.Scope,
+		Key:         m.Key,
+		RequestHash: m.RequestHash,
+		Status:      m.Status,
+		Header:      m.Header,
+		Body:        m.Body,
+		LockedUntil: m.LockedUntil,
+		ExpiresAt:   m.ExpiresAt,
+	}
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+)
+
+// IdempotencyKey stores the first response to a write sent with an
+// Idempotency-Key.
+type IdempotencyKey struct {
+	Scope       string              `gorm:"primaryKey"`
+	Key         string              `gorm:"primaryKey"`
+	RequestHash string              `gorm:"not null"`
+	Status      int                 `gorm:"not null;default:0"`
+	Header      map[string][]string `gorm:"serializer:json"`
+	Body        []byte
+	LockedUntil time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository_Claim(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewIdempotencyRepository(db)
+
+	now := time.Now()
+	record := &domain.IdempotencyRecord{
+		Scope:       "user:1 PUT /api/v1/scores",
+		Key:         "k1",
+		RequestHash: "h1",
+		LockedUntil: now.Add(time.Minute),
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	held, err := repo.ClaimIdempotencyKey(record, now)
+	require.NoError(t, err)
+	assert.Nil(t, held, "a new key is claimed")
+
+	held, err = repo.ClaimIdempotencyKey(record, now)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.False(t, held.Completed())
+
+	record.Status = 201
+	record.Header = map[string][]string{"Content-Type": {"application/json"}}
+	record.Body = []byte(`{"id":"1"}`)
+	require.NoError(t, repo.CompleteIdempotencyKey(record))
+
+	held, err = repo.ClaimIdempotencyKey(record, now)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, 201, held.Status)
+	assert.Equal(t, record.Header, held.Header)
+	assert.Equal(t, record.Body, held.Body)
+
+	later := now.Add(2 * time.Hour)
+	held, err = repo.ClaimIdempotencyKey(record, later)
+	require.NoError(t, err)
+	assert.Nil(t, held, "an expired key is claimed again")
+}
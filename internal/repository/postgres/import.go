@@ -0,0 +1,457 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ports.ImportRepository {
+	return &importRepository{db: db}
+}
+
+// upserted is a row returned by an INSERT ... ON CONFLICT ... RETURNING of the
+// import. Rows the conflict clause skipped aren't returned.
+type upserted struct {
+	ID       string
+	Inserted bool
+	// Username is only returned for users.
+	Username string
+}
+
+// ImportUsers creates the users of the batch as players. Existing users,
+// matched by username regardless of case, only change with
+// domain.ConflictOverwrite, which only touches players: the accounts of
+// admins, moderators and other staff can't be taken over by an import.
+// Every session of a user whose password is replaced is ended.
+func (r *importRepository) ImportUsers(ctx context.Context, rows []domain.ImportUser, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	result := &domain.ImportBatchResult{}
+	err := r.batch(ctx, dryRun, func(tx *gorm.DB) error {
+		rows := mergeRows(rows, result,
+			func(row domain.ImportUser) string { return domain.UsernameKey(row.Username) },
+			func(kept, row domain.ImportUser) bool { return conflict == domain.ConflictOverwrite })
+
+		rows, err := rejectTakenEmails(tx, rows, result)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		values := make([]string, 0, len(rows))
+		args := make([]interface{}, 0, 4*len(rows))
+		for _, row := range rows {
+			values = append(values, "(?, ?, ?, ?, now(), now())")
+			args = append(args, row.Username, nullableEmail(row.Email), row.PasswordHash, domain.RolePlayer)
+		}
+
+		onConflict := "DO NOTHING"
+		if conflict == domain.ConflictOverwrite {
+			onConflict = `DO UPDATE SET
+				email = COALESCE(EXCLUDED.email, users.email),
+				password_hash = CASE WHEN EXCLUDED.password_hash <> '' THEN EXCLUDED.password_hash ELSE users.password_hash END,
+				updated_at = now()
+			WHERE users.role = 'player' AND NOT users.is_guest`
+		}
+
+		var returned []upserted
+		err = tx.Raw(`INSERT INTO users (username, email, password_hash, role, created_at, updated_at)
+			VALUES `+strings.Join(values, ", ")+`
+			ON CONFLICT ((lower(username))) `+onConflict+`
+			RETURNING id, (xmax = 0) AS inserted, username`, args...).Scan(&returned).Error
+		if err != nil {
+			return err
+		}
+
+		newPassword := map[string]bool{}
+		for _, row := range rows {
+			if row.PasswordHash != "" {
+				newPassword[domain.UsernameKey(row.Username)] = true
+			}
+		}
+		var rehashed []string
+		for _, row := range returned {
+			if !row.Inserted && newPassword[domain.UsernameKey(row.Username)] {
+				rehashed = append(rehashed, row.ID)
+			}
+		}
+		if len(rehashed) > 0 {
+			if err := revokeSessions(tx, rehashed...); err != nil {
+				return err
+			}
+		}
+
+		created := count(result, returned, len(rows))
+		if len(created) == 0 {
+			return nil
+		}
+		// New players start with a zero score in every game, as on sign up.
+		err = tx.Exec(`INSERT INTO scores (user_id, game_id, points)
+			SELECT users.id, games.id, 0 FROM users CROSS JOIN games
+			WHERE users.id IN ?
+			ON CONFLICT DO NOTHING`, created).Error
+		if err != nil {
+			return err
+		}
+		return touchUserGames(tx, created...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportGames creates the games of the batch. Existing games, matched by
+// slug, are only renamed with domain.ConflictOverwrite.
+func (r *importRepository) ImportGames(ctx context.Context, rows []domain.ImportGame, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	result := &domain.ImportBatchResult{}
+	err := r.batch(ctx, dryRun, func(tx *gorm.DB) error {
+		rows := mergeRows(rows, result,
+			func(row domain.ImportGame) string { return row.Slug },
+			func(kept, row domain.ImportGame) bool { return conflict == domain.ConflictOverwrite })
+
+		rows, err := rejectTakenGameNames(tx, rows, result)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		values := make([]string, 0, len(rows))
+		args := make([]interface{}, 0, 2*len(rows))
+		for _, row := range rows {
+			values = append(values, "(?, ?)")
+			args = append(args, row.Name, row.Slug)
+		}
+
+		onConflict := "DO NOTHING"
+		if conflict == domain.ConflictOverwrite {
+			onConflict = "DO UPDATE SET name = EXCLUDED.name WHERE games.name <> EXCLUDED.name"
+		}
+
+		var returned []upserted
+		err = tx.Raw(`INSERT INTO games (name, slug)
+			VALUES `+strings.Join(values, ", ")+`
+			ON CONFLICT (slug) `+onConflict+`
+			RETURNING id, (xmax = 0) AS inserted`, args...).Scan(&returned).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("%w: game names clash within the batch", domain.ErrInvalidImport)
+			}
+			return err
+		}
+
+		created := count(result, returned, len(rows))
+		var renamed []string
+		for _, row := range returned {
+			if !row.Inserted {
+				renamed = append(renamed, row.ID)
+			}
+		}
+		if err := touchGames(tx, renamed); err != nil {
+			return err
+		}
+		if len(created) == 0 {
+			return nil
+		}
+		// Every player starts with a zero score in a new game.
+		return tx.Exec(`INSERT INTO scores (user_id, game_id, points)
+			SELECT users.id, games.id, 0 FROM users CROSS JOIN games
+			WHERE games.id IN ? AND users.role <> ?
+			ON CONFLICT DO NOTHING`, created, domain.RoleAdmin).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportScores writes the scores of the batch. Users are looked up by ID or
+// username and games by ID or slug. Whether an existing score changes
+// depends on conflict; with domain.ConflictSkip the zero scores every player
+// starts with count as missing.
+func (r *importRepository) ImportScores(ctx context.Context, rows []domain.ImportScore, conflict domain.ConflictMode, dryRun bool) (*domain.ImportBatchResult, error) {
+	result := &domain.ImportBatchResult{}
+	err := r.batch(ctx, dryRun, func(tx *gorm.DB) error {
+		userRefs := make([]string, 0, len(rows))
+		gameRefs := make([]string, 0, len(rows))
+		for _, row := range rows {
+			userRefs = append(userRefs, row.User)
+			gameRefs = append(gameRefs, row.Game)
+		}
+		users, err := findImportUsers(tx, userRefs)
+		if err != nil {
+			return err
+		}
+		games, err := findImportGames(tx, gameRefs)
+		if err != nil {
+			return err
+		}
+
+		// Rows that refer to the same user or game in another way are merged
+		// once the references are resolved.
+		resolved := make([]domain.ImportScore, 0, len(rows))
+		for _, row := range rows {
+			user, ok := users[strings.ToLower(row.User)]
+			switch {
+			case !ok:
+				result.Rejected = append(result.Rejected, domain.ImportRowError{Line: row.Line, Field: "user", Message: "user not found"})
+				continue
+			case user.Role == domain.RoleAdmin:
+				result.Rejected = append(result.Rejected, domain.ImportRowError{Line: row.Line, Field: "user", Message: "admins have no scores"})
+				continue
+			}
+			game, ok := games[strings.ToLower(row.Game)]
+			if !ok {
+				result.Rejected = append(result.Rejected, domain.ImportRowError{Line: row.Line, Field: "game", Message: "game not found"})
+				continue
+			}
+			row.User, row.Game = user.ID, game.ID
+			resolved = append(resolved, row)
+		}
+
+		resolved = mergeRows(resolved, result,
+			func(row domain.ImportScore) string { return row.User + "/" + row.Game },
+			func(kept, row domain.ImportScore) bool {
+				switch conflict {
+				case domain.ConflictOverwrite:
+					return true
+				case domain.ConflictKeepBest:
+					return domain.BetterScore(row.Points, kept.Points)
+				}
+				return false
+			})
+		if len(resolved) == 0 {
+			return nil
+		}
+
+		values := make([]string, 0, len(resolved))
+		args := make([]interface{}, 0, 3*len(resolved))
+		gameIDs := map[string]bool{}
+		for _, row := range resolved {
+			values = append(values, "(?, ?, ?)")
+			args = append(args, row.User, row.Game, row.Points)
+			gameIDs[row.Game] = true
+		}
+
+		// Mirrors domain.BetterScore for keep-best.
+		where := "EXCLUDED.points > scores.points"
+		switch conflict {
+		case domain.ConflictOverwrite:
+			where = "EXCLUDED.points <> scores.points"
+		case domain.ConflictSkip:
+			where = "scores.points = 0"
+		}
+
+		var returned []struct{ Inserted bool }
+		err = tx.Raw(`INSERT INTO scores (user_id, game_id, points)
+			VALUES `+strings.Join(values, ", ")+`
+			ON CONFLICT (user_id, game_id) DO UPDATE SET points = EXCLUDED.points WHERE `+where+`
+			RETURNING (xmax = 0) AS inserted`, args...).Scan(&returned).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range returned {
+			if row.Inserted {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+		result.Skipped += len(resolved) - len(returned)
+		if len(returned) == 0 {
+			return nil
+		}
+
+		touched := make([]string, 0, len(gameIDs))
+		for id := range gameIDs {
+			touched = append(touched, id)
+		}
+		return touchGames(tx, touched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// batch runs fn in a transaction, rolled back when dryRun is set.
+func (r *importRepository) batch(ctx context.Context, dryRun bool, fn func(tx *gorm.DB) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
+
+// mergeRows keeps one row per key, as a single INSERT can't touch a row
+// twice. replace tells whether a later row replaces the one kept, like it
+// would if it came in a later batch; the other one counts as skipped.
+func mergeRows[T any](rows []T, result *domain.ImportBatchResult, key func(T) string, replace func(kept, row T) bool) []T {
+	index := make(map[string]int, len(rows))
+	merged := make([]T, 0, len(rows))
+	for _, row := range rows {
+		k := key(row)
+		i, seen := index[k]
+		if !seen {
+			index[k] = len(merged)
+			merged = append(merged, row)
+			continue
+		}
+		result.Skipped++
+		if replace(merged[i], row) {
+			merged[i] = row
+		}
+	}
+	return merged
+}
+
+// count adds the returned rows to result and returns the IDs of the ones
+// created.
+func count(result *domain.ImportBatchResult, returned []upserted, rows int) []string {
+	var created []string
+	for _, row := range returned {
+		if row.Inserted {
+			result.Created++
+			created = append(created, row.ID)
+		} else {
+			result.Updated++
+		}
+	}
+	result.Skipped += rows - len(returned)
+	return created
+}
+
+// rejectTakenEmails drops the rows whose email belongs to another user, in
+// the database or earlier in the batch.
+func rejectTakenEmails(tx *gorm.DB, rows []domain.ImportUser, result *domain.ImportBatchResult) ([]domain.ImportUser, error) {
+	var emails []string
+	for _, row := range rows {
+		if row.Email != "" {
+			emails = append(emails, row.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return rows, nil
+	}
+
+	var owners []User
+	if err := tx.Select("username", "email").Where("email IN ?", emails).Find(&owners).Error; err != nil {
+		return nil, err
+	}
+	owner := make(map[string]string, len(owners))
+	for _, u := range owners {
+		owner[*u.Email] = domain.UsernameKey(u.Username)
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		if row.Email != "" {
+			key := domain.UsernameKey(row.Username)
+			if taken, ok := owner[row.Email]; ok && taken != key {
+				result.Rejected = append(result.Rejected, domain.ImportRowError{Line: row.Line, Field: "email", Message: "email already in use"})
+				continue
+			}
+			owner[row.Email] = key
+		}
+		kept = append(kept, row)
+	}
+	return kept, nil
+}
+
+// rejectTakenGameNames drops the rows whose name belongs to a game with
+// another slug, in the database or earlier in the batch.
+func rejectTakenGameNames(tx *gorm.DB, rows []domain.ImportGame, result *domain.ImportBatchResult) ([]domain.ImportGame, error) {
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.Name)
+	}
+
+	var existing []Game
+	if err := tx.Select("name", "slug").Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	owner := make(map[string]string, len(existing))
+	for _, g := range existing {
+		owner[g.Name] = g.Slug
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		if slug, ok := owner[row.Name]; ok && slug != row.Slug {
+			result.Rejected = append(result.Rejected, domain.ImportRowError{Line: row.Line, Field: "name", Message: "another game has this name"})
+			continue
+		}
+		owner[row.Name] = row.Slug
+		kept = append(kept, row)
+	}
+	return kept, nil
+}
+
+// findImportUsers looks users up by ID or username, keyed by the lowercased
+// reference.
+func findImportUsers(tx *gorm.DB, refs []string) (map[string]User, error) {
+	ids, names := splitRefs(refs)
+	var users []User
+	err := tx.Select("id", "username", "role").
+		Where("id IN ? OR lower(username) IN ?", ids, names).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]User, 2*len(users))
+	for _, u := range users {
+		found[strings.ToLower(u.ID)] = u
+		found[domain.UsernameKey(u.Username)] = u
+	}
+	return found, nil
+}
+
+// findImportGames looks games up by ID or slug, keyed by the lowercased
+// reference.
+func findImportGames(tx *gorm.DB, refs []string) (map[string]Game, error) {
+	ids, slugs := splitRefs(refs)
+	var games []Game
+	if err := tx.Select("id", "slug").Where("id IN ? OR slug IN ?", ids, slugs).Find(&games).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]Game, 2*len(games))
+	for _, g := range games {
+		found[strings.ToLower(g.ID)] = g
+		found[g.Slug] = g
+	}
+	return found, nil
+}
+
+// splitRefs sorts references into lowercased UUIDs and names.
+func splitRefs(refs []string) (ids, names []string) {
+	for _, ref := range refs {
+		ref = strings.ToLower(ref)
+		if uuid.Validate(ref) == nil {
+			ids = append(ids, ref)
+		} else {
+			names = append(names, ref)
+		}
+	}
+	return ids, names
+}
@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportRepository_ScoresKeepBest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	importRepo := repository.NewImportRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	users, err := importRepo.ImportUsers(ctx, []domain.ImportUser{{Line: 2, Username: "ana"}, {Line: 3, Username: "ANA"}}, domain.ConflictKeepBest, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, users.Created)
+	assert.Equal(t, 1, users.Skipped)
+
+	ana, err := userRepo.GetUserByUsername("ana")
+	require.NoError(t, err)
+
+	games, err := importRepo.ImportGames(ctx, []domain.ImportGame{{Line: 2, Name: "Pong", Slug: "pong"}}, domain.ConflictKeepBest, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, games.Created)
+
+	rows := []domain.ImportScore{
+		{Line: 2, User: "ana", Game: "pong", Points: 10},
+		{Line: 3, User: "Ana", Game: "pong", Points: 30},
+		{Line: 4, User: "bob", Game: "pong", Points: 5},
+	}
+	dry, err := importRepo.ImportScores(ctx, rows, domain.ConflictKeepBest, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dry.Updated, "the zero score every player starts with")
+	require.Len(t, dry.Rejected, 1)
+	assert.Equal(t, 4, dry.Rejected[0].Line)
+
+	scores, err := scoreRepo.GetScoresByUserID(ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, (*scores)[0].Points, "a dry run writes nothing")
+
+	_, err = importRepo.ImportScores(ctx, rows, domain.ConflictKeepBest, false)
+	require.NoError(t, err)
+	lower, err := importRepo.ImportScores(ctx, []domain.ImportScore{{Line: 2, User: "ana", Game: "pong", Points: 20}}, domain.ConflictKeepBest, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, lower.Skipped)
+
+	scores, err = scoreRepo.GetScoresByUserID(ana.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, (*scores)[0].Points)
+}
+
+func TestImportRepository_OverwriteOnlyTouchesPlayers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	importRepo := repository.NewImportRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	ctx := context.Background()
+
+	_, err := importRepo.ImportUsers(ctx, []domain.ImportUser{{Line: 2, Username: "ana"}, {Line: 3, Username: "mod"}}, domain.ConflictKeepBest, false)
+	require.NoError(t, err)
+	ana, err := userRepo.GetUserByUsername("ana")
+	require.NoError(t, err)
+	mod, err := userRepo.GetUserByUsername("mod")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.SetUserRole(mod.ID, domain.RoleModerator))
+
+	rows := []domain.ImportUser{
+		{Line: 2, Username: "ana", PasswordHash: "imported-hash"},
+		{Line: 3, Username: "mod", PasswordHash: "imported-hash"},
+	}
+	result, err := importRepo.ImportUsers(ctx, rows, domain.ConflictOverwrite, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Skipped)
+
+	creds, err := userRepo.GetUserCreds("mod")
+	require.NoError(t, err)
+	assert.NotEqual(t, "imported-hash", creds.PasswordHash)
+
+	// The sessions of a player whose password was replaced are ended.
+	status, err := userRepo.GetAccountStatus(ana.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, status.SessionsRevokedAt)
+	status, err = userRepo.GetAccountStatus(mod.ID)
+	require.NoError(t, err)
+	assert.Nil(t, status.SessionsRevokedAt)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) ports.LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) ListLoginAttempts(keys []string) (*[]domain.LoginAttempt, error) {
+	var models []LoginAttempt
+	if err := r.db.Where("key IN ?", keys).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	attempts := make([]domain.LoginAttempt, 0, len(models))
+	for _, m := range models {
+		attempts = append(attempts, toDomainLoginAttempt(m))
+	}
+	return &attempts, nil
+}
+
+// RecordLoginFailure adds a failure to the key in a single statement, so
+// concurrent attempts are all counted. Failures older than window are
+// forgotten and counting starts over.
+func (r *loginAttemptRepository) RecordLoginFailure(key string, now time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	model := LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", now.Add(-window)),
+				"last_failure_at": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&model).Error
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := toDomainLoginAttempt(model)
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) LockLogin(key string, until time.Time) error {
+	return r.db.Model(&LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (r *loginAttemptRepository) ClearLoginAttempts(key string) error {
+	return r.db.Where("key = ?", key).Delete(&LoginAttempt{}).Error
+}
+
+func toDomainLoginAttempt(m LoginAttempt) domain.LoginAttempt {
+	return domain.LoginAttempt{
+		Key:           m.Key,
+		Failures:      m.Failures,
+		LastFailureAt: m.LastFailureAt,
+		LockedUntil:   m.LockedUntil,
+	}
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"time"
+)
+
+// LoginAttempt is keyed by "user:<username>" or "ip:<address>".
+type LoginAttempt struct {
+	Key           string    `gorm:"primaryKey"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+)
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) ports.ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// UpdateRole sets the role of the user and records the action in the same
+// transaction.
+func (r *moderationRepository) UpdateRole(action *domain.ModerationAction, role string) error {
+	return r.apply(action, map[string]interface{}{"role": role})
+}
+
+// UpdateStatus sets the account status of the user and records the action in
+// the same transaction.
+func (r *moderationRepository) UpdateStatus(action *domain.ModerationAction, status *domain.AccountStatus) error {
+	return r.apply(action, map[string]interface{}{
+		"status":          status.State,
+		"status_reason":   status.Reason,
+		"suspended_until": status.SuspendedUntil,
+	})
+}
+
+func (r *moderationRepository) ListActions(userID string) (*[]domain.ModerationAction, error) {
+	var models []ModerationAction
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	actions := make([]domain.ModerationAction, 0, len(models))
+	for _, m := range models {
+		actions = append(actions, toDomainModerationAction(&m))
+	}
+	return &actions, nil
+}
+
+func (r *moderationRepository) apply(action *domain.ModerationAction, changes map[string]interface{}) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&User{}).Where("id = ?", action.UserID).Updates(changes)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		// Banned players drop out of the leaderboards.
+		if err := touchUserGames(tx, action.UserID); err != nil {
+			return err
+		}
+
+		model := ModerationAction{
+			UserID:  action.UserID,
+			ActorID: action.ActorID,
+			Action:  action.Action,
+			Reason:  action.Reason,
+			Until:   action.Until,
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+
+		action.ID = model.ID
+		action.CreatedAt = model.CreatedAt
+		return nil
+	})
+}
+
+func toDomainModerationAction(m *ModerationAction) domain.ModerationAction {
+	return domain.ModerationAction{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		ActorID:   m.ActorID,
+		Action:    m.Action,
+		Reason:    m.Reason,
+		Until:     m.Until,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+)
+
+type ModerationAction struct {
+	ID        string `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID    string `gorm:"type:uuid;not null;index"`
+	ActorID   string
+	Action    string `gorm:"not null"`
+	Reason    string `gorm:"size:500;not null"`
+	Until     *time.Time
+	CreatedAt time.Time
+
+	//FK
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationRepository_BanHidesScores(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	gameRepo := repository.NewGameRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+	modRepo := repository.NewModerationRepository(db)
+
+	cheater, err := userRepo.CreateUserWithInitialScores(context.Background(), "cheater", "", "hash")
+	require.NoError(t, err)
+	player, err := userRepo.CreateUserWithInitialScores(context.Background(), "player", "", "hash")
+	require.NoError(t, err)
+	game, err := gameRepo.CreateGameWithInitialScores(context.Background(), "pong", "pong")
+	require.NoError(t, err)
+
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{GameID: game.ID, UserID: cheater.ID, Points: 9999}))
+	require.NoError(t, scoreRepo.SubmitScore(&domain.Score{GameID: game.ID, UserID: player.ID, Points: 100}))
+
+	ban := &domain.ModerationAction{UserID: cheater.ID, ActorID: player.ID, Action: domain.ActionBan, Reason: "cheating"}
+	require.NoError(t, modRepo.UpdateStatus(ban, &domain.AccountStatus{State: domain.StatusBanned, Reason: "cheating"}))
+	assert.NotEmpty(t, ban.ID)
+
+	status, err := userRepo.GetAccountStatus(cheater.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusBanned, status.State)
+	assert.Equal(t, "cheating", status.Reason)
+
+	scores, err := scoreRepo.GetScoresByGameID(game.ID)
+	require.NoError(t, err)
+	assert.Len(t, *scores, 1)
+	assert.Equal(t, player.ID, (*scores)[0].UserID)
+
+	// Nor on the scores of the player.
+	_, err = scoreRepo.GetScoresByUserID(cheater.ID)
+	assert.ErrorIs(t, err, domain.ErrScoreNotFound)
+
+	// The scores are kept and show up again once the ban is lifted.
+
+	reinstate := &domain.ModerationAction{UserID: cheater.ID, Action: domain.ActionReinstate, Reason: "appeal accepted"}
+	require.NoError(t, modRepo.UpdateStatus(reinstate, &domain.AccountStatus{State: domain.StatusActive}))
+
+	scores, err = scoreRepo.GetScoresByGameID(game.ID)
+	require.NoError(t, err)
+	assert.Len(t, *scores, 2)
+	own, err := scoreRepo.GetScoresByUserID(cheater.ID)
+	require.NoError(t, err)
+	assert.Len(t, *own, 1)
+
+	actions, err := modRepo.ListActions(cheater.ID)
+	require.NoError(t, err)
+	require.Len(t, *actions, 2)
+	assert.Equal(t, domain.ActionReinstate, (*actions)[0].Action)
+}
+
+func TestModerationRepository_UnknownUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	modRepo := repository.NewModerationRepository(db)
+
+	err := modRepo.UpdateRole(&domain.ModerationAction{
+		UserID: "00000000-0000-0000-0000-000000000000",
+		Action: domain.ActionPromote,
+		Reason: "typo",
+	}, domain.RoleAdmin)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) ports.OIDCRepository {
+	return &oidcRepository{db: db}
+}
+
+// CreateLoginState stores a pending login. Abandoned logins are cleaned up
+// on the way.
+func (r *oidcRepository) CreateLoginState(state *domain.OIDCLoginState, stateHash string) error {
+	if err := r.db.Where("expires_at <= ?", time.Now()).Delete(&OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+
+	var userID *string
+	if state.UserID != "" {
+		userID = &state.UserID
+	}
+	return r.db.Create(&OIDCLoginState{
+		StateHash:    stateHash,
+		Provider:     state.Provider,
+		CodeVerifier: state.CodeVerifier,
+		Nonce:        state.Nonce,
+		UserID:       userID,
+		BindingHash:  state.BindingHash,
+		ExpiresAt:    state.ExpiresAt,
+	}).Error
+}
+
+// ConsumeLoginState deletes a pending login and returns it, so every state
+// can complete a single login. Unknown and expired states yield
+// ErrOIDCStateInvalid.
+func (r *oidcRepository) ConsumeLoginState(stateHash string, now time.Time) (*domain.OIDCLoginState, error) {
+	var states []OIDCLoginState
+	res := r.db.
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(states) == 0 || !states[0].ExpiresAt.After(now) {
+		return nil, domain.ErrOIDCStateInvalid
+	}
+
+	state := &domain.OIDCLoginState{
+		Provider:     states[0].Provider,
+		CodeVerifier: states[0].CodeVerifier,
+		Nonce:        states[0].Nonce,
+		BindingHash:  states[0].BindingHash,
+		ExpiresAt:    states[0].ExpiresAt,
+	}
+	if states[0].UserID != nil {
+		state.UserID = *states[0].UserID
+	}
+	return state, nil
+}
+
+func (r *oidcRepository) GetUserByIdentity(provider, subject string) (*domain.User, error) {
+	var user User
+	err := r.db.
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject = ?", provider, subject).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return toDomainUser(&user), nil
+}
+
+func (r *oidcRepository) LinkIdentity(identity *domain.ExternalIdentity) error {
+	err := r.db.Create(toIdentityModel(identity)).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrIdentityLinked
+	}
+	return err
+}
+
+// CreateUserWithIdentity registers a user without a password, who can only
+// sign in through the identity provider, and links the identity to them.
+func (r *oidcRepository) CreateUserWithIdentity(ctx context.Context, username string, identity *domain.ExternalIdentity) (*domain.User, error) {
+	newUser := &User{
+		Username: username,
+		Email:    nullableEmail(identity.Email),
+		Role:     domain.RolePlayer,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createUserWithScores(tx, newUser); err != nil {
+			return err
+		}
+
+		identity.UserID = newUser.ID
+		if err := tx.Create(toIdentityModel(identity)).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrIdentityLinked
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, duplicateUserError(r.db, err, newUser.Email)
+	}
+
+	return toDomainUser(newUser), nil
+}
+
+func toIdentityModel(identity *domain.ExternalIdentity) *UserIdentity {
+	return &UserIdentity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		UserID:   identity.UserID,
+		Email:    identity.Email,
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+)
+
+type UserIdentity struct {
+	Provider  string `gorm:"primaryKey"`
+	Subject   string `gorm:"primaryKey"`
+	UserID    string `gorm:"type:uuid;not null;index"`
+	Email     string
+	CreatedAt time.Time
+
+	//FK
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"primaryKey"`
+	Provider     string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	UserID       *string   `gorm:"type:uuid"`
+	BindingHash  string    `gorm:"not null;default:''"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
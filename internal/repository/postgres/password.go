@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+)
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) ports.PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) CreateResetToken(token *domain.ResetToken, tokenHash string) error {
+	model := &PasswordResetToken{
+		UserID:    token.UserID,
+		TokenHash: tokenHash,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	token.ID = model.ID
+	return nil
+}
+
+// GetResetToken returns a token that is still valid without using it up.
+// Unknown, used and expired tokens yield ErrResetTokenInvalid.
+func (r *passwordResetRepository) GetResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error) {
+	var token PasswordResetToken
+	err := r.db.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrResetTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &domain.ResetToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
+
+// ConsumeResetToken marks a valid token as used and returns it. Any other
+// pending token of the same user is used up too, so only the latest reset
+// link matters. Unknown, used and expired tokens yield ErrResetTokenInvalid.
+func (r *passwordResetRepository) ConsumeResetToken(tokenHash string, now time.Time) (*domain.ResetToken, error) {
+	var token PasswordResetToken
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrResetTokenInvalid
+			}
+			return err
+		}
+
+		// The used_at condition makes concurrent uses of one token race on
+		// the update, so only one of them wins.
+		res := tx.Model(&PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrResetTokenInvalid
+		}
+
+		return tx.Model(&PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ResetToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    &now,
+	}, nil
+}
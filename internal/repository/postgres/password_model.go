@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+)
+
+type PasswordResetToken struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	//FK
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
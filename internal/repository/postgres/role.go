@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) ports.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) ListRoles() (*[]domain.Role, error) {
+	var roles []Role
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rolesResponse []domain.Role
+	for _, role := range roles {
+		rolesResponse = append(rolesResponse, toDomainRole(role))
+	}
+	return &rolesResponse, nil
+}
+
+func (r *roleRepository) GetRole(name string) (*domain.Role, error) {
+	var role Role
+	err := r.db.Preload("Permissions").First(&role, "name = ?", name).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	domainRole := toDomainRole(role)
+	return &domainRole, nil
+}
+
+func toDomainRole(role Role) domain.Role {
+	perms := make([]domain.Permission, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		perms = append(perms, domain.Permission(p.Permission))
+	}
+
+	return domain.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: perms,
+	}
+}
+
+// seedRoles makes sure the default roles exist. Permissions are only added,
+// never removed, so grants made by hand survive a restart.
+func seedRoles(db *gorm.DB) error {
+	for _, role := range domain.DefaultRoles {
+		model := Role{Name: role.Name, Description: role.Description}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model).Error; err != nil {
+			return err
+		}
+
+		for _, perm := range role.Permissions {
+			rp := RolePermission{RoleName: role.Name, Permission: string(perm)}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rp).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
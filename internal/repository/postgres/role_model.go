@@ -0,0 +1,14 @@
+package repository
+
+type Role struct {
+	Name        string `gorm:"primaryKey"`
+	Description string
+
+	//FK
+	Permissions []RolePermission `gorm:"foreignKey:RoleName;constraint:OnDelete:CASCADE"`
+}
+
+type RolePermission struct {
+	RoleName   string `gorm:"primaryKey"`
+	Permission string `gorm:"primaryKey"`
+}
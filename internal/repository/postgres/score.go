@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
@@ -35,74 +36,153 @@ func (r *scoreRepository) GetScore(userID, gameID string) (*domain.Score, error)
 }
 
 func (r *scoreRepository) SubmitScore(score *domain.Score) error {
-	err := r.db.Save(&Score{
-		GameID: score.GameID,
-		UserID: score.UserID,
-		Points: score.Points,
-	}).Error
-	return err
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(&Score{
+			GameID: score.GameID,
+			UserID: score.UserID,
+			Points: score.Points,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return touchGame(tx, score.GameID)
+	})
 }
 
 func (r *scoreRepository) GetScoresByGameID(gameID string) (*[]domain.Score, error) {
 	var scores []dto.UserScoreDTO
-	err := r.db.
-		Table("scores").
-		Select("users.username, scores.user_id, games.name as game_name, scores.game_id, scores.points").
-		Joins("JOIN users ON users.id = scores.user_id").
-		Joins("JOIN games ON games.id = scores.game_id").
-		Where("scores.game_id = ?", gameID).
-		Order("scores.points DESC").
+	if err := gameScoresQuery(r.db, gameID).Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	return toDomainScores(scores)
+}
+
+func (r *scoreRepository) GetScoresByUserID(playerID string) (*[]domain.Score, error) {
+	var scores []dto.UserScoreDTO
+	if err := userScoresQuery(r.db, playerID).Scan(&scores).Error; err != nil {
+		return nil, err
+	}
+	return toDomainScores(scores)
+}
+
+// GetScoresByGameIDs returns the best limit scores of several games at once,
+// best first. Games without scores are left out.
+func (r *scoreRepository) GetScoresByGameIDs(gameIDs []string, limit int) (*[]domain.Score, error) {
+	ranked := gameScoresQuery(r.db, gameIDs...).
+		Select(scoreColumns + ", ROW_NUMBER() OVER (PARTITION BY scores.game_id ORDER BY scores.points DESC) AS position")
+
+	var scores []dto.UserScoreDTO
+	err := r.db.Table("(?) AS ranked", ranked).
+		Where("position <= ?", limit).
+		Order("points DESC").
 		Scan(&scores).Error
 	if err != nil {
 		return nil, err
 	}
+	return toDomainScoreList(scores), nil
+}
 
-	if len(scores) == 0 {
-		return nil, domain.ErrScoreNotFound
+// GetScoresByUserIDs returns the scores of several users at once, each user's
+// best first. Users without scores are left out.
+func (r *scoreRepository) GetScoresByUserIDs(userIDs []string) (*[]domain.Score, error) {
+	var scores []dto.UserScoreDTO
+	if err := userScoresQuery(r.db, userIDs...).Scan(&scores).Error; err != nil {
+		return nil, err
 	}
+	return toDomainScoreList(scores), nil
+}
 
-	var scoresResponse []domain.Score
-	for _, score := range scores {
-		scoresResponse = append(scoresResponse, domain.Score{
-			Username: score.Username,
-			UserID:   score.UserID,
-			GameName: score.GameName,
-			GameID:   score.GameID,
-			Points:   score.Points,
-		})
-	}
+// StreamScoresByGameID calls fn with every score of the leaderboard of the
+// game as it is read, best first.
+func (r *scoreRepository) StreamScoresByGameID(ctx context.Context, gameID string, fn func(*domain.Score) error) error {
+	return streamScores(gameScoresQuery(r.db.WithContext(ctx), gameID), fn)
+}
 
-	return &scoresResponse, nil
+// StreamScoresByUserID calls fn with every score of the user as it is read,
+// best first.
+func (r *scoreRepository) StreamScoresByUserID(ctx context.Context, userID string, fn func(*domain.Score) error) error {
+	return streamScores(userScoresQuery(r.db.WithContext(ctx), userID), fn)
 }
 
-func (r *scoreRepository) GetScoresByUserID(playerID string) (*[]domain.Score, error) {
-	var scores []dto.UserScoreDTO
-	err := r.db.
+const scoreColumns = "users.username, scores.user_id, games.name as game_name, games.slug as game_slug, scores.game_id, scores.points"
+
+func gameScoresQuery(db *gorm.DB, gameIDs ...string) *gorm.DB {
+	return db.
 		Table("scores").
-		Select("users.username, scores.user_id, games.name as game_name, scores.game_id, scores.points").
+		Select(scoreColumns).
 		Joins("JOIN users ON users.id = scores.user_id").
 		Joins("JOIN games ON games.id = scores.game_id").
-		Order("scores.points DESC").
-		Where("scores.user_id = ?", playerID).
-		Scan(&scores).Error
+		Where("scores.game_id IN ?", gameIDs).
+		// Banned players are kept out of leaderboards and statistics; their
+		// scores stay stored in case the ban is lifted.
+		Where("users.status <> ?", domain.StatusBanned).
+		Order("scores.points DESC")
+}
+
+func userScoresQuery(db *gorm.DB, userIDs ...string) *gorm.DB {
+	return db.
+		Table("scores").
+		Select(scoreColumns).
+		Joins("JOIN users ON users.id = scores.user_id").
+		Joins("JOIN games ON games.id = scores.game_id").
+		Where("scores.user_id IN ?", userIDs).
+		// Hidden like on leaderboards. Banned players can't sign in, so
+		// this never hides their own scores from them.
+		Where("users.status <> ?", domain.StatusBanned).
+		Order("scores.points DESC")
+}
+
+// streamScores runs query and hands its rows to fn one at a time, stopping at
+// the first error fn returns.
+func streamScores(query *gorm.DB, fn func(*domain.Score) error) error {
+	rows, err := query.Rows()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var row dto.UserScoreDTO
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		score := toDomainScore(row)
+		if err := fn(&score); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func toDomainScores(scores []dto.UserScoreDTO) (*[]domain.Score, error) {
 	if len(scores) == 0 {
 		return nil, domain.ErrScoreNotFound
 	}
 
 	var scoresResponse []domain.Score
 	for _, score := range scores {
-		scoresResponse = append(scoresResponse, domain.Score{
-			Username: score.Username,
-			UserID:   score.UserID,
-			GameName: score.GameName,
-			GameID:   score.GameID,
-			Points:   score.Points,
-		})
+		scoresResponse = append(scoresResponse, toDomainScore(score))
 	}
-
 	return &scoresResponse, nil
 }
+
+// toDomainScoreList is toDomainScores for lookups of several keys, where no
+// scores at all is not an error.
+func toDomainScoreList(scores []dto.UserScoreDTO) *[]domain.Score {
+	result := make([]domain.Score, 0, len(scores))
+	for _, score := range scores {
+		result = append(result, toDomainScore(score))
+	}
+	return &result
+}
+
+func toDomainScore(score dto.UserScoreDTO) domain.Score {
+	return domain.Score{
+		Username: score.Username,
+		UserID:   score.UserID,
+		GameName: score.GameName,
+		GameSlug: score.GameSlug,
+		GameID:   score.GameID,
+		Points:   score.Points,
+	}
+}
@@ -19,11 +19,11 @@ func TestScoreRepository_CreateAndQuery(t *testing.T) {
 	gameRepo := repository.NewGameRepository(db)
 	scoreRepo := repository.NewScoreRepository(db)
 
-	game, err := gameRepo.CreateGameWithInitialScores(context.Background(), "pong")
+	game, err := gameRepo.CreateGameWithInitialScores(context.Background(), "pong", "pong")
 	assert.NoError(t, err)
 	t.Logf("Created game: ID=%s, Name=%s", game.ID, game.Name)
 	// setup
-	user, err := userRepo.CreateUserWithInitialScores(context.Background(), "juan", "123")
+	user, err := userRepo.CreateUserWithInitialScores(context.Background(), "juan", "", "123")
 	assert.NoError(t, err)
 	t.Logf("Created user: ID=%s, Username=%s", user.ID, user.Username)
 
@@ -42,3 +42,83 @@ func TestScoreRepository_CreateAndQuery(t *testing.T) {
 		t.Logf("Score: UserID=%s, GameID=%s, Value=%d", s.UserID, s.GameID, s.Points)
 	}
 }
+
+func TestScoreRepository_SubmitBumpsGameVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	gameRepo := repository.NewGameRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+
+	game, err := gameRepo.CreateGameWithInitialScores(context.Background(), "tetris", "tetris")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), game.Version)
+
+	user, err := userRepo.CreateUserWithInitialScores(context.Background(), "ana", "", "123")
+	assert.NoError(t, err)
+
+	joined, err := gameRepo.GetGameByID(game.ID)
+	assert.NoError(t, err)
+	assert.Greater(t, joined.Version, game.Version, "a new player joins the leaderboard")
+
+	err = scoreRepo.SubmitScore(&domain.Score{GameID: game.ID, UserID: user.ID, Points: 10})
+	assert.NoError(t, err)
+
+	submitted, err := gameRepo.GetGameByID(game.ID)
+	assert.NoError(t, err)
+	assert.Greater(t, submitted.Version, joined.Version)
+	assert.False(t, submitted.ScoresUpdatedAt.Before(joined.ScoresUpdatedAt))
+}
+
+func TestScoreRepository_BatchLookups(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	gameRepo := repository.NewGameRepository(db)
+	scoreRepo := repository.NewScoreRepository(db)
+	ctx := context.Background()
+
+	pong, err := gameRepo.CreateGameWithInitialScores(ctx, "pong", "pong")
+	assert.NoError(t, err)
+	chess, err := gameRepo.CreateGameWithInitialScores(ctx, "chess", "chess")
+	assert.NoError(t, err)
+	ana, err := userRepo.CreateUserWithInitialScores(ctx, "ana", "", "123")
+	assert.NoError(t, err)
+	bob, err := userRepo.CreateUserWithInitialScores(ctx, "bob", "", "123")
+	assert.NoError(t, err)
+
+	assert.NoError(t, scoreRepo.SubmitScore(&domain.Score{GameID: pong.ID, UserID: ana.ID, Points: 10}))
+	assert.NoError(t, scoreRepo.SubmitScore(&domain.Score{GameID: pong.ID, UserID: bob.ID, Points: 30}))
+	assert.NoError(t, scoreRepo.SubmitScore(&domain.Score{GameID: chess.ID, UserID: ana.ID, Points: 20}))
+
+	scores, err := scoreRepo.GetScoresByGameIDs([]string{pong.ID, chess.ID}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, *scores, 4)
+	assert.Equal(t, 30, (*scores)[0].Points)
+
+	scores, err = scoreRepo.GetScoresByGameIDs([]string{pong.ID, chess.ID}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, *scores, 2, "the best score of each game")
+	assert.Equal(t, 30, (*scores)[0].Points)
+	assert.Equal(t, 20, (*scores)[1].Points)
+
+	scores, err = scoreRepo.GetScoresByUserIDs([]string{ana.ID})
+	assert.NoError(t, err)
+	assert.Len(t, *scores, 2)
+	assert.Equal(t, "ana", (*scores)[0].Username)
+
+	users, err := userRepo.GetUsersByIDs([]string{ana.ID, bob.ID})
+	assert.NoError(t, err)
+	assert.Len(t, *users, 2)
+
+	games, err := gameRepo.GetGamesByIDs([]string{chess.ID})
+	assert.NoError(t, err)
+	assert.Len(t, *games, 1)
+	assert.Equal(t, "chess", (*games)[0].Slug)
+}
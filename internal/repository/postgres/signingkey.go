@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+)
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) ports.SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) CreateSigningKey(key *domain.SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	model := &SigningKey{
+		Algorithm:  key.Algorithm,
+		PrivateKey: der,
+		RotatesAt:  key.RotatesAt,
+		ExpiresAt:  key.ExpiresAt,
+	}
+	if !key.ActivatesAt.IsZero() {
+		model.ActivatesAt = &key.ActivatesAt
+	}
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	key.ID = model.ID
+	key.CreatedAt = model.CreatedAt
+	if key.ActivatesAt.IsZero() {
+		key.ActivatesAt = model.CreatedAt
+	}
+	return nil
+}
+
+// ListSigningKeys returns the keys that have not expired yet, newest first.
+func (r *signingKeyRepository) ListSigningKeys(now time.Time) (*[]domain.SigningKey, error) {
+	var models []SigningKey
+	if err := r.db.Where("expires_at > ?", now).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.SigningKey, 0, len(models))
+	for _, m := range models {
+		parsed, err := x509.ParsePKCS8PrivateKey(m.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode signing key %s: %w", m.ID, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s cannot sign", m.ID)
+		}
+
+		activatesAt := m.CreatedAt
+		if m.ActivatesAt != nil {
+			activatesAt = *m.ActivatesAt
+		}
+		keys = append(keys, domain.SigningKey{
+			ID:          m.ID,
+			Algorithm:   m.Algorithm,
+			PrivateKey:  signer,
+			CreatedAt:   m.CreatedAt,
+			ActivatesAt: activatesAt,
+			RotatesAt:   m.RotatesAt,
+			ExpiresAt:   m.ExpiresAt,
+		})
+	}
+	return &keys, nil
+}
+
+func (r *signingKeyRepository) DeleteExpiredSigningKeys(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&SigningKey{}).Error
+}
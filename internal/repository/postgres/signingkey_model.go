@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+)
+
+// SigningKey holds a private key used to sign access tokens, PKCS#8 encoded.
+type SigningKey struct {
+	ID         string `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Algorithm  string `gorm:"not null"`
+	PrivateKey []byte `gorm:"not null"`
+	// ActivatesAt is NULL for keys that signed from their creation.
+	ActivatesAt *time.Time
+	RotatesAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
-	gormPostgres "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -40,7 +39,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to get connection string: %v", err)
 	}
 
-	db, err := gorm.Open(gormPostgres.Open(connStr), &gorm.Config{
+	db, err := gorm.Open(openDialector(connStr), &gorm.Config{
 		TranslateError: true,
 	})
 	if err != nil {
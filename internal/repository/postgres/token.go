@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) ports.TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *domain.RefreshToken, tokenHash string) error {
+	model := &RefreshToken{
+		UserID:    token.UserID,
+		TokenHash: tokenHash,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if err := r.db.Create(model).Error; err != nil {
+		return err
+	}
+
+	token.ID = model.ID
+	return nil
+}
+
+func (r *tokenRepository) GetRefreshToken(tokenHash string) (*domain.RefreshToken, error) {
+	var token RefreshToken
+	err := r.db.First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &domain.RefreshToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt,
+		RevokedAt: token.RevokedAt,
+	}, nil
+}
+
+// RotateRefreshToken revokes the old token and stores its replacement in a
+// single transaction. It fails with ErrRefreshTokenInvalid if the old token
+// was revoked concurrently, so a token can only be rotated once.
+func (r *tokenRepository) RotateRefreshToken(oldID string, next *domain.RefreshToken, nextHash string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrRefreshTokenInvalid
+		}
+
+		model := &RefreshToken{
+			UserID:    next.UserID,
+			TokenHash: nextHash,
+			ExpiresAt: next.ExpiresAt,
+		}
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+
+		next.ID = model.ID
+		return nil
+	})
+}
+
+func (r *tokenRepository) RevokeRefreshToken(id string) error {
+	return r.db.Model(&RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of the user and records
+// when, so access tokens issued before are refused as well.
+func (r *tokenRepository) RevokeUserRefreshTokens(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, userID)
+	})
+}
+
+// revokeSessions ends every session of the users within tx: their refresh
+// tokens are revoked and access tokens issued until now are refused.
+func revokeSessions(tx *gorm.DB, userIDs ...string) error {
+	now := time.Now()
+	if err := tx.Model(&RefreshToken{}).
+		Where("user_id IN ? AND revoked_at IS NULL", userIDs).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&User{}).Where("id IN ?", userIDs).Update("sessions_revoked_at", now).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Entries past their expiry no longer matter, drop them as we go.
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&RevokedToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&RevokedToken{TokenID: tokenID, ExpiresAt: expiresAt}).Error
+	})
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
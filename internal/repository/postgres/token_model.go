@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+)
+
+type RefreshToken struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	//FK
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// RevokedToken lists access tokens that were revoked before expiring. Rows are
+// only useful until ExpiresAt, after which the token is rejected anyway.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -3,11 +3,14 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -20,23 +23,19 @@ func NewUserRepository(db *gorm.DB) ports.UserRepository {
 
 func (r *userRepository) GetUserByUsername(username string) (*domain.User, error) {
 	var user User
-	err := r.db.First(&user, "username = ?", username).Error
+	err := r.db.First(&user, "lower(username) = lower(?)", username).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
-	return &domain.User{
-		ID:       user.ID,
-		Username: user.Username,
-		IsAdmin:  user.IsAdmin,
-	}, nil
+	return toDomainUser(&user), nil
 }
 
 func (r *userRepository) GetUserCreds(username string) (*auth.AuthUserData, error) {
 	var user User
-	err := r.db.First(&user, "username = ?", username).Error
+	err := r.db.First(&user, "lower(username) = lower(?)", username).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrUserNotFound
@@ -47,11 +46,14 @@ func (r *userRepository) GetUserCreds(username string) (*auth.AuthUserData, erro
 		ID:           user.ID,
 		Username:     user.Username,
 		PasswordHash: user.PasswordHash,
-		IsAdmin:      user.IsAdmin,
+		Role:         user.Role,
+
+		MustChangePassword: user.MustChangePassword,
+		Status:             toAccountStatus(&user),
 	}, nil
 }
 
-func (r *userRepository) GetUserByID(id string) (*domain.User, error) {
+func (r *userRepository) GetUserCredsByID(id string) (*auth.AuthUserData, error) {
 	var user User
 	err := r.db.First(&user, "id = ?", id).Error
 	if err != nil {
@@ -60,55 +62,354 @@ func (r *userRepository) GetUserByID(id string) (*domain.User, error) {
 		}
 		return nil, err
 	}
-	return &domain.User{
-		ID:       user.ID,
-		Username: user.Username,
-		IsAdmin:  user.IsAdmin,
+	return &auth.AuthUserData{
+		ID:           user.ID,
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+		Role:         user.Role,
+
+		MustChangePassword: user.MustChangePassword,
+		Status:             toAccountStatus(&user),
 	}, nil
 }
 
-func (r *userRepository) CreateUserWithInitialScores(ctx context.Context, username string, passwordHash string) (*domain.User, error) {
+// GetAccountStatus only loads the status columns, as it runs on every
+// authenticated request.
+func (r *userRepository) GetAccountStatus(userID string) (*domain.AccountStatus, error) {
+	var user User
+	err := r.db.Select("status", "status_reason", "suspended_until", "sessions_revoked_at").First(&user, "id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	status := toAccountStatus(&user)
+	return &status, nil
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*domain.User, error) {
+	var user User
+	err := r.db.First(&user, "email = ?", strings.ToLower(email)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return toDomainUser(&user), nil
+}
+
+func (r *userRepository) GetUserByID(id string) (*domain.User, error) {
+	var user User
+	err := r.db.First(&user, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return toDomainUser(&user), nil
+}
+
+// GetUsersByIDs returns the users among ids that exist, in no particular
+// order.
+func (r *userRepository) GetUsersByIDs(ids []string) (*[]domain.User, error) {
+	var users []User
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.User, 0, len(users))
+	for i := range users {
+		result = append(result, *toDomainUser(&users[i]))
+	}
+	return &result, nil
+}
+
+func (r *userRepository) CreateUserWithInitialScores(ctx context.Context, username, email, passwordHash string) (*domain.User, error) {
 	newUser := &User{
 		Username:     username,
+		Email:        nullableEmail(email),
 		PasswordHash: passwordHash,
+		Role:         domain.RolePlayer,
 	}
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createUserWithScores(tx, newUser)
+	})
+
+	if err != nil {
+		return nil, duplicateUserError(r.db, err, newUser.Email)
+	}
+
+	return toDomainUser(newUser), nil
+}
+
+// CreateAdmin creates a user with the admin role. Admins don't play, so no
+// initial scores are created.
+func (r *userRepository) CreateAdmin(username, passwordHash string, mustChangePassword bool) (*domain.User, error) {
+	admin := &User{
+		Username:           username,
+		PasswordHash:       passwordHash,
+		Role:               domain.RoleAdmin,
+		MustChangePassword: mustChangePassword,
+	}
+	if err := r.db.Create(admin).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, domain.ErrUsernameAlreadyExists
+		}
+		return nil, err
+	}
+	return toDomainUser(admin), nil
+}
+
+func (r *userRepository) SetUserRole(userID, role string) error {
+	res := r.db.Model(&User{}).Where("id = ?", userID).Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) CountUsersByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+// UpdatePassword stores a new password hash. mustChange forces the user to
+// pick another password on next login, for passwords handed out by others.
+func (r *userRepository) UpdatePassword(userID, passwordHash string, mustChange bool) error {
+	res := r.db.Model(&User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":        passwordHash,
+		"must_change_password": mustChange,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
 
-		if err := tx.Create(newUser).Error; err != nil {
-			if errors.Is(err, gorm.ErrDuplicatedKey) {
-				return domain.ErrUsernameAlreadyExists
+// RenameUser changes the username and records the old one in the history,
+// in one transaction.
+func (r *userRepository) RenameUser(userID, username string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrUserNotFound
 			}
 			return err
 		}
 
-		var games []Game
-		if err := tx.Find(&games).Error; err != nil {
+		if err := tx.Create(&UsernameChange{
+			UserID:    userID,
+			Username:  user.Username,
+			ChangedAt: time.Now(),
+		}).Error; err != nil {
 			return err
 		}
-
-		var scores []Score
-		for _, game := range games {
-			scores = append(scores, Score{
-				GameID: game.ID,
-				UserID: newUser.ID,
-				Points: 0,
-			})
+		if err := tx.Model(&user).Update("username", username).Error; err != nil {
+			return err
 		}
+		return touchUserGames(tx, userID)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrUsernameAlreadyExists
+	}
+	return err
+}
 
-		if len(scores) > 0 {
-			if err := tx.Create(&scores).Error; err != nil {
-				return err
-			}
+// ListUsernameHistory returns the usernames the user gave up, newest first.
+func (r *userRepository) ListUsernameHistory(userID string) (*[]domain.UsernameChange, error) {
+	var models []UsernameChange
+	if err := r.db.Where("user_id = ?", userID).Order("changed_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	changes := make([]domain.UsernameChange, 0, len(models))
+	for _, m := range models {
+		changes = append(changes, toDomainUsernameChange(m))
+	}
+	return &changes, nil
+}
+
+// IsUsernameHeld reports whether a user other than userID gave up username,
+// in any case, after since. An empty userID checks every user.
+func (r *userRepository) IsUsernameHeld(username, userID string, since time.Time) (bool, error) {
+	query := r.db.Model(&UsernameChange{}).Where("lower(username) = lower(?) AND changed_at > ?", username, since)
+	if userID != "" {
+		query = query.Where("user_id <> ?", userID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateProfile applies the non-nil fields of update and returns the user as
+// stored afterwards.
+func (r *userRepository) UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error) {
+	changes := map[string]interface{}{}
+	if update.DisplayName != nil {
+		changes["display_name"] = *update.DisplayName
+	}
+	if update.AvatarURL != nil {
+		changes["avatar_url"] = *update.AvatarURL
+	}
+	if update.Country != nil {
+		changes["country"] = *update.Country
+	}
+	if update.Bio != nil {
+		changes["bio"] = *update.Bio
+	}
+
+	if len(changes) > 0 {
+		res := r.db.Model(&User{}).Where("id = ?", userID).Updates(changes)
+		if res.Error != nil {
+			return nil, res.Error
 		}
-		return nil
-	})
+		if res.RowsAffected == 0 {
+			return nil, domain.ErrUserNotFound
+		}
+	}
+	return r.GetUserByID(userID)
+}
+
+// ListUsers returns one page of users ordered by username, along with the
+// number of users matching the search.
+func (r *userRepository) ListUsers(query *domain.UserQuery) (*[]domain.User, int64, error) {
+	tx := r.db.Model(&User{})
+	if query.Search != "" {
+		pattern := "%" + likeEscaper.Replace(query.Search) + "%"
+		tx = tx.Where("username ILIKE ? OR display_name ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
+	var users []User
+	err := tx.Order("username").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&users).Error
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	result := make([]domain.User, 0, len(users))
+	for i := range users {
+		result = append(result, *toDomainUser(&users[i]))
 	}
+	return &result, total, nil
+}
+
+// likeEscaper keeps LIKE wildcards typed by the caller from matching anything.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// createUserWithScores inserts user and a zero score for every game.
+func createUserWithScores(tx *gorm.DB, user *User) error {
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+
+	var games []Game
+	if err := tx.Find(&games).Error; err != nil {
+		return err
+	}
+
+	var scores []Score
+	for _, game := range games {
+		scores = append(scores, Score{
+			GameID: game.ID,
+			UserID: user.ID,
+			Points: 0,
+		})
+	}
+
+	if len(scores) > 0 {
+		if err := tx.Create(&scores).Error; err != nil {
+			return err
+		}
+	}
+	return touchUserGames(tx, user.ID)
+}
+
+// duplicateUserError tells which unique field made creating a user fail. It
+// needs db rather than the failed transaction, which Postgres no longer
+// answers queries in.
+func duplicateUserError(db *gorm.DB, err error, email *string) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	var count int64
+	if email != nil {
+		db.Model(&User{}).Where("email = ?", *email).Count(&count)
+	}
+	if count > 0 {
+		return domain.ErrEmailAlreadyExists
+	}
+	return domain.ErrUsernameAlreadyExists
+}
 
+// nullableEmail stores emails lowercased and a missing one as NULL, so the
+// unique index only applies to users that have one.
+func nullableEmail(email string) *string {
+	if email == "" {
+		return nil
+	}
+	lower := strings.ToLower(email)
+	return &lower
+}
+
+func toDomainUser(user *User) *domain.User {
+	var email string
+	if user.Email != nil {
+		email = *user.Email
+	}
 	return &domain.User{
-		ID:       newUser.ID,
-		Username: newUser.Username,
-	}, nil
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              email,
+		Role:               user.Role,
+		DisplayName:        user.DisplayName,
+		AvatarURL:          user.AvatarURL,
+		Country:            user.Country,
+		Bio:                user.Bio,
+		MustChangePassword: user.MustChangePassword,
+		IsGuest:            user.IsGuest,
+		Status:             toAccountStatus(user),
+		CreatedAt:          user.CreatedAt,
+	}
+}
+
+func toDomainUsernameChange(m UsernameChange) domain.UsernameChange {
+	return domain.UsernameChange{
+		UserID:    m.UserID,
+		Username:  m.Username,
+		ChangedAt: m.ChangedAt,
+	}
+}
+
+func toAccountStatus(user *User) domain.AccountStatus {
+	state := user.Status
+	if state == "" {
+		state = domain.StatusActive
+	}
+	return domain.AccountStatus{
+		State:          state,
+		Reason:         user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+
+		SessionsRevokedAt: user.SessionsRevokedAt,
+	}
 }
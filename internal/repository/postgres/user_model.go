@@ -5,12 +5,32 @@ import (
 )
 
 type User struct {
-	ID           string `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
-	Username     string `gorm:"uniqueIndex;not null"`
-	PasswordHash string `gorm:"not null"`
-	IsAdmin      bool   `gorm:"default:false"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID           string  `gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	Username     string  `gorm:"uniqueIndex;not null"`
+	Email        *string `gorm:"uniqueIndex"`
+	PasswordHash string  `gorm:"not null"`
+	Role         string  `gorm:"not null;default:player;index"`
+
+	DisplayName string `gorm:"size:50"`
+	AvatarURL   string `gorm:"size:500"`
+	Country     string `gorm:"size:2"`
+	Bio         string `gorm:"size:500"`
+
+	MustChangePassword bool `gorm:"not null;default:false"`
+
+	IsGuest bool `gorm:"not null;default:false"`
+	// DeviceHash is the SHA-256 of the device identifier of a guest.
+	DeviceHash *string `gorm:"uniqueIndex"`
+
+	Status         string `gorm:"not null;default:active;index"`
+	StatusReason   string `gorm:"size:500"`
+	SuspendedUntil *time.Time
+	// SessionsRevokedAt is set whenever every session of the user is ended.
+	SessionsRevokedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 	//FK
-	Scores []Score `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Scores  []Score `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	RoleRef Role    `gorm:"foreignKey:Role;references:Name"`
 }
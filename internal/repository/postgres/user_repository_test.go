@@ -3,9 +3,14 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
 
 	repository "github.com/Martin-Arias/go-scoring-api/internal/repository/postgres"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUserRepository_CreateAndFetch(t *testing.T) {
@@ -16,10 +21,116 @@ func TestUserRepository_CreateAndFetch(t *testing.T) {
 	db := repository.SetupTestDB(t)
 	repo := repository.NewUserRepository(db)
 
-	_, err := repo.CreateUserWithInitialScores(context.Background(), "martin", "pass123")
+	_, err := repo.CreateUserWithInitialScores(context.Background(), "martin", "", "pass123")
 	assert.NoError(t, err)
 
 	user, err := repo.GetUserByUsername("martin")
 	assert.NoError(t, err)
 	assert.Equal(t, "martin", user.Username)
 }
+
+func TestUserRepository_ProfileAndList(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+
+	user, err := repo.CreateUserWithInitialScores(context.Background(), "martin", "", "pass123")
+	assert.NoError(t, err)
+	_, err = repo.CreateUserWithInitialScores(context.Background(), "lucia", "", "pass123")
+	assert.NoError(t, err)
+
+	name := "Martín_A"
+	updated, err := repo.UpdateProfile(user.ID, &domain.ProfileUpdate{DisplayName: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, "Martín_A", updated.DisplayName)
+
+	users, total, err := repo.ListUsers(&domain.UserQuery{Search: "n_a", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "martin", (*users)[0].Username)
+
+	users, total, err = repo.ListUsers(&domain.UserQuery{Page: 2, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "martin", (*users)[0].Username)
+}
+
+func TestUserRepository_UsernamesIgnoreCase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+
+	_, err := repo.CreateUserWithInitialScores(context.Background(), "Martin", "", "pass123")
+	assert.NoError(t, err)
+
+	user, err := repo.GetUserByUsername("mARTIN")
+	assert.NoError(t, err)
+	assert.Equal(t, "Martin", user.Username)
+
+	_, err = repo.CreateUserWithInitialScores(context.Background(), "martin", "", "pass123")
+	assert.ErrorIs(t, err, domain.ErrUsernameAlreadyExists)
+}
+
+func TestUserRepository_RenameKeepsHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+
+	user, err := repo.CreateUserWithInitialScores(context.Background(), "martin", "", "pass123")
+	assert.NoError(t, err)
+	other, err := repo.CreateUserWithInitialScores(context.Background(), "lucia", "", "pass123")
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.RenameUser(user.ID, "martin_a"))
+	assert.ErrorIs(t, repo.RenameUser(user.ID, "LUCIA"), domain.ErrUsernameAlreadyExists)
+
+	history, err := repo.ListUsernameHistory(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, *history, 1)
+	assert.Equal(t, "martin", (*history)[0].Username)
+
+	since := time.Now().Add(-time.Hour)
+	held, err := repo.IsUsernameHeld("MARTIN", other.ID, since)
+	assert.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = repo.IsUsernameHeld("martin", user.ID, since)
+	assert.NoError(t, err)
+	assert.False(t, held)
+
+	held, err = repo.IsUsernameHeld("martin", "", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, held)
+}
+
+func TestMigrations_LockDefaultAdminPassword(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := repository.SetupTestDB(t)
+	repo := repository.NewUserRepository(db)
+
+	// Older versions created the admin with this password and no forced change.
+	hash, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
+	assert.NoError(t, err)
+	_, err = repo.CreateAdmin("admin", string(hash), false)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repository.RunMigrations(db))
+
+	admin, err := repo.GetUserCreds("admin")
+	assert.NoError(t, err)
+	assert.False(t, hasher.New().Verify(admin.PasswordHash, "admin123"))
+	assert.NotEmpty(t, admin.PasswordHash)
+	assert.True(t, admin.MustChangePassword)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"time"
+)
+
+// UsernameChange keeps a username a user gave up, so it stays held for a
+// while. Lookups go through lower(username).
+type UsernameChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	Username  string    `gorm:"not null"`
+	ChangedAt time.Time `gorm:"not null;index"`
+
+	//FK
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
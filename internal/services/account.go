@@ -0,0 +1,106 @@
+package services
+
+import (
+	"os"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+// deletedUsernamePrefix starts the username of anonymized accounts.
+const deletedUsernamePrefix = "deleted-"
+
+type accountService struct {
+	ar   ports.AccountRepository
+	ur   ports.UserRepository
+	ts   ports.TokenService
+	ls   ports.LockoutService
+	ph   ports.PasswordHasher
+	mode string
+}
+
+func NewAccountService(ar ports.AccountRepository, ur ports.UserRepository, ts ports.TokenService, ls ports.LockoutService, ph ports.PasswordHasher) ports.AccountService {
+	return &accountService{
+		ar:   ar,
+		ur:   ur,
+		ts:   ts,
+		ls:   ls,
+		ph:   ph,
+		mode: deletionMode(),
+	}
+}
+
+func (as *accountService) Export(userID string) (*domain.UserExport, error) {
+	export, err := as.ar.ExportUserData(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to export user data")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID).Msg("user data exported")
+	return export, nil
+}
+
+// DeleteAccount removes the account behind claims once the password is
+// confirmed. Accounts created through an identity provider have no password
+// to confirm. Depending on ACCOUNT_DELETION_MODE the user is anonymized or
+// deleted outright; either way the presented access token stops working.
+func (as *accountService) DeleteAccount(claims *domain.AccessClaims, password string) error {
+	creds, err := as.ur.GetUserCredsByID(claims.UserID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", claims.UserID).Msg("error fetching user credentials")
+		return err
+	}
+
+	if creds.PasswordHash != "" && !as.ph.Verify(creds.PasswordHash, password) {
+		log.Warn().Str("user_id", claims.UserID).Msg("account deletion with wrong password")
+		return domain.ErrCurrentPasswordInvalid
+	}
+
+	if creds.Role == domain.RoleAdmin {
+		admins, err := as.ur.CountUsersByRole(domain.RoleAdmin)
+		if err != nil {
+			log.Error().Err(err).Msg("error counting admins")
+			return err
+		}
+		if admins <= 1 {
+			log.Warn().Str("user_id", claims.UserID).Msg("refusing to delete the last admin")
+			return domain.ErrLastAdmin
+		}
+	}
+
+	// Failed login counters are keyed by username, which is about to go away.
+	if err := as.ls.Unlock(claims.UserID); err != nil {
+		log.Warn().Err(err).Str("user_id", claims.UserID).Msg("failed to clear login attempts of deleted account")
+	}
+
+	if as.mode == domain.DeletionHard {
+		err = as.ar.DeleteUser(claims.UserID)
+	} else {
+		err = as.ar.AnonymizeUser(claims.UserID, deletedUsernamePrefix+claims.UserID)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", claims.UserID).Str("mode", as.mode).Msg("failed to delete account")
+		return err
+	}
+
+	if err := as.ts.Logout(claims, ""); err != nil {
+		log.Warn().Err(err).Str("user_id", claims.UserID).Msg("failed to revoke access token of deleted account")
+	}
+
+	log.Info().Str("user_id", claims.UserID).Str("mode", as.mode).Msg("account deleted")
+	return nil
+}
+
+func deletionMode() string {
+	switch mode := os.Getenv("ACCOUNT_DELETION_MODE"); mode {
+	case "", domain.DeletionAnonymize:
+		return domain.DeletionAnonymize
+	case domain.DeletionHard:
+		return domain.DeletionHard
+	default:
+		log.Warn().Str("mode", mode).Msg("unknown ACCOUNT_DELETION_MODE, anonymizing accounts")
+		return domain.DeletionAnonymize
+	}
+}
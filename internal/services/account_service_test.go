@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var deleteClaims = &domain.AccessClaims{TokenID: "jti1", UserID: "user1", ExpiresAt: time.Now().Add(time.Minute)}
+
+type accountFixture struct {
+	ar *mocks.AccountRepositoryMock
+	ur *mocks.UserRepositoryMock
+	tr *mocks.TokenRepositoryMock
+	lr *mocks.LoginAttemptRepositoryMock
+	as ports.AccountService
+}
+
+func newAccountFixture(role string) *accountFixture {
+	f := &accountFixture{
+		ar: new(mocks.AccountRepositoryMock),
+		ur: new(mocks.UserRepositoryMock),
+		tr: new(mocks.TokenRepositoryMock),
+		lr: new(mocks.LoginAttemptRepositoryMock),
+	}
+	ts := services.NewTokenService(f.tr, f.ur, new(mocks.RoleRepositoryMock), newKeyService())
+	ls := services.NewLockoutService(f.lr, f.ur)
+	f.as = services.NewAccountService(f.ar, f.ur, ts, ls, newHasher())
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	f.ur.On("GetUserCredsByID", "user1").Return(&auth.AuthUserData{ID: "user1", Username: "test", PasswordHash: string(hash), Role: role}, nil)
+	f.ur.On("GetUserByID", "user1").Return(&domain.User{ID: "user1", Username: "test", Role: role}, nil)
+	f.lr.On("ClearLoginAttempts", "user:test").Return(nil)
+	f.tr.On("RevokeAccessToken", "jti1", deleteClaims.ExpiresAt).Return(nil)
+	return f
+}
+
+func TestDeleteAccount_AnonymizesByDefault(t *testing.T) {
+	f := newAccountFixture(domain.RolePlayer)
+	f.ar.On("AnonymizeUser", "user1", "deleted-user1").Return(nil)
+
+	err := f.as.DeleteAccount(deleteClaims, "secret")
+	assert.NoError(t, err)
+
+	f.ar.AssertExpectations(t)
+	f.ar.AssertNotCalled(t, "DeleteUser", mock.Anything)
+	f.lr.AssertExpectations(t)
+	f.tr.AssertExpectations(t)
+}
+
+func TestDeleteAccount_HardDelete(t *testing.T) {
+	t.Setenv("ACCOUNT_DELETION_MODE", domain.DeletionHard)
+	f := newAccountFixture(domain.RolePlayer)
+	f.ar.On("DeleteUser", "user1").Return(nil)
+
+	err := f.as.DeleteAccount(deleteClaims, "secret")
+	assert.NoError(t, err)
+
+	f.ar.AssertExpectations(t)
+	f.ar.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount_WrongPassword(t *testing.T) {
+	f := newAccountFixture(domain.RolePlayer)
+
+	err := f.as.DeleteAccount(deleteClaims, "wrong")
+	assert.ErrorIs(t, err, domain.ErrCurrentPasswordInvalid)
+	f.ar.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
+
+func TestDeleteAccount_LastAdmin(t *testing.T) {
+	f := newAccountFixture(domain.RoleAdmin)
+	f.ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(1), nil)
+
+	err := f.as.DeleteAccount(deleteClaims, "secret")
+	assert.ErrorIs(t, err, domain.ErrLastAdmin)
+	f.ar.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+}
+
+func TestExport(t *testing.T) {
+	f := newAccountFixture(domain.RolePlayer)
+	export := &domain.UserExport{User: *validUser, Scores: []domain.Score{{GameID: "game1", Points: 10}}}
+	f.ar.On("ExportUserData", "user1").Return(export, nil)
+
+	got, err := f.as.Export("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, export, got)
+}
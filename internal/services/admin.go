@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultAdminUsername = "admin"
+
+type adminService struct {
+	ur ports.UserRepository
+	ts ports.TokenService
+	ph ports.PasswordHasher
+}
+
+// NewAdminService builds the service behind the admin bootstrap and CLI.
+// Passwords set here skip the password policy: they have to be changed on
+// first login, and the change is checked against it.
+func NewAdminService(ur ports.UserRepository, ts ports.TokenService, ph ports.PasswordHasher) ports.AdminService {
+	return &adminService{
+		ur: ur,
+		ts: ts,
+		ph: ph,
+	}
+}
+
+// EnsureAdmin creates the first admin when there is none, named after
+// ADMIN_USERNAME and with ADMIN_PASSWORD as password. Without a configured
+// password one is generated and returned, so it can be shown once. It
+// returns a nil user when an admin already exists, including one another
+// instance created concurrently.
+func (as *adminService) EnsureAdmin() (*domain.User, string, error) {
+	count, err := as.ur.CountUsersByRole(domain.RoleAdmin)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to count admins")
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", nil
+	}
+
+	username := os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		username = defaultAdminUsername
+	}
+	admin, password, err := as.CreateAdmin(username, os.Getenv("ADMIN_PASSWORD"))
+	if errors.Is(err, domain.ErrUsernameAlreadyExists) {
+		// Replicas starting together race to create the admin. The username
+		// may also belong to a user who is not an admin, which has to fail.
+		count, countErr := as.ur.CountUsersByRole(domain.RoleAdmin)
+		if countErr == nil && count > 0 {
+			log.Info().Str("username", username).Msg("admin created by another instance")
+			return nil, "", nil
+		}
+	}
+	return admin, password, err
+}
+
+// CreateAdmin creates an admin that has to change its password on first
+// login. An empty password is replaced by a generated one, which is returned.
+func (as *adminService) CreateAdmin(username, password string) (*domain.User, string, error) {
+	var generated string
+	if password == "" {
+		var err error
+		if generated, err = generatePassword(); err != nil {
+			log.Error().Err(err).Msg("failed to generate admin password")
+			return nil, "", err
+		}
+		password = generated
+	}
+
+	hash, err := as.ph.Hash(password)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to hash password")
+		return nil, "", err
+	}
+
+	admin, err := as.ur.CreateAdmin(username, hash, true)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to create admin")
+		return nil, "", err
+	}
+
+	log.Info().Str("user_id", admin.ID).Str("username", username).Msg("admin created")
+	return admin, generated, nil
+}
+
+// RecoverAdmin gives an existing user the admin role and a new generated
+// password, which has to be changed on first login. Every session of the
+// user is ended.
+func (as *adminService) RecoverAdmin(username string) (string, error) {
+	user, err := as.ur.GetUserByUsername(username)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to fetch user")
+		return "", err
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate admin password")
+		return "", err
+	}
+
+	hash, err := as.ph.Hash(password)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to hash password")
+		return "", err
+	}
+
+	if err := as.ur.UpdatePassword(user.ID, hash, true); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to update password")
+		return "", err
+	}
+
+	if !user.IsAdmin() {
+		if err := as.ur.SetUserRole(user.ID, domain.RoleAdmin); err != nil {
+			log.Error().Err(err).Str("user_id", user.ID).Msg("failed to promote user to admin")
+			return "", err
+		}
+	}
+
+	if err := as.ts.RevokeUserSessions(user.ID); err != nil {
+		return "", err
+	}
+
+	log.Info().Str("user_id", user.ID).Str("username", username).Msg("admin recovered")
+	return password, nil
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
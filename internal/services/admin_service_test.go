@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEnsureAdmin_GeneratesPassword(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "")
+	t.Setenv("ADMIN_PASSWORD", "")
+
+	ur := new(mocks.UserRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	var storedHash string
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(0), nil)
+	ur.On("CreateAdmin", "admin", mock.AnythingOfType("string"), true).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+	}).Return(&domain.User{ID: "admin1", Username: "admin", Role: domain.RoleAdmin, MustChangePassword: true}, nil)
+
+	admin, password, err := as.EnsureAdmin()
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", admin.Username)
+	assert.NotEmpty(t, password)
+	assert.NotEqual(t, "admin123", password)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(password)))
+}
+
+func TestEnsureAdmin_FromConfig(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "root")
+	t.Setenv("ADMIN_PASSWORD", "configured-secret")
+
+	ur := new(mocks.UserRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(0), nil)
+	ur.On("CreateAdmin", "root", mock.MatchedBy(func(h string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(h), []byte("configured-secret")) == nil
+	}), true).Return(&domain.User{ID: "admin1", Username: "root", Role: domain.RoleAdmin}, nil)
+
+	admin, password, err := as.EnsureAdmin()
+	assert.NoError(t, err)
+	assert.Equal(t, "root", admin.Username)
+	assert.Empty(t, password, "configured passwords are not echoed back")
+	ur.AssertExpectations(t)
+}
+
+func TestEnsureAdmin_AdminExists(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(1), nil)
+
+	admin, password, err := as.EnsureAdmin()
+	assert.NoError(t, err)
+	assert.Nil(t, admin)
+	assert.Empty(t, password)
+	ur.AssertNotCalled(t, "CreateAdmin", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecoverAdmin(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	ur.On("GetUserByUsername", "test").Return(validUser, nil)
+	ur.On("UpdatePassword", "user1", mock.AnythingOfType("string"), true).Return(nil)
+	ur.On("SetUserRole", "user1", domain.RoleAdmin).Return(nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+
+	password, err := as.RecoverAdmin("test")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, password)
+
+	ur.AssertExpectations(t)
+	tr.AssertExpectations(t)
+}
+
+func TestEnsureAdmin_CreatedConcurrently(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "")
+	t.Setenv("ADMIN_PASSWORD", "")
+
+	ur := new(mocks.UserRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(0), nil).Once()
+	ur.On("CreateAdmin", "admin", mock.AnythingOfType("string"), true).Return(nil, domain.ErrUsernameAlreadyExists)
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(1), nil).Once()
+
+	admin, password, err := as.EnsureAdmin()
+	assert.NoError(t, err)
+	assert.Nil(t, admin)
+	assert.Empty(t, password)
+}
+
+func TestEnsureAdmin_UsernameTakenByPlayer(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "")
+	t.Setenv("ADMIN_PASSWORD", "")
+
+	ur := new(mocks.UserRepositoryMock)
+	as := services.NewAdminService(ur, services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService()), newHasher())
+
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(0), nil)
+	ur.On("CreateAdmin", "admin", mock.AnythingOfType("string"), true).Return(nil, domain.ErrUsernameAlreadyExists)
+
+	_, _, err := as.EnsureAdmin()
+	assert.ErrorIs(t, err, domain.ErrUsernameAlreadyExists)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	apiKeyPrefix = "gsk_"
+	// apiKeyDisplayLength is how much of a key is kept in clear to tell keys apart.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval limits how often LastUsedAt is written for busy keys.
+	apiKeyTouchInterval = time.Minute
+)
+
+type apiKeyService struct {
+	kr ports.APIKeyRepository
+	gr ports.GameRepository
+}
+
+// userOnlyScopes can't be given to API keys: keys may not mint other keys,
+// nor import users over existing accounts.
+var userOnlyScopes = []domain.Permission{domain.PermAPIKeys, domain.PermDataImport}
+
+func NewAPIKeyService(kr ports.APIKeyRepository, gr ports.GameRepository) ports.APIKeyService {
+	return &apiKeyService{
+		kr: kr,
+		gr: gr,
+	}
+}
+
+// CreateAPIKey stores a new key and returns it along with the raw key value,
+// which is not recoverable afterwards. Games may be referenced by ID or slug.
+func (ks *apiKeyService) CreateAPIKey(key *domain.APIKey, gameRefs []string) (*domain.APIKey, string, error) {
+	for _, scope := range key.Scopes {
+		if domain.HasPermission(userOnlyScopes, scope) || !domain.HasPermission(domain.AllPermissions, scope) {
+			log.Warn().Str("scope", string(scope)).Msg("invalid api key scope")
+			return nil, "", domain.ErrInvalidScope
+		}
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return nil, "", domain.ErrInvalidExpiry
+	}
+
+	key.GameIDs = nil
+	for _, ref := range gameRefs {
+		game, err := findGame(ks.gr, ref)
+		if err != nil {
+			log.Warn().Err(err).Str("game_id", ref).Msg("error fetching game for api key")
+			return nil, "", err
+		}
+		key.GameIDs = append(key.GameIDs, game.ID)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		log.Error().Err(err).Msg("failed to generate api key")
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	key.Prefix = rawKey[:apiKeyDisplayLength]
+
+	if err := ks.kr.CreateAPIKey(key, hashToken(rawKey)); err != nil {
+		log.Error().Err(err).Str("name", key.Name).Msg("failed to create api key")
+		return nil, "", err
+	}
+
+	log.Info().Str("api_key_id", key.ID).Str("name", key.Name).Str("created_by", key.CreatedBy).Msg("api key created")
+	return key, rawKey, nil
+}
+
+func (ks *apiKeyService) ListAPIKeys() (*[]domain.APIKey, error) {
+	keys, err := ks.kr.ListAPIKeys()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to retrieve api keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (ks *apiKeyService) RevokeAPIKey(id string) error {
+	if err := ks.kr.RevokeAPIKey(id); err != nil {
+		log.Warn().Err(err).Str("api_key_id", id).Msg("failed to revoke api key")
+		return err
+	}
+
+	log.Info().Str("api_key_id", id).Msg("api key revoked")
+	return nil
+}
+
+// Authenticate checks a raw key sent by a client and records its use.
+func (ks *apiKeyService) Authenticate(rawKey string) (*domain.APIKey, error) {
+	key, err := ks.kr.GetAPIKeyByHash(hashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrAPIKeyInvalid
+		}
+		log.Error().Err(err).Msg("error fetching api key")
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		log.Info().Str("api_key_id", key.ID).Msg("revoked or expired api key presented")
+		return nil, domain.ErrAPIKeyInvalid
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := ks.kr.TouchAPIKey(key.ID, now); err != nil {
+			// Losing a usage timestamp is not worth failing the request.
+			log.Warn().Err(err).Str("api_key_id", key.ID).Msg("failed to record api key use")
+		}
+		key.LastUsedAt = &now
+	}
+
+	// Keys created before a scope was reserved to users lose it.
+	scopes := key.Scopes[:0:0]
+	for _, scope := range key.Scopes {
+		if !domain.HasPermission(userOnlyScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	key.Scopes = scopes
+
+	return key, nil
+}
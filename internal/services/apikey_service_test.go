@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+	ks := services.NewAPIKeyService(kr, gr)
+
+	gr.On("GetGameBySlug", "testgame").Return(validGame, nil)
+	kr.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	key, rawKey, err := ks.CreateAPIKey(&domain.APIKey{
+		Name:   "leaderboard-bot",
+		Scopes: []domain.Permission{domain.PermScoresSubmit},
+	}, []string{"testgame"})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawKey, "gsk_"))
+	assert.True(t, strings.HasPrefix(rawKey, key.Prefix))
+	assert.Equal(t, []string{gameID}, key.GameIDs)
+
+	// Only the hash of the key is stored.
+	hash := kr.Calls[0].Arguments.String(1)
+	assert.NotEqual(t, rawKey, hash)
+	assert.NotContains(t, hash, rawKey)
+}
+
+func TestCreateAPIKey_InvalidScope(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+	for _, scope := range []domain.Permission{"scores:delete", domain.PermAPIKeys, domain.PermDataImport} {
+		_, _, err := ks.CreateAPIKey(&domain.APIKey{Name: "bot", Scopes: []domain.Permission{scope}}, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+	}
+	kr.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey_ExpiryInThePast(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err := ks.CreateAPIKey(&domain.APIKey{
+		Name:      "bot",
+		Scopes:    []domain.Permission{domain.PermScoresRead},
+		ExpiresAt: &past,
+	}, nil)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidExpiry)
+	kr.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+	key := &domain.APIKey{ID: "key1", Name: "bot", Scopes: []domain.Permission{domain.PermScoresRead}}
+	kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+	kr.On("TouchAPIKey", "key1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	got, err := ks.Authenticate("gsk_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", got.ID)
+	assert.NotNil(t, got.LastUsedAt)
+	kr.AssertExpectations(t)
+}
+
+func TestAuthenticateAPIKey_RecentlyUsed(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+	lastUsed := time.Now().Add(-10 * time.Second)
+	key := &domain.APIKey{ID: "key1", LastUsedAt: &lastUsed}
+	kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+
+	_, err := ks.Authenticate("gsk_secret")
+	assert.NoError(t, err)
+	kr.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateAPIKey_Rejected(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := map[string]*domain.APIKey{
+		"revoked": {ID: "key1", RevokedAt: &past},
+		"expired": {ID: "key1", ExpiresAt: &past},
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			kr := new(mocks.APIKeyRepositoryMock)
+			ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+			kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+
+			_, err := ks.Authenticate("gsk_secret")
+			assert.ErrorIs(t, err, domain.ErrAPIKeyInvalid)
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		kr := new(mocks.APIKeyRepositoryMock)
+		ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+		kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(nil, domain.ErrAPIKeyNotFound)
+
+		_, err := ks.Authenticate("gsk_unknown")
+		assert.ErrorIs(t, err, domain.ErrAPIKeyInvalid)
+	})
+}
+
+func TestAuthenticateAPIKey_DropsUserOnlyScopes(t *testing.T) {
+	kr := new(mocks.APIKeyRepositoryMock)
+	ks := services.NewAPIKeyService(kr, new(mocks.GameRepositoryMock))
+
+	// Created before data:import was reserved to users.
+	key := &domain.APIKey{ID: "key1", Scopes: []domain.Permission{domain.PermScoresRead, domain.PermDataImport}}
+	kr.On("GetAPIKeyByHash", mock.AnythingOfType("string")).Return(key, nil)
+	kr.On("TouchAPIKey", "key1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	got, err := ks.Authenticate("gsk_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Permission{domain.PermScoresRead}, got.Scopes)
+}
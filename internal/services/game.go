@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
@@ -18,8 +22,21 @@ func NewGameService(gr ports.GameRepository) ports.GameService {
 	}
 }
 
-func (gs *gameService) CreateGame(gameName string) (*domain.Game, error) {
-	game, err := gs.gr.CreateGameWithInitialScores(context.Background(), gameName)
+// CreateGame creates a game under the given slug, or under one derived from
+// the game name when slug is empty.
+func (gs *gameService) CreateGame(gameName, slug string) (*domain.Game, error) {
+	var err error
+	if slug == "" {
+		slug, err = gs.availableSlug(gameName)
+	} else {
+		err = gs.checkSlug(slug)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("game_name", gameName).Str("slug", slug).Msg("invalid game slug")
+		return nil, err
+	}
+
+	game, err := gs.gr.CreateGameWithInitialScores(context.Background(), gameName, slug)
 	if err != nil {
 		log.Error().Err(err).Str("game_name", gameName).Msg("failed to create game")
 		return nil, err
@@ -37,3 +54,68 @@ func (gs *gameService) GetGames() (*[]domain.Game, error) {
 
 	return games, nil
 }
+
+// GetGame returns the game identified by ref, which may be its ID or its slug.
+func (gs *gameService) GetGame(ref string) (*domain.Game, error) {
+	return findGame(gs.gr, ref)
+}
+
+// GetGamesByIDs returns the games among ids that exist, for callers that
+// look up many games at once.
+func (gs *gameService) GetGamesByIDs(ids []string) (*[]domain.Game, error) {
+	games, err := gs.gr.GetGamesByIDs(ids)
+	if err != nil {
+		log.Error().Err(err).Int("games", len(ids)).Msg("error fetching games")
+		return nil, err
+	}
+	return games, nil
+}
+
+// checkSlug validates a slug chosen by the caller.
+func (gs *gameService) checkSlug(slug string) error {
+	if !utils.IsValidSlug(slug) || uuid.Validate(slug) == nil {
+		return domain.ErrInvalidGameSlug
+	}
+
+	_, err := gs.gr.GetGameBySlug(slug)
+	if err == nil {
+		return domain.ErrGameSlugAlreadyExists
+	}
+	if !errors.Is(err, domain.ErrGameNotFound) {
+		return err
+	}
+	return nil
+}
+
+// availableSlug derives a slug from the game name, appending a numeric
+// suffix until it no longer collides with an existing game.
+func (gs *gameService) availableSlug(gameName string) (string, error) {
+	base := utils.Slugify(gameName)
+	switch {
+	case base == "":
+		base = "game"
+	case uuid.Validate(base) == nil:
+		base = "game-" + base
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		_, err := gs.gr.GetGameBySlug(slug)
+		if errors.Is(err, domain.ErrGameNotFound) {
+			return slug, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// findGame resolves a game reference: UUIDs are looked up by ID and anything
+// else is treated as a slug.
+func findGame(gr ports.GameRepository, ref string) (*domain.Game, error) {
+	if uuid.Validate(ref) == nil {
+		return gr.GetGameByID(ref)
+	}
+	return gr.GetGameBySlug(ref)
+}
@@ -14,10 +14,11 @@ func TestCreateGame(t *testing.T) {
 	mockRepo := new(mocks.GameRepositoryMock)
 	service := services.NewGameService(mockRepo)
 
-	expected := &domain.Game{ID: "123", Name: "chess"}
-	mockRepo.On("CreateGameWithInitialScores", mock.Anything, "chess").Return(expected, nil)
+	expected := &domain.Game{ID: "123", Name: "chess", Slug: "chess"}
+	mockRepo.On("GetGameBySlug", "chess").Return(nil, domain.ErrGameNotFound)
+	mockRepo.On("CreateGameWithInitialScores", mock.Anything, "chess", "chess").Return(expected, nil)
 
-	game, err := service.CreateGame("chess")
+	game, err := service.CreateGame("chess", "")
 	assert.NoError(t, err)
 	assert.Equal(t, expected, game)
 
@@ -30,17 +31,94 @@ func TestCreateGame_Error(t *testing.T) {
 
 	var nilGame *domain.Game = nil
 
+	mockRepo.On("GetGameBySlug", "fail-game").Return(nil, domain.ErrGameNotFound)
 	mockRepo.
-		On("CreateGameWithInitialScores", mock.Anything, "fail-game").
+		On("CreateGameWithInitialScores", mock.Anything, "fail-game", "fail-game").
 		Return(nilGame, assert.AnError)
 
-	game, err := service.CreateGame("fail-game")
+	game, err := service.CreateGame("fail-game", "")
 
 	assert.Error(t, err)
 	assert.Nil(t, game)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateGame_DerivedSlugCollision(t *testing.T) {
+	mockRepo := new(mocks.GameRepositoryMock)
+	service := services.NewGameService(mockRepo)
+
+	expected := &domain.Game{ID: "123", Name: "Space Racer!", Slug: "space-racer-2"}
+	mockRepo.On("GetGameBySlug", "space-racer").Return(&domain.Game{ID: "99", Name: "Space Racer", Slug: "space-racer"}, nil)
+	mockRepo.On("GetGameBySlug", "space-racer-2").Return(nil, domain.ErrGameNotFound)
+	mockRepo.On("CreateGameWithInitialScores", mock.Anything, "Space Racer!", "space-racer-2").Return(expected, nil)
+
+	game, err := service.CreateGame("Space Racer!", "")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, game)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateGame_CustomSlug(t *testing.T) {
+	mockRepo := new(mocks.GameRepositoryMock)
+	service := services.NewGameService(mockRepo)
+
+	expected := &domain.Game{ID: "123", Name: "Space Racer", Slug: "racer"}
+	mockRepo.On("GetGameBySlug", "racer").Return(nil, domain.ErrGameNotFound)
+	mockRepo.On("CreateGameWithInitialScores", mock.Anything, "Space Racer", "racer").Return(expected, nil)
+
+	game, err := service.CreateGame("Space Racer", "racer")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, game)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateGame_CustomSlugTaken(t *testing.T) {
+	mockRepo := new(mocks.GameRepositoryMock)
+	service := services.NewGameService(mockRepo)
+
+	mockRepo.On("GetGameBySlug", "racer").Return(&domain.Game{ID: "99", Slug: "racer"}, nil)
+
+	game, err := service.CreateGame("Space Racer", "racer")
+	assert.ErrorIs(t, err, domain.ErrGameSlugAlreadyExists)
+	assert.Nil(t, game)
+
+	mockRepo.AssertNotCalled(t, "CreateGameWithInitialScores", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateGame_InvalidSlug(t *testing.T) {
+	mockRepo := new(mocks.GameRepositoryMock)
+	service := services.NewGameService(mockRepo)
+
+	for _, slug := range []string{"Space Racer", "8b7c1a0e-54a4-4f7c-9d3e-0c1b2a3d4e5f"} {
+		game, err := service.CreateGame("Space Racer", slug)
+		assert.ErrorIs(t, err, domain.ErrInvalidGameSlug)
+		assert.Nil(t, game)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetGameBySlug", mock.Anything)
+}
+
+func TestGetGame_ByIDOrSlug(t *testing.T) {
+	mockRepo := new(mocks.GameRepositoryMock)
+	service := services.NewGameService(mockRepo)
+
+	expected := &domain.Game{ID: "8b7c1a0e-54a4-4f7c-9d3e-0c1b2a3d4e5f", Name: "pong", Slug: "pong"}
+	mockRepo.On("GetGameByID", expected.ID).Return(expected, nil)
+	mockRepo.On("GetGameBySlug", "pong").Return(expected, nil)
+
+	byID, err := service.GetGame(expected.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, byID)
+
+	bySlug, err := service.GetGame("pong")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, bySlug)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetGames(t *testing.T) {
 	mockRepo := new(mocks.GameRepositoryMock)
 	service := services.NewGameService(mockRepo)
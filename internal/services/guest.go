@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+// guestUsernamePrefix starts the generated username of guests.
+const guestUsernamePrefix = "guest-"
+
+type guestService struct {
+	gr ports.GuestRepository
+	ur ports.UserRepository
+	sr ports.ScoreRepository
+	ts ports.TokenService
+	ph ports.PasswordHasher
+	pp ports.PasswordPolicy
+
+	names *usernameRules
+}
+
+func NewGuestService(gr ports.GuestRepository, ur ports.UserRepository, sr ports.ScoreRepository, ts ports.TokenService, ph ports.PasswordHasher, pp ports.PasswordPolicy) ports.GuestService {
+	return &guestService{
+		gr: gr,
+		ur: ur,
+		sr: sr,
+		ts: ts,
+		ph: ph,
+		pp: pp,
+
+		names: newUsernameRules(ur),
+	}
+}
+
+// LoginGuest signs in the guest of the device, creating it on the first
+// visit. The device identifier is the only credential of a guest and is
+// stored hashed, like refresh tokens.
+func (gs *guestService) LoginGuest(deviceID string) (*domain.TokenPair, error) {
+	username, err := guestUsername()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate guest username")
+		return nil, err
+	}
+
+	guest, err := gs.gr.GetOrCreateGuest(context.Background(), hashToken(deviceID), username)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch or create guest")
+		return nil, err
+	}
+
+	tokens, err := gs.ts.IssueTokens(guest)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", guest.ID).Msg("failed to issue guest tokens")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", guest.ID).Msg("guest logged in")
+	return tokens, nil
+}
+
+// Upgrade turns the guest into a registered account, keeping its ID and
+// scores.
+func (gs *guestService) Upgrade(userID, username, email, password string) (*domain.User, error) {
+	normalized, err := gs.names.normalize(username)
+	if err != nil {
+		log.Info().Err(err).Str("user_id", userID).Str("username", username).Msg("guest upgrade with an invalid username")
+		return nil, err
+	}
+	username = normalized
+
+	if err := gs.pp.Validate(password, username); err != nil {
+		log.Info().Err(err).Str("user_id", userID).Msg("guest upgrade with a password against the policy")
+		return nil, err
+	}
+
+	if err := gs.names.checkHeld(username, userID); err != nil {
+		return nil, err
+	}
+
+	hash, err := gs.ph.Hash(password)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to hash password")
+		return nil, err
+	}
+
+	user, err := gs.gr.UpgradeGuest(userID, username, email, hash)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("failed to upgrade guest")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID).Str("username", username).Msg("guest upgraded")
+	return user, nil
+}
+
+// Merge moves the scores of the device's guest into the registered player
+// userID and deletes the guest. It returns the scores of userID afterwards.
+func (gs *guestService) Merge(userID, deviceID string) (*[]domain.Score, error) {
+	user, err := gs.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	// Guests only merge into accounts that can sign in again, and admins
+	// never take part in games.
+	if user.IsGuest || user.IsAdmin() {
+		return nil, domain.ErrMergeTarget
+	}
+
+	guest, err := gs.gr.GetGuestByDevice(hashToken(deviceID))
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("guest to merge not found")
+		return nil, err
+	}
+
+	if err := gs.gr.MergeGuest(guest.ID, userID); err != nil {
+		log.Error().Err(err).Str("guest_id", guest.ID).Str("user_id", userID).Msg("failed to merge guest")
+		return nil, err
+	}
+	log.Info().Str("guest_id", guest.ID).Str("user_id", userID).Msg("guest merged")
+
+	scores, err := gs.sr.GetScoresByUserID(userID)
+	if errors.Is(err, domain.ErrScoreNotFound) {
+		return &[]domain.Score{}, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("error retrieving user scores")
+		return nil, err
+	}
+	return scores, nil
+}
+
+func guestUsername() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return guestUsernamePrefix + hex.EncodeToString(buf), nil
+}
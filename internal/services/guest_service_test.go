@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const deviceID = "device-0123456789abcdef"
+
+var guestUser = &domain.User{ID: "guest1", Username: "guest-0a1b2c3d4e5f", Role: domain.RolePlayer, IsGuest: true}
+
+func deviceHash(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func newGuestService(t *testing.T) (ports.GuestService, *mocks.GuestRepositoryMock, *mocks.UserRepositoryMock, *mocks.ScoreRepositoryMock, *mocks.TokenRepositoryMock, *mocks.RoleRepositoryMock) {
+	gr := new(mocks.GuestRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	sr := new(mocks.ScoreRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+	return services.NewGuestService(gr, ur, sr, ts, newHasher(), newPolicy(t)), gr, ur, sr, tr, rr
+}
+
+func TestLoginGuest(t *testing.T) {
+	gs, gr, _, _, tr, rr := newGuestService(t)
+
+	gr.On("GetOrCreateGuest", mock.Anything, deviceHash(deviceID), mock.MatchedBy(func(username string) bool {
+		return strings.HasPrefix(username, "guest-")
+	})).Return(guestUser, nil)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := gs.LoginGuest(deviceID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	gr.AssertExpectations(t)
+}
+
+func TestUpgradeGuest_PasswordPolicy(t *testing.T) {
+	gs, gr, _, _, _, _ := newGuestService(t)
+
+	_, err := gs.Upgrade("guest1", "martin", "", "short")
+	assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+	gr.AssertNotCalled(t, "UpgradeGuest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpgradeGuest(t *testing.T) {
+	gs, gr, ur, _, _, _ := newGuestService(t)
+
+	ur.On("IsUsernameHeld", "martin", "guest1", mock.Anything).Return(false, nil)
+	upgraded := &domain.User{ID: "guest1", Username: "martin", Role: domain.RolePlayer}
+	gr.On("UpgradeGuest", "guest1", "martin", "martin@example.com", mock.AnythingOfType("string")).Return(upgraded, nil)
+
+	user, err := gs.Upgrade("guest1", "martin", "martin@example.com", "s3cure-pass")
+	assert.NoError(t, err)
+	assert.False(t, user.IsGuest)
+}
+
+func TestMergeGuest(t *testing.T) {
+	gs, gr, ur, sr, _, _ := newGuestService(t)
+
+	merged := &[]domain.Score{{UserID: "user1", GameID: "game1", Points: 300}}
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	gr.On("GetGuestByDevice", deviceHash(deviceID)).Return(guestUser, nil)
+	gr.On("MergeGuest", "guest1", "user1").Return(nil)
+	sr.On("GetScoresByUserID", "user1").Return(merged, nil)
+
+	scores, err := gs.Merge("user1", deviceID)
+	assert.NoError(t, err)
+	assert.Equal(t, merged, scores)
+	gr.AssertExpectations(t)
+}
+
+func TestMergeGuest_IntoGuest(t *testing.T) {
+	gs, gr, ur, _, _, _ := newGuestService(t)
+
+	ur.On("GetUserByID", "guest2").Return(&domain.User{ID: "guest2", Role: domain.RolePlayer, IsGuest: true}, nil)
+
+	_, err := gs.Merge("guest2", deviceID)
+	assert.ErrorIs(t, err, domain.ErrMergeTarget)
+	gr.AssertNotCalled(t, "MergeGuest", mock.Anything, mock.Anything)
+}
+
+func TestMergeGuest_UnknownDevice(t *testing.T) {
+	gs, gr, ur, _, _, _ := newGuestService(t)
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	gr.On("GetGuestByDevice", deviceHash("unknown-device-id")).Return(nil, domain.ErrGuestNotFound)
+
+	_, err := gs.Merge("user1", "unknown-device-id")
+	assert.ErrorIs(t, err, domain.ErrGuestNotFound)
+	gr.AssertNotCalled(t, "MergeGuest", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencyLockTimeout = time.Minute
+)
+
+type idempotencyService struct {
+	ir ports.IdempotencyRepository
+	// ttl is how long a response is replayed.
+	ttl time.Duration
+	// lockTimeout is how long a request may run before a retry with the same
+	// key is let through, e.g. after the instance running it went down.
+	lockTimeout time.Duration
+}
+
+// NewIdempotencyService reads how long responses are kept from
+// IDEMPOTENCY_TTL and how long a request holds its key from
+// IDEMPOTENCY_LOCK_TIMEOUT.
+func NewIdempotencyService(ir ports.IdempotencyRepository) ports.IdempotencyService {
+	return &idempotencyService{
+		ir:          ir,
+		ttl:         utils.EnvDuration("IDEMPOTENCY_TTL", defaultIdempotencyTTL),
+		lockTimeout: utils.EnvDuration("IDEMPOTENCY_LOCK_TIMEOUT", defaultIdempotencyLockTimeout),
+	}
+}
+
+func (s *idempotencyService) Begin(scope, key, requestHash string) (*domain.IdempotencyRecord, error) {
+	now := time.Now()
+	held, err := s.ir.ClaimIdempotencyKey(&domain.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		LockedUntil: now.Add(s.lockTimeout),
+		ExpiresAt:   now.Add(s.ttl),
+	}, now)
+	if err != nil {
+		log.Error().Err(err).Str("scope", scope).Msg("failed to claim idempotency key")
+		return nil, err
+	}
+
+	switch {
+	case held == nil:
+		return nil, nil
+	case held.RequestHash != requestHash:
+		return nil, domain.ErrIdempotencyKeyReused
+	case !held.Completed():
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+	return held, nil
+}
+
+func (s *idempotencyService) Complete(record *domain.IdempotencyRecord) error {
+	if err := s.ir.CompleteIdempotencyKey(record); err != nil {
+		log.Error().Err(err).Str("scope", record.Scope).Msg("failed to store idempotent response")
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) Release(scope, key string) error {
+	if err := s.ir.ReleaseIdempotencyKey(scope, key); err != nil {
+		log.Error().Err(err).Str("scope", scope).Msg("failed to release idempotency key")
+		return err
+	}
+	return nil
+}
+
+func (s *idempotencyService) Purge() error {
+	return s.ir.DeleteExpiredIdempotencyKeys(time.Now())
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyBegin_Claimed(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_TTL", "1h")
+	ir := new(mocks.IdempotencyRepositoryMock)
+	is := services.NewIdempotencyService(ir)
+
+	ir.On("ClaimIdempotencyKey", mock.MatchedBy(func(r *domain.IdempotencyRecord) bool {
+		return r.Scope == "user:1 PUT /scores" && r.Key == "k1" && r.RequestHash == "h1" &&
+			time.Until(r.ExpiresAt) > 59*time.Minute && r.LockedUntil.Before(r.ExpiresAt)
+	}), mock.Anything).Return(nil, nil)
+
+	stored, err := is.Begin("user:1 PUT /scores", "k1", "h1")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	ir.AssertExpectations(t)
+}
+
+func TestIdempotencyBegin_Held(t *testing.T) {
+	cases := []struct {
+		held *domain.IdempotencyRecord
+		err  error
+	}{
+		{&domain.IdempotencyRecord{RequestHash: "h1", Status: 200, Body: []byte("{}")}, nil},
+		{&domain.IdempotencyRecord{RequestHash: "h2", Status: 200}, domain.ErrIdempotencyKeyReused},
+		{&domain.IdempotencyRecord{RequestHash: "h1"}, domain.ErrIdempotencyKeyInProgress},
+	}
+	for _, tc := range cases {
+		ir := new(mocks.IdempotencyRepositoryMock)
+		is := services.NewIdempotencyService(ir)
+		ir.On("ClaimIdempotencyKey", mock.Anything, mock.Anything).Return(tc.held, nil)
+
+		stored, err := is.Begin("scope", "k1", "h1")
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err)
+			assert.Nil(t, stored)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.held, stored)
+	}
+}
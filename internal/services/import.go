@@ -0,0 +1,426 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultImportBatchSize = 1000
+	// maxImportBatchSize keeps the parameters of one INSERT under the limit
+	// of Postgres.
+	maxImportBatchSize     = 10000
+	defaultImportMaxErrors = 10000
+	// maxImportLine is the longest NDJSON line accepted.
+	maxImportLine = 1 << 20
+)
+
+// importColumns are the columns an import file must have, per kind. A list
+// of alternatives needs one of them; the score columns match those of the
+// CSV export, so an export can be imported again.
+var importColumns = map[domain.ImportKind][][]string{
+	domain.ImportUsers:  {{"username"}},
+	domain.ImportGames:  {{"name"}},
+	domain.ImportScores: {{"user_id", "username"}, {"game_id", "game_slug"}, {"points"}},
+}
+
+type importService struct {
+	ir       ports.ImportRepository
+	ph       ports.PasswordHasher
+	names    *usernameRules
+	validate *validator.Validate
+	// batchSize is how many rows are written per transaction.
+	batchSize int
+	// maxErrors caps the rejected rows the report lists.
+	maxErrors int
+}
+
+// NewImportService reads the batch size from IMPORT_BATCH_SIZE and the
+// number of rejected rows reported from IMPORT_MAX_ERRORS.
+func NewImportService(ir ports.ImportRepository, ur ports.UserRepository, ph ports.PasswordHasher) ports.ImportService {
+	return &importService{
+		ir:        ir,
+		ph:        ph,
+		names:     newUsernameRules(ur),
+		validate:  validator.New(),
+		batchSize: min(utils.EnvInt("IMPORT_BATCH_SIZE", defaultImportBatchSize), maxImportBatchSize),
+		maxErrors: utils.EnvInt("IMPORT_MAX_ERRORS", defaultImportMaxErrors),
+	}
+}
+
+// Import reads file row by row and writes it in batches, each in its own
+// transaction. Invalid rows are rejected and listed in the report while the
+// rest go on. An error stops the import; the batches written before it stay.
+func (s *importService) Import(ctx context.Context, file io.Reader, opts domain.ImportOptions) (*domain.ImportReport, error) {
+	if opts.Conflict == "" {
+		opts.Conflict = domain.ConflictKeepBest
+	}
+	if err := checkImportOptions(opts); err != nil {
+		return nil, err
+	}
+
+	rows, err := newImportReader(file, opts.Format, importColumns[opts.Kind])
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{Kind: opts.Kind, Conflict: opts.Conflict, DryRun: opts.DryRun, Errors: []domain.ImportRowError{}}
+	switch opts.Kind {
+	case domain.ImportUsers:
+		err = runImport(ctx, s, rows, report, s.parseUser,
+			func(batch []domain.ImportUser) (*domain.ImportBatchResult, error) {
+				return s.ir.ImportUsers(ctx, batch, opts.Conflict, opts.DryRun)
+			})
+	case domain.ImportGames:
+		err = runImport(ctx, s, rows, report, s.parseGame,
+			func(batch []domain.ImportGame) (*domain.ImportBatchResult, error) {
+				return s.ir.ImportGames(ctx, batch, opts.Conflict, opts.DryRun)
+			})
+	case domain.ImportScores:
+		err = runImport(ctx, s, rows, report, s.parseScore,
+			func(batch []domain.ImportScore) (*domain.ImportBatchResult, error) {
+				return s.ir.ImportScores(ctx, batch, opts.Conflict, opts.DryRun)
+			})
+	}
+
+	logged := log.Info()
+	if err != nil {
+		logged = log.Error().Err(err)
+	}
+	logged.Str("kind", string(opts.Kind)).Bool("dry_run", opts.DryRun).
+		Int("rows", report.Rows).Int("created", report.Created).Int("updated", report.Updated).
+		Int("skipped", report.Skipped).Int("rejected", report.Rejected).
+		Msg("import finished")
+
+	return report, err
+}
+
+// runImport parses the rows, collects them in batches and writes each batch
+// with write, adding up the report.
+func runImport[T any](
+	ctx context.Context,
+	s *importService,
+	rows *importReader,
+	report *domain.ImportReport,
+	parse func(importRow) (T, *domain.ImportRowError),
+	write func([]T) (*domain.ImportBatchResult, error),
+) error {
+	batch := make([]T, 0, s.batchSize)
+	// raw keeps the text of the rows of the batch to report the ones the
+	// database rejects.
+	raw := make(map[int]string, s.batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result, err := write(batch)
+		if err != nil {
+			return err
+		}
+		report.Created += result.Created
+		report.Updated += result.Updated
+		report.Skipped += result.Skipped
+		for _, rejected := range result.Rejected {
+			rejected.Row = raw[rejected.Line]
+			s.reject(report, rejected)
+		}
+		batch = batch[:0]
+		clear(raw)
+		return nil
+	}
+
+	for {
+		row, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		report.Rows++
+
+		if row.err != nil {
+			s.reject(report, *row.err)
+			continue
+		}
+		parsed, rowErr := parse(row)
+		if rowErr != nil {
+			rowErr.Line, rowErr.Row = row.line, row.raw
+			s.reject(report, *rowErr)
+			continue
+		}
+
+		batch = append(batch, parsed)
+		raw[row.line] = row.raw
+		if len(batch) == s.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+func (s *importService) reject(report *domain.ImportReport, rowErr domain.ImportRowError) {
+	report.Rejected++
+	if len(report.Errors) < s.maxErrors {
+		report.Errors = append(report.Errors, rowErr)
+	} else {
+		report.ErrorsTruncated = true
+	}
+}
+
+func (s *importService) parseUser(row importRow) (domain.ImportUser, *domain.ImportRowError) {
+	username, err := s.names.normalize(row.get("username"))
+	if err != nil {
+		return domain.ImportUser{}, fieldError("username", err.Error())
+	}
+
+	email := row.get("email")
+	if email != "" && s.validate.Var(email, "email") != nil {
+		return domain.ImportUser{}, fieldError("email", "must be an email address")
+	}
+
+	hash := row.get("password_hash")
+	if hash != "" && !s.ph.Recognizes(hash) {
+		return domain.ImportUser{}, fieldError("password_hash", "must be a bcrypt or argon2id hash")
+	}
+
+	return domain.ImportUser{Line: row.line, Username: username, Email: email, PasswordHash: hash}, nil
+}
+
+func (s *importService) parseGame(row importRow) (domain.ImportGame, *domain.ImportRowError) {
+	name := row.get("name")
+	if name == "" {
+		return domain.ImportGame{}, fieldError("name", "is required")
+	}
+
+	slug := row.get("slug")
+	if slug == "" {
+		slug = utils.Slugify(name)
+		if slug == "" {
+			return domain.ImportGame{}, fieldError("slug", "can't be derived from the name, give one")
+		}
+	} else if !utils.IsValidSlug(slug) || uuid.Validate(slug) == nil {
+		return domain.ImportGame{}, fieldError("slug", domain.ErrInvalidGameSlug.Error())
+	}
+
+	return domain.ImportGame{Line: row.line, Name: name, Slug: slug}, nil
+}
+
+func (s *importService) parseScore(row importRow) (domain.ImportScore, *domain.ImportRowError) {
+	user := row.get("user_id")
+	if user == "" {
+		user = row.get("username")
+	}
+	if user == "" {
+		return domain.ImportScore{}, fieldError("user_id", "user_id or username is required")
+	}
+
+	game := row.get("game_id")
+	if game == "" {
+		game = row.get("game_slug")
+	}
+	if game == "" {
+		return domain.ImportScore{}, fieldError("game_id", "game_id or game_slug is required")
+	}
+
+	points, err := strconv.Atoi(row.get("points"))
+	if err != nil {
+		return domain.ImportScore{}, fieldError("points", "must be an integer")
+	}
+	if points < 0 {
+		return domain.ImportScore{}, fieldError("points", "must be at least 0")
+	}
+
+	return domain.ImportScore{Line: row.line, User: user, Game: game, Points: points}, nil
+}
+
+func fieldError(field, message string) *domain.ImportRowError {
+	return &domain.ImportRowError{Field: field, Message: message}
+}
+
+func checkImportOptions(opts domain.ImportOptions) error {
+	if _, ok := importColumns[opts.Kind]; !ok {
+		return fmt.Errorf("%w: unknown kind %q, use users, games or scores", domain.ErrInvalidImport, opts.Kind)
+	}
+	switch opts.Format {
+	case domain.ImportCSV, domain.ImportNDJSON:
+	default:
+		return fmt.Errorf("%w: unknown format %q, use csv or ndjson", domain.ErrInvalidImport, opts.Format)
+	}
+	switch opts.Conflict {
+	case domain.ConflictKeepBest, domain.ConflictOverwrite, domain.ConflictSkip:
+	default:
+		return fmt.Errorf("%w: unknown conflict mode %q, use keep-best, overwrite or skip", domain.ErrInvalidImport, opts.Conflict)
+	}
+	return nil
+}
+
+// importRow is a row of an import file with its columns by lowercased name.
+type importRow struct {
+	line   int
+	raw    string
+	fields map[string]string
+	// err is set for rows that can't be read, which are rejected.
+	err *domain.ImportRowError
+}
+
+func (r importRow) get(column string) string {
+	return strings.TrimSpace(r.fields[column])
+}
+
+// importReader reads the rows of a CSV file with a header or of an NDJSON
+// file, one at a time.
+type importReader struct {
+	next func() (importRow, error)
+}
+
+func newImportReader(file io.Reader, format domain.ImportFormat, required [][]string) (*importReader, error) {
+	if format == domain.ImportNDJSON {
+		return newNDJSONReader(file), nil
+	}
+	return newCSVReader(file, required)
+}
+
+func newCSVReader(file io.Reader, required [][]string) (*importReader, error) {
+	r := csv.NewReader(file)
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", domain.ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+	}
+
+	columns := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		// Spreadsheets like to start files with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[i] = name
+		present[name] = true
+	}
+	for _, alternatives := range required {
+		found := false
+		for _, name := range alternatives {
+			found = found || present[name]
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: missing column %s", domain.ErrInvalidImport, strings.Join(alternatives, " or "))
+		}
+	}
+
+	next := func() (importRow, error) {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return importRow{}, err
+		}
+		if err != nil {
+			return importRow{}, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+		}
+		line, _ := r.FieldPos(0)
+		row := importRow{line: line, raw: csvLine(record), fields: make(map[string]string, len(columns))}
+		if len(record) != len(columns) {
+			row.err = &domain.ImportRowError{
+				Line:    line,
+				Message: fmt.Sprintf("has %d fields, the header has %d", len(record), len(columns)),
+				Row:     row.raw,
+			}
+			return row, nil
+		}
+		for i, value := range record {
+			row.fields[columns[i]] = value
+		}
+		return row, nil
+	}
+	return &importReader{next: next}, nil
+}
+
+func newNDJSONReader(file io.Reader) *importReader {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+	line := 0
+
+	next := func() (importRow, error) {
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+
+			row := importRow{line: line, raw: string(text)}
+			fields, err := decodeObject(text)
+			if err != nil {
+				row.err = &domain.ImportRowError{Line: line, Message: "is not a JSON object", Row: row.raw}
+				return row, nil
+			}
+			row.fields = fields
+			return row, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return importRow{}, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidImport, line+1, err)
+		}
+		return importRow{}, io.EOF
+	}
+	return &importReader{next: next}
+}
+
+// decodeObject decodes a JSON object of scalars into strings keyed by
+// lowercased name.
+func decodeObject(text []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	dec.UseNumber()
+	var object map[string]interface{}
+	if err := dec.Decode(&object); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(object))
+	for name, value := range object {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			fields[strings.ToLower(name)] = v
+		case json.Number:
+			fields[strings.ToLower(name)] = v.String()
+		case bool:
+			fields[strings.ToLower(name)] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("field %s is not a scalar", name)
+		}
+	}
+	return fields, nil
+}
+
+func csvLine(record []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(record)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newImportService() (ports.ImportService, *mocks.ImportRepositoryMock) {
+	ir := new(mocks.ImportRepositoryMock)
+	return services.NewImportService(ir, new(mocks.UserRepositoryMock), newHasher()), ir
+}
+
+func TestImport_ScoresCSV(t *testing.T) {
+	is, ir := newImportService()
+
+	file := "username,game_slug,points\n" +
+		"ana,chess,30\n" +
+		"bob,chess,-1\n" +
+		"carl,chess\n" +
+		"dan,chess,10\n"
+	ir.On("ImportScores", mock.Anything, []domain.ImportScore{
+		{Line: 2, User: "ana", Game: "chess", Points: 30},
+		{Line: 5, User: "dan", Game: "chess", Points: 10},
+	}, domain.ConflictKeepBest, true).Return(&domain.ImportBatchResult{
+		Created:  1,
+		Rejected: []domain.ImportRowError{{Line: 5, Field: "username", Message: "user not found"}},
+	}, nil)
+
+	report, err := is.Import(context.Background(), strings.NewReader(file), domain.ImportOptions{
+		Kind: domain.ImportScores, Format: domain.ImportCSV, DryRun: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.ConflictKeepBest, report.Conflict)
+	assert.Equal(t, 4, report.Rows)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 3, report.Rejected)
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, domain.ImportRowError{Line: 3, Field: "points", Message: "must be at least 0", Row: "bob,chess,-1"}, report.Errors[0])
+	assert.Equal(t, 4, report.Errors[1].Line)
+	assert.Equal(t, "dan,chess,10", report.Errors[2].Row)
+	ir.AssertExpectations(t)
+}
+
+func TestImport_UsersNDJSON(t *testing.T) {
+	is, ir := newImportService()
+
+	hash, err := newHasher().Hash("secret123")
+	require.NoError(t, err)
+	file := `{"username":"ana","email":"ana@example.com","password_hash":"` + hash + `"}` + "\n" +
+		"\n" +
+		`{"username":"bob","password_hash":"plain"}` + "\n" +
+		`not json` + "\n" +
+		`{"username":"x"}` + "\n"
+	ir.On("ImportUsers", mock.Anything, []domain.ImportUser{
+		{Line: 1, Username: "ana", Email: "ana@example.com", PasswordHash: hash},
+	}, domain.ConflictSkip, false).Return(&domain.ImportBatchResult{Created: 1}, nil)
+
+	report, err := is.Import(context.Background(), strings.NewReader(file), domain.ImportOptions{
+		Kind: domain.ImportUsers, Format: domain.ImportNDJSON, Conflict: domain.ConflictSkip,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Rows)
+	assert.Equal(t, 3, report.Rejected)
+	assert.Equal(t, "password_hash", report.Errors[0].Field)
+	assert.Equal(t, 4, report.Errors[1].Line)
+	assert.Equal(t, "username", report.Errors[2].Field)
+	ir.AssertExpectations(t)
+}
+
+func TestImport_UsersRejectHostileArgon2Parameters(t *testing.T) {
+	is, ir := newImportService()
+
+	file := "username,password_hash\n" +
+		`ana,"$argon2id$v=19$m=1024,t=1,p=0$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"` + "\n" +
+		`bob,"$argon2id$v=19$m=4194304,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"` + "\n"
+
+	report, err := is.Import(context.Background(), strings.NewReader(file), domain.ImportOptions{
+		Kind: domain.ImportUsers, Format: domain.ImportCSV,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Rejected)
+	for _, rowErr := range report.Errors {
+		assert.Equal(t, "password_hash", rowErr.Field)
+	}
+	ir.AssertNotCalled(t, "ImportUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImport_GamesDeriveSlug(t *testing.T) {
+	is, ir := newImportService()
+
+	ir.On("ImportGames", mock.Anything, []domain.ImportGame{
+		{Line: 2, Name: "Street Fighter II", Slug: "street-fighter-ii"},
+	}, domain.ConflictOverwrite, false).Return(&domain.ImportBatchResult{Updated: 1}, nil)
+
+	report, err := is.Import(context.Background(), strings.NewReader("\ufeffName,slug\nStreet Fighter II,\nTetris,Not A Slug\n"), domain.ImportOptions{
+		Kind: domain.ImportGames, Format: domain.ImportCSV, Conflict: domain.ConflictOverwrite,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 1, report.Rejected)
+	ir.AssertExpectations(t)
+}
+
+func TestImport_InvalidFile(t *testing.T) {
+	is, ir := newImportService()
+
+	cases := []struct {
+		file string
+		opts domain.ImportOptions
+	}{
+		{"username\n", domain.ImportOptions{Kind: "teams", Format: domain.ImportCSV}},
+		{"username\n", domain.ImportOptions{Kind: domain.ImportUsers, Format: "xml"}},
+		{"username\n", domain.ImportOptions{Kind: domain.ImportUsers, Format: domain.ImportCSV, Conflict: "merge"}},
+		{"", domain.ImportOptions{Kind: domain.ImportUsers, Format: domain.ImportCSV}},
+		{"username,points\n", domain.ImportOptions{Kind: domain.ImportScores, Format: domain.ImportCSV}},
+	}
+	for _, tc := range cases {
+		_, err := is.Import(context.Background(), strings.NewReader(tc.file), tc.opts)
+		assert.ErrorIs(t, err, domain.ErrInvalidImport, "%+v", tc.opts)
+	}
+	ir.AssertNotCalled(t, "ImportUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImport_Batches(t *testing.T) {
+	t.Setenv("IMPORT_BATCH_SIZE", "2")
+	is, ir := newImportService()
+
+	dbErr := errors.New("db down")
+	ir.On("ImportGames", mock.Anything, mock.MatchedBy(func(rows []domain.ImportGame) bool { return rows[0].Line == 2 }), domain.ConflictKeepBest, false).
+		Return(&domain.ImportBatchResult{Created: 2}, nil).Once()
+	ir.On("ImportGames", mock.Anything, mock.MatchedBy(func(rows []domain.ImportGame) bool { return rows[0].Line == 4 }), domain.ConflictKeepBest, false).
+		Return(nil, dbErr).Once()
+
+	report, err := is.Import(context.Background(), strings.NewReader("name\nA\nB\nC\n"), domain.ImportOptions{
+		Kind: domain.ImportGames, Format: domain.ImportCSV,
+	})
+	assert.ErrorIs(t, err, dbErr)
+	assert.Equal(t, 2, report.Created)
+	ir.AssertExpectations(t)
+}
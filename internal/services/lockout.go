@@ -0,0 +1,149 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/metrics"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxLoginAttempts      = 5
+	defaultMaxLoginAttemptsPerIP = 20
+	defaultLoginLockout          = time.Minute
+	defaultMaxLoginLockout       = time.Hour
+	defaultLoginAttemptWindow    = 24 * time.Hour
+)
+
+type lockoutService struct {
+	lr ports.LoginAttemptRepository
+	ur ports.UserRepository
+
+	maxAttempts      int
+	maxAttemptsPerIP int
+	lockout          time.Duration
+	maxLockout       time.Duration
+	window           time.Duration
+}
+
+func NewLockoutService(lr ports.LoginAttemptRepository, ur ports.UserRepository) ports.LockoutService {
+	return &lockoutService{
+		lr:               lr,
+		ur:               ur,
+		maxAttempts:      utils.EnvInt("LOGIN_MAX_ATTEMPTS", defaultMaxLoginAttempts),
+		maxAttemptsPerIP: utils.EnvInt("LOGIN_MAX_ATTEMPTS_PER_IP", defaultMaxLoginAttemptsPerIP),
+		lockout:          utils.EnvDuration("LOGIN_LOCKOUT", defaultLoginLockout),
+		maxLockout:       utils.EnvDuration("LOGIN_LOCKOUT_MAX", defaultMaxLoginLockout),
+		window:           utils.EnvDuration("LOGIN_ATTEMPT_WINDOW", defaultLoginAttemptWindow),
+	}
+}
+
+// Check returns a *domain.LockedError when either the username or the IP is
+// locked out.
+func (ls *lockoutService) Check(username, ip string) error {
+	attempts, err := ls.lr.ListLoginAttempts([]string{userKey(username), ipKey(ip)})
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to check login lockout")
+		return err
+	}
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, attempt := range *attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		log.Info().Str("username", username).Str("ip", ip).Dur("retry_after", retryAfter).Msg("login refused while locked out")
+		return &domain.LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login for the username and the IP. Once a
+// key reaches its threshold it is locked, for twice as long with every
+// further failure.
+func (ls *lockoutService) RecordFailure(username, ip string) error {
+	metrics.LoginFailures.Inc()
+
+	if err := ls.recordFailure(userKey(username), "user", ls.maxAttempts); err != nil {
+		return err
+	}
+	return ls.recordFailure(ipKey(ip), "ip", ls.maxAttemptsPerIP)
+}
+
+// RecordSuccess forgets the failures of the username. Those of the IP are
+// kept, so logging into one account doesn't reset a guessing spree.
+func (ls *lockoutService) RecordSuccess(username string) error {
+	if err := ls.lr.ClearLoginAttempts(userKey(username)); err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to clear login attempts")
+		return err
+	}
+	return nil
+}
+
+// Unlock lifts the lockout of a user.
+func (ls *lockoutService) Unlock(userID string) error {
+	user, err := ls.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("failed to fetch user to unlock")
+		return err
+	}
+
+	if err := ls.lr.ClearLoginAttempts(userKey(user.Username)); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to unlock user")
+		return err
+	}
+
+	log.Info().Str("user_id", userID).Str("username", user.Username).Msg("user login unlocked")
+	return nil
+}
+
+func (ls *lockoutService) recordFailure(key, scope string, threshold int) error {
+	now := time.Now()
+	attempt, err := ls.lr.RecordLoginFailure(key, now, ls.window)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("failed to record login failure")
+		return err
+	}
+	if attempt.Failures < threshold {
+		return nil
+	}
+
+	duration := ls.lockoutDuration(attempt.Failures - threshold)
+	if err := ls.lr.LockLogin(key, now.Add(duration)); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("failed to lock login")
+		return err
+	}
+
+	metrics.LoginLockouts.WithLabelValues(scope).Inc()
+	log.Warn().Str("key", key).Int("failures", attempt.Failures).Dur("duration", duration).Msg("login locked out")
+	return nil
+}
+
+// lockoutDuration doubles the base lockout for every failure past the
+// threshold, up to the configured maximum.
+func (ls *lockoutService) lockoutDuration(extraFailures int) time.Duration {
+	if ls.lockout <= 0 {
+		return ls.maxLockout
+	}
+	factor := math.Pow(2, float64(extraFailures))
+	if factor >= float64(ls.maxLockout/ls.lockout) {
+		return ls.maxLockout
+	}
+	return time.Duration(factor) * ls.lockout
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLockoutCheck_Locked(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ls := services.NewLockoutService(lr, new(mocks.UserRepositoryMock))
+
+	until := time.Now().Add(90 * time.Second)
+	lr.On("ListLoginAttempts", []string{"user:test", "ip:10.0.0.1"}).Return(&[]domain.LoginAttempt{
+		{Key: "user:test", Failures: 5, LockedUntil: &until},
+	}, nil)
+
+	err := ls.Check("Test", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrLoginLocked)
+
+	var locked *domain.LockedError
+	assert.ErrorAs(t, err, &locked)
+	assert.InDelta(t, 90, locked.RetryAfter.Seconds(), 1)
+}
+
+func TestLockoutCheck_ExpiredLock(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ls := services.NewLockoutService(lr, new(mocks.UserRepositoryMock))
+
+	until := time.Now().Add(-time.Second)
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{
+		{Key: "user:test", Failures: 5, LockedUntil: &until},
+	}, nil)
+
+	assert.NoError(t, ls.Check("test", "10.0.0.1"))
+}
+
+func TestRecordFailure_LocksWithBackoff(t *testing.T) {
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "3")
+	t.Setenv("LOGIN_LOCKOUT", "1m")
+	t.Setenv("LOGIN_LOCKOUT_MAX", "10m")
+
+	tests := []struct {
+		failures int
+		lockout  time.Duration
+	}{
+		{failures: 2, lockout: 0},
+		{failures: 3, lockout: time.Minute},
+		{failures: 4, lockout: 2 * time.Minute},
+		{failures: 6, lockout: 8 * time.Minute},
+		{failures: 7, lockout: 10 * time.Minute},
+		{failures: 50, lockout: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		lr := new(mocks.LoginAttemptRepositoryMock)
+		ls := services.NewLockoutService(lr, new(mocks.UserRepositoryMock))
+
+		lr.On("RecordLoginFailure", "user:test", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "user:test", Failures: tt.failures}, nil)
+		lr.On("RecordLoginFailure", "ip:10.0.0.1", mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Key: "ip:10.0.0.1", Failures: 1}, nil)
+
+		var lockedUntil time.Time
+		lr.On("LockLogin", "user:test", mock.Anything).Run(func(args mock.Arguments) {
+			lockedUntil = args.Get(1).(time.Time)
+		}).Return(nil)
+
+		assert.NoError(t, ls.RecordFailure("test", "10.0.0.1"))
+
+		if tt.lockout == 0 {
+			lr.AssertNotCalled(t, "LockLogin", mock.Anything, mock.Anything)
+			continue
+		}
+		assert.InDelta(t, tt.lockout.Seconds(), time.Until(lockedUntil).Seconds(), 1, "failures=%d", tt.failures)
+		lr.AssertNotCalled(t, "LockLogin", "ip:10.0.0.1", mock.Anything)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	ls := services.NewLockoutService(lr, ur)
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	lr.On("ClearLoginAttempts", "user:test").Return(nil)
+
+	assert.NoError(t, ls.Unlock("user1"))
+	lr.AssertExpectations(t)
+}
+
+func TestLoginUser_LockedOut(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	us := services.NewUserService(ur, ts, services.NewLockoutService(lr, ur), newHasher(), newPolicy(t))
+
+	until := time.Now().Add(time.Minute)
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{{Key: "ip:10.0.0.1", LockedUntil: &until}}, nil)
+
+	_, err := us.LoginUser("test", "secret", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrLoginLocked)
+	ur.AssertNotCalled(t, "GetUserCreds", mock.Anything)
+}
+
+func TestLoginUser_WrongPasswordRecordsFailure(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	us := services.NewUserService(ur, ts, services.NewLockoutService(lr, ur), newHasher(), newPolicy(t))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{}, nil)
+	ur.On("GetUserCreds", "test").Return(&auth.AuthUserData{ID: "user1", Username: "test", PasswordHash: string(hash)}, nil)
+	lr.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+
+	_, err := us.LoginUser("test", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrAuthInvalid)
+	lr.AssertNumberOfCalls(t, "RecordLoginFailure", 2)
+	lr.AssertNotCalled(t, "ClearLoginAttempts", mock.Anything)
+}
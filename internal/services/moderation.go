@@ -0,0 +1,182 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+type moderationService struct {
+	mr ports.ModerationRepository
+	ur ports.UserRepository
+	ts ports.TokenService
+}
+
+func NewModerationService(mr ports.ModerationRepository, ur ports.UserRepository, ts ports.TokenService) ports.ModerationService {
+	return &moderationService{
+		mr: mr,
+		ur: ur,
+		ts: ts,
+	}
+}
+
+// Promote makes an active user an admin.
+func (ms *moderationService) Promote(actorID, userID, reason string) (*domain.ModerationAction, error) {
+	user, err := ms.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	if user.IsAdmin() {
+		return nil, domain.ErrUserAlreadyAdmin
+	}
+	if err := user.Status.Check(time.Now()); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("refusing to promote a restricted user")
+		return nil, err
+	}
+
+	action := newModerationAction(actorID, userID, domain.ActionPromote, reason)
+	if err := ms.mr.UpdateRole(action, domain.RoleAdmin); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to promote user")
+		return nil, err
+	}
+
+	logAction(action)
+	return action, nil
+}
+
+// Demote turns an admin back into a player. The last admin cannot be demoted.
+func (ms *moderationService) Demote(actorID, userID, reason string) (*domain.ModerationAction, error) {
+	user, err := ms.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, domain.ErrUserNotAdmin
+	}
+
+	admins, err := ms.ur.CountUsersByRole(domain.RoleAdmin)
+	if err != nil {
+		log.Error().Err(err).Msg("error counting admins")
+		return nil, err
+	}
+	if admins <= 1 {
+		log.Warn().Str("user_id", userID).Msg("refusing to demote the last admin")
+		return nil, domain.ErrLastAdmin
+	}
+
+	action := newModerationAction(actorID, userID, domain.ActionDemote, reason)
+	if err := ms.mr.UpdateRole(action, domain.RolePlayer); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to demote user")
+		return nil, err
+	}
+
+	logAction(action)
+	return action, nil
+}
+
+// Suspend keeps the user from signing in until the given time.
+func (ms *moderationService) Suspend(actorID, userID string, until time.Time, reason string) (*domain.ModerationAction, error) {
+	if !until.After(time.Now()) {
+		return nil, domain.ErrInvalidSuspension
+	}
+
+	action := newModerationAction(actorID, userID, domain.ActionSuspend, reason)
+	action.Until = &until
+	if err := ms.restrict(action, &domain.AccountStatus{
+		State:          domain.StatusSuspended,
+		Reason:         reason,
+		SuspendedUntil: &until,
+	}); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// Ban keeps the user from signing in for good and hides their scores.
+func (ms *moderationService) Ban(actorID, userID, reason string) (*domain.ModerationAction, error) {
+	action := newModerationAction(actorID, userID, domain.ActionBan, reason)
+	if err := ms.restrict(action, &domain.AccountStatus{
+		State:  domain.StatusBanned,
+		Reason: reason,
+	}); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// Reinstate lifts a suspension or a ban.
+func (ms *moderationService) Reinstate(actorID, userID, reason string) (*domain.ModerationAction, error) {
+	action := newModerationAction(actorID, userID, domain.ActionReinstate, reason)
+	if err := ms.mr.UpdateStatus(action, &domain.AccountStatus{State: domain.StatusActive}); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("failed to reinstate user")
+		return nil, err
+	}
+
+	logAction(action)
+	return action, nil
+}
+
+func (ms *moderationService) History(userID string) (*[]domain.ModerationAction, error) {
+	if _, err := ms.ur.GetUserByID(userID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	actions, err := ms.mr.ListActions(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to list moderation actions")
+		return nil, err
+	}
+	return actions, nil
+}
+
+// restrict applies a suspension or a ban and ends the sessions of the user.
+// Access tokens already issued are refused on their next use.
+func (ms *moderationService) restrict(action *domain.ModerationAction, status *domain.AccountStatus) error {
+	user, err := ms.ur.GetUserByID(action.UserID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", action.UserID).Msg("error fetching user")
+		return err
+	}
+
+	if user.IsAdmin() {
+		log.Warn().Str("user_id", user.ID).Str("action", action.Action).Msg("refusing to restrict an admin")
+		return domain.ErrModerateAdmin
+	}
+
+	if err := ms.mr.UpdateStatus(action, status); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Str("action", action.Action).Msg("failed to update account status")
+		return err
+	}
+
+	if err := ms.ts.RevokeUserSessions(user.ID); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to end sessions of restricted user")
+	}
+
+	logAction(action)
+	return nil
+}
+
+func newModerationAction(actorID, userID, action, reason string) *domain.ModerationAction {
+	return &domain.ModerationAction{
+		UserID:  userID,
+		ActorID: actorID,
+		Action:  action,
+		Reason:  reason,
+	}
+}
+
+func logAction(action *domain.ModerationAction) {
+	log.Info().
+		Str("user_id", action.UserID).
+		Str("actor_id", action.ActorID).
+		Str("action", action.Action).
+		Str("reason", action.Reason).
+		Msg("moderation action taken")
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newModerationService() (ports.ModerationService, *mocks.ModerationRepositoryMock, *mocks.UserRepositoryMock, *mocks.TokenRepositoryMock) {
+	mr := new(mocks.ModerationRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	ts := services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), newKeyService())
+	return services.NewModerationService(mr, ur, ts), mr, ur, tr
+}
+
+func TestBan(t *testing.T) {
+	ms, mr, ur, tr := newModerationService()
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	mr.On("UpdateStatus", mock.MatchedBy(func(a *domain.ModerationAction) bool {
+		return a.UserID == "user1" && a.ActorID == "mod1" && a.Action == domain.ActionBan && a.Reason == "cheating"
+	}), &domain.AccountStatus{State: domain.StatusBanned, Reason: "cheating"}).Return(nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+
+	action, err := ms.Ban("mod1", "user1", "cheating")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ActionBan, action.Action)
+
+	mr.AssertExpectations(t)
+	tr.AssertExpectations(t)
+}
+
+func TestBan_Admin(t *testing.T) {
+	ms, mr, ur, _ := newModerationService()
+
+	ur.On("GetUserByID", "admin1").Return(&domain.User{ID: "admin1", Role: domain.RoleAdmin}, nil)
+
+	_, err := ms.Ban("mod1", "admin1", "cheating")
+	assert.ErrorIs(t, err, domain.ErrModerateAdmin)
+	mr.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestSuspend(t *testing.T) {
+	ms, mr, ur, tr := newModerationService()
+
+	until := time.Now().Add(24 * time.Hour)
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	mr.On("UpdateStatus", mock.MatchedBy(func(a *domain.ModerationAction) bool {
+		return a.Action == domain.ActionSuspend && a.Until.Equal(until)
+	}), mock.MatchedBy(func(s *domain.AccountStatus) bool {
+		return s.State == domain.StatusSuspended && s.SuspendedUntil.Equal(until)
+	})).Return(nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+
+	_, err := ms.Suspend("mod1", "user1", until, "spam")
+	assert.NoError(t, err)
+	mr.AssertExpectations(t)
+}
+
+func TestSuspend_InThePast(t *testing.T) {
+	ms, mr, _, _ := newModerationService()
+
+	_, err := ms.Suspend("mod1", "user1", time.Now().Add(-time.Hour), "spam")
+	assert.ErrorIs(t, err, domain.ErrInvalidSuspension)
+	mr.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+}
+
+func TestPromote_BannedUser(t *testing.T) {
+	ms, mr, ur, _ := newModerationService()
+
+	banned := &domain.User{ID: "user1", Role: domain.RolePlayer, Status: domain.AccountStatus{State: domain.StatusBanned}}
+	ur.On("GetUserByID", "user1").Return(banned, nil)
+
+	_, err := ms.Promote("admin1", "user1", "trusted")
+	assert.ErrorIs(t, err, domain.ErrUserBanned)
+	mr.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+}
+
+func TestDemote(t *testing.T) {
+	ms, mr, ur, _ := newModerationService()
+
+	ur.On("GetUserByID", "admin2").Return(&domain.User{ID: "admin2", Role: domain.RoleAdmin}, nil)
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(2), nil)
+	mr.On("UpdateRole", mock.MatchedBy(func(a *domain.ModerationAction) bool {
+		return a.Action == domain.ActionDemote
+	}), domain.RolePlayer).Return(nil)
+
+	_, err := ms.Demote("admin1", "admin2", "left the team")
+	assert.NoError(t, err)
+	mr.AssertExpectations(t)
+}
+
+func TestDemote_LastAdmin(t *testing.T) {
+	ms, mr, ur, _ := newModerationService()
+
+	ur.On("GetUserByID", "admin1").Return(&domain.User{ID: "admin1", Role: domain.RoleAdmin}, nil)
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(1), nil)
+
+	_, err := ms.Demote("admin1", "admin1", "stepping down")
+	assert.ErrorIs(t, err, domain.ErrLastAdmin)
+	mr.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything)
+}
+
+func TestAccountStatus_Check(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	assert.NoError(t, domain.AccountStatus{State: domain.StatusActive}.Check(now))
+	assert.NoError(t, domain.AccountStatus{State: domain.StatusSuspended, SuspendedUntil: &earlier}.Check(now))
+	assert.ErrorIs(t, domain.AccountStatus{State: domain.StatusSuspended, SuspendedUntil: &later}.Check(now), domain.ErrUserSuspended)
+	assert.ErrorIs(t, domain.AccountStatus{State: domain.StatusBanned}.Check(now), domain.ErrUserBanned)
+}
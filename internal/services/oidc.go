@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultOIDCStateTTL     = 10 * time.Minute
+	maxOIDCUsernameLength   = 24
+	maxOIDCUsernameAttempts = 20
+)
+
+var defaultOIDCScopes = []string{oidc.ScopeOpenID, "email", "profile"}
+
+type oidcService struct {
+	or        ports.OIDCRepository
+	ur        ports.UserRepository
+	ts        ports.TokenService
+	providers map[string]*oidcClient
+	stateTTL  time.Duration
+	names     *usernameRules
+}
+
+// oidcClient discovers its provider on first use, so the API starts even
+// while a provider is down.
+type oidcClient struct {
+	config   domain.OIDCProvider
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(or ports.OIDCRepository, ur ports.UserRepository, ts ports.TokenService, providers []domain.OIDCProvider) ports.OIDCService {
+	clients := make(map[string]*oidcClient, len(providers))
+	for _, p := range providers {
+		clients[p.Name] = &oidcClient{config: p}
+	}
+	return &oidcService{
+		or:        or,
+		ur:        ur,
+		ts:        ts,
+		providers: clients,
+		stateTTL:  utils.EnvDuration("OIDC_STATE_TTL", defaultOIDCStateTTL),
+		names:     newUsernameRules(ur),
+	}
+}
+
+// OIDCProvidersFromEnv reads the providers named in OIDC_PROVIDERS. Each
+// provider NAME is configured through OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL and optionally _SCOPES.
+func OIDCProvidersFromEnv() []domain.OIDCProvider {
+	var providers []domain.OIDCProvider
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := domain.OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       defaultOIDCScopes,
+		}
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			provider.Scopes = strings.Split(scopes, ",")
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Warn().Str("provider", name).Msg("incomplete OIDC provider configuration, skipping")
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// AuthCodeURL starts a login and returns the provider URL to send the player
+// to, with the binding the callback has to present. The code verifier (PKCE)
+// and nonce stay on the server until the callback.
+func (s *oidcService) AuthCodeURL(ctx context.Context, provider string) (*domain.OIDCRedirect, error) {
+	return s.authCodeURL(ctx, provider, "")
+}
+
+// LinkURL is AuthCodeURL for a logged in user: the callback links the
+// identity to userID instead of looking up or creating its user.
+func (s *oidcService) LinkURL(ctx context.Context, provider, userID string) (*domain.OIDCRedirect, error) {
+	return s.authCodeURL(ctx, provider, userID)
+}
+
+func (s *oidcService) authCodeURL(ctx context.Context, provider, userID string) (*domain.OIDCRedirect, error) {
+	client, oauthConfig, _, err := s.client(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate login state")
+		return nil, err
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate nonce")
+		return nil, err
+	}
+	binding, bindingHash, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate login binding")
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	if err := s.or.CreateLoginState(&domain.OIDCLoginState{
+		Provider:     client.config.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       userID,
+		BindingHash:  bindingHash,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}, stateHash); err != nil {
+		log.Error().Err(err).Str("provider", provider).Msg("failed to store login state")
+		return nil, err
+	}
+
+	return &domain.OIDCRedirect{
+		URL:     oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)),
+		Binding: binding,
+	}, nil
+}
+
+// Callback completes a login: the code is exchanged for an ID token, the
+// identity is linked to a local user, created if needed, and a token pair is
+// issued for that user. Logins started with LinkURL link the identity to the
+// user who started them. binding has to be the one handed out with the
+// state: a login started elsewhere, e.g. by an attacker who sent the link to
+// a player, is refused.
+func (s *oidcService) Callback(ctx context.Context, provider, code, state, binding string) (*domain.TokenPair, error) {
+	client, oauthConfig, verifier, err := s.client(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.or.ConsumeLoginState(hashToken(state), time.Now())
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("login state lookup failed")
+		return nil, err
+	}
+	if pending.Provider != client.config.Name {
+		log.Warn().Str("provider", provider).Str("state_provider", pending.Provider).Msg("login state of another provider")
+		return nil, domain.ErrOIDCStateInvalid
+	}
+	if pending.BindingHash == "" || subtle.ConstantTimeCompare([]byte(hashToken(binding)), []byte(pending.BindingHash)) != 1 {
+		log.Warn().Str("provider", provider).Str("user_id", pending.UserID).Msg("login state completed in another browser")
+		return nil, domain.ErrOIDCStateInvalid
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(pending.CodeVerifier))
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("authorization code exchange failed")
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		log.Warn().Str("provider", provider).Msg("token response without id_token")
+		return nil, domain.ErrOIDCLoginFailed
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("id token verification failed")
+		return nil, domain.ErrOIDCLoginFailed
+	}
+	if idToken.Nonce != pending.Nonce {
+		log.Warn().Str("provider", provider).Msg("id token nonce mismatch")
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+		Name              string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		log.Warn().Err(err).Str("provider", provider).Msg("invalid id token claims")
+		return nil, domain.ErrOIDCLoginFailed
+	}
+
+	identity := &domain.ExternalIdentity{
+		Provider:          client.config.Name,
+		Subject:           idToken.Subject,
+		EmailVerified:     claims.EmailVerified,
+		PreferredUsername: claims.PreferredUsername,
+	}
+	// Unverified emails could belong to someone else and are not kept.
+	if claims.EmailVerified {
+		identity.Email = strings.ToLower(claims.Email)
+	}
+	if identity.PreferredUsername == "" {
+		identity.PreferredUsername = claims.Name
+	}
+
+	var user *domain.User
+	if pending.UserID != "" {
+		user, err = s.linkUser(identity, pending.UserID)
+	} else {
+		user, err = s.resolveUser(ctx, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.ts.IssueTokens(user)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserBanned) || errors.Is(err, domain.ErrUserSuspended) {
+			return nil, err
+		}
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to issue tokens")
+		return nil, domain.ErrUnexpected
+	}
+
+	log.Info().Str("user_id", user.ID).Str("provider", provider).Msg("user logged in with identity provider")
+	return tokens, nil
+}
+
+// linkUser links an identity to the user who asked for it from a logged in
+// session. An identity already linked to someone else stays theirs.
+func (s *oidcService) linkUser(identity *domain.ExternalIdentity, userID string) (*domain.User, error) {
+	owner, err := s.or.GetUserByIdentity(identity.Provider, identity.Subject)
+	switch {
+	case err == nil && owner.ID == userID:
+		return owner, nil
+	case err == nil:
+		log.Warn().Str("user_id", userID).Str("owner_id", owner.ID).Str("provider", identity.Provider).Msg("identity already linked to another user")
+		return nil, domain.ErrIdentityLinked
+	case !errors.Is(err, domain.ErrUserNotFound):
+		log.Error().Err(err).Str("provider", identity.Provider).Msg("error fetching user by identity")
+		return nil, err
+	}
+
+	user, err := s.ur.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	identity.UserID = user.ID
+	if err := s.or.LinkIdentity(identity); err != nil {
+		if !errors.Is(err, domain.ErrIdentityLinked) {
+			log.Error().Err(err).Str("user_id", user.ID).Str("provider", identity.Provider).Msg("failed to link identity")
+		}
+		return nil, err
+	}
+	log.Info().Str("user_id", user.ID).Str("provider", identity.Provider).Msg("identity linked")
+	return user, nil
+}
+
+// resolveUser finds the user an identity belongs to or creates one. Local
+// emails are not verified, so an identity is never linked to a user because
+// of a matching email: whoever registered it first could be an attacker
+// waiting for the owner to sign in. Users link identities themselves with
+// LinkURL.
+func (s *oidcService) resolveUser(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
+	user, err := s.or.GetUserByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		log.Error().Err(err).Str("provider", identity.Provider).Msg("error fetching user by identity")
+		return nil, err
+	}
+
+	if identity.Email != "" {
+		_, err := s.ur.GetUserByEmail(identity.Email)
+		switch {
+		// The email belongs to another account; the new user goes without.
+		case err == nil:
+			identity.Email = ""
+
+		case !errors.Is(err, domain.ErrUserNotFound):
+			log.Error().Err(err).Msg("error fetching user by email")
+			return nil, err
+		}
+	}
+
+	base := utils.Slugify(identity.PreferredUsername)
+	if base == "" && identity.Email != "" {
+		base = utils.Slugify(strings.SplitN(identity.Email, "@", 2)[0])
+	}
+	if base == "" {
+		base = "player"
+	}
+	// Suffixes don't help names under a reserved prefix such as "guest-".
+	if _, err := s.names.normalize(base); errors.Is(err, domain.ErrUsernameReserved) {
+		base = "player-" + base
+	}
+	if len(base) > maxOIDCUsernameLength {
+		base = strings.TrimRight(base[:maxOIDCUsernameLength], "-")
+	}
+
+	for i := 1; i <= maxOIDCUsernameAttempts; i++ {
+		username := base
+		if i > 1 {
+			username = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		// Too short, reserved or held names move on to the next candidate.
+		if _, err := s.names.check(username, ""); err != nil {
+			if errors.Is(err, domain.ErrUsernameInvalid) || errors.Is(err, domain.ErrUsernameReserved) || errors.Is(err, domain.ErrUsernameHeld) {
+				continue
+			}
+			return nil, err
+		}
+
+		user, err := s.or.CreateUserWithIdentity(ctx, username, identity)
+		switch {
+		case err == nil:
+			log.Info().Str("user_id", user.ID).Str("provider", identity.Provider).Msg("user created from identity")
+			return user, nil
+
+		case errors.Is(err, domain.ErrUsernameAlreadyExists):
+			continue
+
+		case errors.Is(err, domain.ErrIdentityLinked):
+			// A concurrent first login of the same identity won the race.
+			return s.or.GetUserByIdentity(identity.Provider, identity.Subject)
+
+		default:
+			log.Error().Err(err).Str("provider", identity.Provider).Msg("failed to create user from identity")
+			return nil, err
+		}
+	}
+
+	log.Error().Str("username", base).Msg("no free username for new identity")
+	return nil, domain.ErrUsernameAlreadyExists
+}
+
+// client returns the OAuth2 configuration and ID token verifier of a provider.
+func (s *oidcService) client(ctx context.Context, name string) (*oidcClient, *oauth2.Config, *oidc.IDTokenVerifier, error) {
+	client, ok := s.providers[name]
+	if !ok {
+		return nil, nil, nil, domain.ErrOIDCProviderNotFound
+	}
+
+	provider, err := client.discover(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("provider", name).Msg("identity provider discovery failed")
+		return nil, nil, nil, domain.ErrOIDCProviderUnavailable
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     client.config.ClientID,
+		ClientSecret: client.config.ClientSecret,
+		RedirectURL:  client.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       client.config.Scopes,
+	}
+	verifier := provider.Verifier(&oidc.Config{ClientID: client.config.ClientID})
+	return client, oauthConfig, verifier, nil
+}
+
+func (c *oidcClient) discover(ctx context.Context) (*oidc.Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider == nil {
+		provider, err := oidc.NewProvider(ctx, c.config.IssuerURL)
+		if err != nil {
+			return nil, err
+		}
+		c.provider = provider
+	}
+	return c.provider, nil
+}
@@ -0,0 +1,361 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockClientID    = "scoring-api"
+	mockRedirectURL = "http://localhost:8080/auth/oidc/mock/callback"
+)
+
+// mockOIDCProvider is an in-process OpenID Connect provider. It serves
+// discovery, its signing key and a token endpoint that checks the PKCE code
+// verifier against the challenge of the authorization request.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge   string
+	redirectURI string
+	claims      jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, codes: map[string]mockAuthorization{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *mockOIDCProvider) config() domain.OIDCProvider {
+	return domain.OIDCProvider{
+		Name:         "mock",
+		IssuerURL:    p.server.URL,
+		ClientID:     mockClientID,
+		ClientSecret: "secret",
+		RedirectURL:  mockRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// authorize plays the player consenting at the provider: it takes the URL the
+// API redirected to and returns the code and state of the redirect back.
+func (p *mockOIDCProvider) authorize(t *testing.T, authURL string, claims jwt.MapClaims) (code, state string) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+	require.Equal(t, mockClientID, q.Get("client_id"))
+
+	claims["nonce"] = q.Get("nonce")
+	code = rand.Text()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = mockAuthorization{
+		challenge:   q.Get("code_challenge"),
+		redirectURI: q.Get("redirect_uri"),
+		claims:      claims,
+	}
+	return code, q.Get("state")
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/authorize",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	auth, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || auth.challenge != base64.RawURLEncoding.EncodeToString(sum[:]) || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss": p.server.URL,
+		"aud": mockClientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range auth.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "mock-key"
+	idToken, _ := token.SignedString(p.key)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type oidcFixture struct {
+	provider *mockOIDCProvider
+	or       *mocks.OIDCRepositoryMock
+	ur       *mocks.UserRepositoryMock
+	tr       *mocks.TokenRepositoryMock
+	os       ports.OIDCService
+
+	state     *domain.OIDCLoginState
+	stateHash string
+	// binding is what the browser that started the login holds.
+	binding string
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	f := &oidcFixture{
+		provider: newMockOIDCProvider(t),
+		or:       new(mocks.OIDCRepositoryMock),
+		ur:       new(mocks.UserRepositoryMock),
+		tr:       new(mocks.TokenRepositoryMock),
+	}
+	rr := new(mocks.RoleRepositoryMock)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	f.tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	ts := services.NewTokenService(f.tr, f.ur, rr, newKeyService())
+	f.os = services.NewOIDCService(f.or, f.ur, ts, []domain.OIDCProvider{f.provider.config()})
+
+	f.or.On("CreateLoginState", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		f.state = args.Get(0).(*domain.OIDCLoginState)
+		f.stateHash = args.String(1)
+	}).Return(nil)
+	return f
+}
+
+// login runs the flow up to the redirect back from the provider.
+func (f *oidcFixture) login(t *testing.T, claims jwt.MapClaims) (code, state string) {
+	redirect, err := f.os.AuthCodeURL(context.Background(), "mock")
+	require.NoError(t, err)
+	assert.NotContains(t, redirect.URL, f.state.CodeVerifier)
+	assert.NotContains(t, redirect.URL, redirect.Binding)
+	f.binding = redirect.Binding
+
+	code, state = f.provider.authorize(t, redirect.URL, claims)
+	sum := sha256.Sum256([]byte(state))
+	require.Equal(t, f.stateHash, hex.EncodeToString(sum[:]))
+	return code, state
+}
+
+func TestOIDCCallback_CreatesUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{
+		"sub":                "sub-1",
+		"email":              "Martin@Example.com",
+		"email_verified":     true,
+		"preferred_username": "Martín Arias",
+	})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+	f.or.On("GetUserByIdentity", "mock", "sub-1").Return(nil, domain.ErrUserNotFound)
+	f.ur.On("GetUserByEmail", "martin@example.com").Return(nil, domain.ErrUserNotFound)
+	f.ur.On("IsUsernameHeld", "martin-arias", "", mock.Anything).Return(false, nil)
+	f.or.On("CreateUserWithIdentity", mock.Anything, "martin-arias", mock.MatchedBy(func(i *domain.ExternalIdentity) bool {
+		return i.Provider == "mock" && i.Subject == "sub-1" && i.Email == "martin@example.com"
+	})).Return(validUser, nil)
+
+	tokens, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	f.or.AssertExpectations(t)
+}
+
+func TestOIDCCallback_DoesNotLinkByEmail(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{"sub": "sub-1", "email": "test@example.com", "email_verified": true, "preferred_username": "tester"})
+
+	// Someone registered the email locally; it is not verified, so it may not
+	// be theirs.
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+	f.or.On("GetUserByIdentity", "mock", "sub-1").Return(nil, domain.ErrUserNotFound)
+	f.ur.On("GetUserByEmail", "test@example.com").Return(validUser, nil)
+	f.ur.On("IsUsernameHeld", "tester", "", mock.Anything).Return(false, nil)
+	f.or.On("CreateUserWithIdentity", mock.Anything, "tester", mock.MatchedBy(func(i *domain.ExternalIdentity) bool {
+		return i.Subject == "sub-1" && i.Email == ""
+	})).Return(&domain.User{ID: "user2", Username: "tester", Role: domain.RolePlayer}, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	require.NoError(t, err)
+	f.or.AssertNotCalled(t, "LinkIdentity", mock.Anything)
+	f.or.AssertExpectations(t)
+}
+
+// link runs the flow of a logged in user linking an identity up to the
+// redirect back from the provider.
+func (f *oidcFixture) link(t *testing.T, userID string, claims jwt.MapClaims) (code, state string) {
+	redirect, err := f.os.LinkURL(context.Background(), "mock", userID)
+	require.NoError(t, err)
+	require.Equal(t, userID, f.state.UserID)
+	f.binding = redirect.Binding
+
+	return f.provider.authorize(t, redirect.URL, claims)
+}
+
+func TestOIDCCallback_LinksLoggedInUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.link(t, validUser.ID, jwt.MapClaims{"sub": "sub-1", "email": "other@example.com", "email_verified": true})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+	f.or.On("GetUserByIdentity", "mock", "sub-1").Return(nil, domain.ErrUserNotFound)
+	f.ur.On("GetUserByID", validUser.ID).Return(validUser, nil)
+	f.or.On("LinkIdentity", mock.MatchedBy(func(i *domain.ExternalIdentity) bool {
+		return i.UserID == validUser.ID && i.Subject == "sub-1"
+	})).Return(nil)
+
+	tokens, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	f.or.AssertExpectations(t)
+	f.or.AssertNotCalled(t, "CreateUserWithIdentity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_LinkRefusesIdentityOfAnotherUser(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.link(t, validUser.ID, jwt.MapClaims{"sub": "sub-1"})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+	f.or.On("GetUserByIdentity", "mock", "sub-1").Return(&domain.User{ID: "user2"}, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	assert.ErrorIs(t, err, domain.ErrIdentityLinked)
+	f.or.AssertNotCalled(t, "LinkIdentity", mock.Anything)
+}
+
+func TestOIDCCallback_RefusesLinkFromAnotherBrowser(t *testing.T) {
+	f := newOIDCFixture(t)
+	// An attacker starts a link to their own account and sends the URL to a
+	// player, whose browser has no binding.
+	code, state := f.link(t, "attacker", jwt.MapClaims{"sub": "victim-sub"})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, "")
+	assert.ErrorIs(t, err, domain.ErrOIDCStateInvalid)
+	f.or.AssertNotCalled(t, "LinkIdentity", mock.Anything)
+}
+
+func TestOIDCCallback_RefusesLoginFromAnotherBrowser(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{"sub": "sub-1"})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(f.state, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, "another-binding")
+	assert.ErrorIs(t, err, domain.ErrOIDCStateInvalid)
+	f.or.AssertNotCalled(t, "GetUserByIdentity", mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_WrongCodeVerifier(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{"sub": "sub-1"})
+
+	tampered := *f.state
+	tampered.CodeVerifier = "not-the-verifier-of-this-login-0123456789abc"
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(&tampered, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	assert.ErrorIs(t, err, domain.ErrOIDCLoginFailed)
+	f.or.AssertNotCalled(t, "GetUserByIdentity", mock.Anything, mock.Anything)
+}
+
+func TestOIDCCallback_NonceMismatch(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{"sub": "sub-1"})
+
+	replayed := *f.state
+	replayed.Nonce = "another-nonce"
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(&replayed, nil)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	assert.ErrorIs(t, err, domain.ErrOIDCLoginFailed)
+}
+
+func TestOIDCCallback_UnknownState(t *testing.T) {
+	f := newOIDCFixture(t)
+	code, state := f.login(t, jwt.MapClaims{"sub": "sub-1"})
+
+	f.or.On("ConsumeLoginState", f.stateHash, mock.Anything).Return(nil, domain.ErrOIDCStateInvalid)
+
+	_, err := f.os.Callback(context.Background(), "mock", code, state, f.binding)
+	assert.ErrorIs(t, err, domain.ErrOIDCStateInvalid)
+}
+
+func TestOIDCAuthCodeURL_UnknownProvider(t *testing.T) {
+	f := newOIDCFixture(t)
+
+	_, err := f.os.AuthCodeURL(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrOIDCProviderNotFound)
+}
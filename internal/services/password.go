@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultResetTokenTTL = time.Hour
+
+type passwordService struct {
+	ur       ports.UserRepository
+	prr      ports.PasswordResetRepository
+	ts       ports.TokenService
+	notifier ports.Notifier
+	ph       ports.PasswordHasher
+	pp       ports.PasswordPolicy
+	resetTTL time.Duration
+	resetURL string
+}
+
+func NewPasswordService(ur ports.UserRepository, prr ports.PasswordResetRepository, ts ports.TokenService, notifier ports.Notifier, ph ports.PasswordHasher, pp ports.PasswordPolicy) ports.PasswordService {
+	return &passwordService{
+		ur:       ur,
+		prr:      prr,
+		ts:       ts,
+		notifier: notifier,
+		ph:       ph,
+		pp:       pp,
+		resetTTL: utils.EnvDuration("PASSWORD_RESET_TTL", defaultResetTokenTTL),
+		resetURL: os.Getenv("PASSWORD_RESET_URL"),
+	}
+}
+
+// ChangePassword replaces the password of a logged in user. Every session of
+// the user is ended and a fresh token pair is returned for the current client.
+func (ps *passwordService) ChangePassword(userID, currentPassword, newPassword string) (*domain.TokenPair, error) {
+	creds, err := ps.ur.GetUserCredsByID(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to fetch user")
+		return nil, err
+	}
+
+	if !ps.ph.Verify(creds.PasswordHash, currentPassword) {
+		log.Info().Str("user_id", userID).Msg("password change failed: invalid current password")
+		return nil, domain.ErrCurrentPasswordInvalid
+	}
+
+	if err := ps.pp.Validate(newPassword, creds.Username); err != nil {
+		log.Info().Err(err).Str("user_id", userID).Msg("password change against the policy")
+		return nil, err
+	}
+
+	if err := ps.setPassword(userID, newPassword); err != nil {
+		return nil, err
+	}
+
+	tokens, err := ps.ts.IssueTokens(&domain.User{
+		ID:       creds.ID,
+		Username: creds.Username,
+		Role:     creds.Role,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to issue tokens")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID).Msg("password changed")
+	return tokens, nil
+}
+
+// RequestPasswordReset sends a reset token to the email of the user with the
+// given username or email. Unknown users are not reported, so the endpoint
+// can't be used to find out which accounts exist. For the same reason the
+// token is stored and sent in the background: known accounts answer as fast
+// as unknown ones, and a failed delivery is only logged.
+func (ps *passwordService) RequestPasswordReset(login string) error {
+	user, err := ps.findUser(login)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			log.Info().Str("login", login).Msg("password reset requested for unknown user")
+			return nil
+		}
+		log.Error().Err(err).Str("login", login).Msg("failed to fetch user")
+		return err
+	}
+
+	if user.Email == "" {
+		log.Warn().Str("user_id", user.ID).Msg("password reset requested for user without email")
+		return nil
+	}
+
+	go ps.sendResetToken(user)
+	return nil
+}
+
+// sendResetToken stores a new reset token for user and sends it by email.
+func (ps *passwordService) sendResetToken(user *domain.User) {
+	token, hash, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate reset token")
+		return
+	}
+
+	if err := ps.prr.CreateResetToken(&domain.ResetToken{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(ps.resetTTL),
+	}, hash); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to store reset token")
+		return
+	}
+
+	if err := ps.notifier.Send(&domain.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    ps.resetMessage(user.Username, token),
+	}); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to send reset token")
+		return
+	}
+
+	log.Info().Str("user_id", user.ID).Msg("password reset token sent")
+}
+
+// ResetPassword sets a new password with a reset token and ends every
+// session of the user.
+func (ps *passwordService) ResetPassword(token, newPassword string) error {
+	hash := hashToken(token)
+	pending, err := ps.prr.GetResetToken(hash, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("password reset with invalid token")
+		return err
+	}
+	user, err := ps.ur.GetUserByID(pending.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", pending.UserID).Msg("failed to fetch user")
+		return err
+	}
+
+	// Checked before the token is used up, so a rejected password can be
+	// retried with the same link.
+	if err := ps.pp.Validate(newPassword, user.Username); err != nil {
+		log.Info().Err(err).Str("user_id", user.ID).Msg("password reset against the policy")
+		return err
+	}
+
+	resetToken, err := ps.prr.ConsumeResetToken(hash, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("password reset with invalid token")
+		return err
+	}
+
+	if err := ps.setPassword(resetToken.UserID, newPassword); err != nil {
+		return err
+	}
+
+	log.Info().Str("user_id", resetToken.UserID).Msg("password reset")
+	return nil
+}
+
+func (ps *passwordService) setPassword(userID, password string) error {
+	hash, err := ps.ph.Hash(password)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to hash password")
+		return err
+	}
+
+	if err := ps.ur.UpdatePassword(userID, hash, false); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to update password")
+		return err
+	}
+
+	return ps.ts.RevokeUserSessions(userID)
+}
+
+func (ps *passwordService) findUser(login string) (*domain.User, error) {
+	if strings.Contains(login, "@") {
+		return ps.ur.GetUserByEmail(login)
+	}
+	return ps.ur.GetUserByUsername(login)
+}
+
+func (ps *passwordService) resetMessage(username, token string) string {
+	ttl := ps.resetTTL.Round(time.Minute)
+	u, err := url.Parse(ps.resetURL)
+	if ps.resetURL == "" || err != nil {
+		return fmt.Sprintf("Hi %s,\n\nUse this token to reset your password: %s\n\nIt expires in %s. If you didn't ask for it, ignore this message.\n", username, token, ttl)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf("Hi %s,\n\nFollow this link to reset your password: %s\n\nIt expires in %s. If you didn't ask for it, ignore this message.\n", username, u.String(), ttl)
+}
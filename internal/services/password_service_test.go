@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/notifier"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestChangePassword(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+	ps := services.NewPasswordService(ur, new(mocks.PasswordResetRepositoryMock), ts, notifier.NewLogNotifier(), newHasher(), newPolicy(t))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("oldpass"), bcrypt.MinCost)
+	ur.On("GetUserCredsByID", "user1").Return(&auth.AuthUserData{ID: "user1", Username: "test", PasswordHash: string(hash), Role: domain.RolePlayer}, nil)
+	ur.On("UpdatePassword", "user1", mock.MatchedBy(func(h string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(h), []byte("newpass123")) == nil
+	}), false).Return(nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := ps.ChangePassword("user1", "oldpass", "newpass123")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	ur.AssertExpectations(t)
+	tr.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	ts := services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), newKeyService())
+	ps := services.NewPasswordService(ur, new(mocks.PasswordResetRepositoryMock), ts, notifier.NewLogNotifier(), newHasher(), newPolicy(t))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("oldpass"), bcrypt.MinCost)
+	ur.On("GetUserCredsByID", "user1").Return(&auth.AuthUserData{ID: "user1", PasswordHash: string(hash)}, nil)
+
+	_, err := ps.ChangePassword("user1", "wrong", "newpass123")
+	assert.ErrorIs(t, err, domain.ErrCurrentPasswordInvalid)
+	ur.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	tr.AssertNotCalled(t, "RevokeUserRefreshTokens", mock.Anything)
+}
+
+func TestPasswordReset(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	prr := new(mocks.PasswordResetRepositoryMock)
+	ts := services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), newKeyService())
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ps := services.NewPasswordService(ur, prr, ts, notifier.NewFileNotifier(outbox), newHasher(), newPolicy(t))
+
+	user := &domain.User{ID: "user1", Username: "test", Email: "test@example.com"}
+	ur.On("GetUserByEmail", "test@example.com").Return(user, nil)
+
+	var storedHash string
+	prr.On("CreateResetToken", mock.MatchedBy(func(rt *domain.ResetToken) bool {
+		return rt.UserID == "user1"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+	}).Return(nil)
+
+	assert.NoError(t, ps.RequestPasswordReset("test@example.com"))
+
+	// The token is sent in the background.
+	var raw []byte
+	assert.Eventually(t, func() bool {
+		raw, _ = os.ReadFile(outbox)
+		return len(raw) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// The token travels in the notification and only its hash is stored.
+	var msg struct{ To, Body string }
+	assert.NoError(t, json.Unmarshal(raw, &msg))
+	assert.Equal(t, "test@example.com", msg.To)
+
+	match := regexp.MustCompile(`password: (\S+)`).FindStringSubmatch(msg.Body)
+	assert.Len(t, match, 2)
+	token := match[1]
+	sum := sha256.Sum256([]byte(token))
+	assert.Equal(t, hex.EncodeToString(sum[:]), storedHash)
+
+	prr.On("GetResetToken", storedHash, mock.Anything).Return(&domain.ResetToken{ID: "rst1", UserID: "user1"}, nil)
+	ur.On("GetUserByID", "user1").Return(user, nil)
+	prr.On("ConsumeResetToken", storedHash, mock.Anything).Return(&domain.ResetToken{ID: "rst1", UserID: "user1"}, nil)
+	ur.On("UpdatePassword", "user1", mock.Anything, false).Return(nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+
+	assert.NoError(t, ps.ResetPassword(token, "newpass123"))
+
+	ur.AssertExpectations(t)
+	tr.AssertExpectations(t)
+	prr.AssertExpectations(t)
+}
+
+func TestRequestPasswordReset_UnknownUser(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	prr := new(mocks.PasswordResetRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	ps := services.NewPasswordService(ur, prr, ts, notifier.NewLogNotifier(), newHasher(), newPolicy(t))
+
+	ur.On("GetUserByUsername", "ghost").Return((*domain.User)(nil), domain.ErrUserNotFound)
+
+	assert.NoError(t, ps.RequestPasswordReset("ghost"))
+	prr.AssertNotCalled(t, "CreateResetToken", mock.Anything, mock.Anything)
+}
+
+type failingNotifier struct{ sent chan struct{} }
+
+func (n failingNotifier) Send(*domain.Notification) error {
+	close(n.sent)
+	return assert.AnError
+}
+
+func TestRequestPasswordReset_HidesDeliveryFailures(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	prr := new(mocks.PasswordResetRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	n := failingNotifier{sent: make(chan struct{})}
+	ps := services.NewPasswordService(ur, prr, ts, n, newHasher(), newPolicy(t))
+
+	ur.On("GetUserByUsername", "test").Return(&domain.User{ID: "user1", Username: "test", Email: "test@example.com"}, nil)
+	prr.On("CreateResetToken", mock.Anything, mock.Anything).Return(nil)
+
+	// A failed delivery answers like an unknown user.
+	assert.NoError(t, ps.RequestPasswordReset("test"))
+	select {
+	case <-n.sent:
+	case <-time.After(time.Second):
+		t.Fatal("reset token was not sent")
+	}
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	prr := new(mocks.PasswordResetRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	ps := services.NewPasswordService(ur, prr, ts, notifier.NewLogNotifier(), newHasher(), newPolicy(t))
+
+	prr.On("GetResetToken", mock.Anything, mock.Anything).Return(nil, domain.ErrResetTokenInvalid)
+
+	err := ps.ResetPassword("used-token", "newpass123")
+	assert.ErrorIs(t, err, domain.ErrResetTokenInvalid)
+	ur.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_RejectsUsernameInPassword(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	prr := new(mocks.PasswordResetRepositoryMock)
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), ur, new(mocks.RoleRepositoryMock), newKeyService())
+	ps := services.NewPasswordService(ur, prr, ts, notifier.NewLogNotifier(), newHasher(), newPolicy(t))
+
+	prr.On("GetResetToken", mock.Anything, mock.Anything).Return(&domain.ResetToken{ID: "rst1", UserID: "user1"}, nil)
+	ur.On("GetUserByID", "user1").Return(&domain.User{ID: "user1", Username: "martinmartin"}, nil)
+
+	err := ps.ResetPassword("token", "MartinMartin")
+	assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+	// The token is kept for another try.
+	prr.AssertNotCalled(t, "ConsumeResetToken", mock.Anything, mock.Anything)
+	ur.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
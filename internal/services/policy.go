@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPasswordMinLength  = 8
+	defaultPasswordMaxLength  = 128
+	defaultPasswordMinClasses = 1
+)
+
+type passwordPolicy struct {
+	minLength  int
+	maxLength  int
+	minClasses int
+	blocklist  map[string]struct{}
+}
+
+// NewPasswordPolicy reads the policy from the environment:
+// PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH, PASSWORD_MIN_CLASSES (how many of
+// lowercase, uppercase, digits and symbols must appear) and
+// PASSWORD_BLOCKLIST_FILE, a list of breached passwords, one per line. A
+// blocklist that can't be read is an error rather than silently skipped.
+func NewPasswordPolicy() (ports.PasswordPolicy, error) {
+	policy := &passwordPolicy{
+		minLength:  utils.EnvInt("PASSWORD_MIN_LENGTH", defaultPasswordMinLength),
+		maxLength:  utils.EnvInt("PASSWORD_MAX_LENGTH", defaultPasswordMaxLength),
+		minClasses: utils.EnvInt("PASSWORD_MIN_CLASSES", defaultPasswordMinClasses),
+	}
+
+	if path := os.Getenv("PASSWORD_BLOCKLIST_FILE"); path != "" {
+		blocklist, err := loadBlocklist(path)
+		if err != nil {
+			log.Error().Err(err).Str("path", path).Msg("failed to load password blocklist")
+			return nil, err
+		}
+		policy.blocklist = blocklist
+		log.Info().Int("entries", len(blocklist)).Msg("password blocklist loaded")
+	}
+	return policy, nil
+}
+
+func (p *passwordPolicy) Validate(password, username string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.minLength))
+	}
+	if length > p.maxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters long", p.maxLength))
+	}
+
+	if classes := characterClasses(password); classes < p.minClasses {
+		violations = append(violations, fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.minClasses))
+	}
+
+	if username != "" && strings.EqualFold(password, username) {
+		violations = append(violations, "must not be the username")
+	}
+
+	if _, ok := p.blocklist[strings.ToLower(password)]; ok {
+		violations = append(violations, "is too common or appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// loadBlocklist reads one password per line, compared case-insensitively.
+func loadBlocklist(path string) (map[string]struct{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	blocklist := map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			blocklist[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return blocklist, scanner.Err()
+}
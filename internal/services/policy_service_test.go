@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newHasher keeps tests fast; bcrypt hashes made with MinCost need no rehash.
+func newHasher() ports.PasswordHasher {
+	return hasher.NewBcrypt(bcrypt.MinCost)
+}
+
+func newPolicy(t *testing.T) ports.PasswordPolicy {
+	policy, err := services.NewPasswordPolicy()
+	require.NoError(t, err)
+	return policy
+}
+
+func TestPasswordPolicy_Defaults(t *testing.T) {
+	policy := newPolicy(t)
+
+	assert.NoError(t, policy.Validate("correct horse", "martin"))
+
+	err := policy.Validate("short", "martin")
+	assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+
+	err = policy.Validate("MartinMartin", "martinmartin")
+	var policyErr *domain.PasswordPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, []string{"must not be the username"}, policyErr.Violations)
+}
+
+func TestPasswordPolicy_ComplexityAndBlocklist(t *testing.T) {
+	blocklist := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(blocklist, []byte("Password123!\nqwerty\n"), 0o600))
+	t.Setenv("PASSWORD_BLOCKLIST_FILE", blocklist)
+	t.Setenv("PASSWORD_MIN_CLASSES", "3")
+	t.Setenv("PASSWORD_MIN_LENGTH", "10")
+	policy := newPolicy(t)
+
+	assert.NoError(t, policy.Validate("Tr0ub4dour&3", ""))
+
+	var policyErr *domain.PasswordPolicyError
+	require.ErrorAs(t, policy.Validate("password123!", ""), &policyErr)
+	assert.Len(t, policyErr.Violations, 1, "blocklist is case-insensitive")
+
+	require.ErrorAs(t, policy.Validate("abcdefghijk", ""), &policyErr)
+	assert.Len(t, policyErr.Violations, 1)
+}
+
+func TestPasswordPolicy_MissingBlocklist(t *testing.T) {
+	t.Setenv("PASSWORD_BLOCKLIST_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+
+	_, err := services.NewPasswordPolicy()
+	assert.Error(t, err)
+}
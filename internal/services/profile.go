@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+type profileService struct {
+	ur          ports.UserRepository
+	sr          ports.ScoreRepository
+	maxPageSize int
+	names       *usernameRules
+	// cooldown is the least time between two renames of a user.
+	cooldown time.Duration
+}
+
+func NewProfileService(ur ports.UserRepository, sr ports.ScoreRepository) ports.ProfileService {
+	return &profileService{
+		ur:          ur,
+		sr:          sr,
+		maxPageSize: utils.EnvInt("USERS_MAX_PAGE_SIZE", maxUserPageSize),
+		names:       newUsernameRules(ur),
+		cooldown:    utils.EnvDuration("USERNAME_CHANGE_COOLDOWN", defaultUsernameCooldown),
+	}
+}
+
+func (ps *profileService) GetProfile(userID string) (*domain.User, error) {
+	user, err := ps.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+	return user, nil
+}
+
+func (ps *profileService) UpdateProfile(userID string, update *domain.ProfileUpdate) (*domain.User, error) {
+	trim(update.DisplayName)
+	trim(update.AvatarURL)
+	trim(update.Bio)
+	if update.Country != nil {
+		country := strings.ToUpper(strings.TrimSpace(*update.Country))
+		update.Country = &country
+	}
+
+	user, err := ps.ur.UpdateProfile(userID, update)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to update profile")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID).Msg("profile updated")
+	return user, nil
+}
+
+// GetPublicProfile returns the profile of any user along with their scores.
+func (ps *profileService) GetPublicProfile(userID string) (*domain.PublicProfile, error) {
+	user, err := ps.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	profile := &domain.PublicProfile{User: user}
+
+	scores, err := ps.sr.GetScoresByUserID(userID)
+	if err != nil {
+		// Admins and users registered before any game have no scores.
+		if errors.Is(err, domain.ErrScoreNotFound) {
+			return profile, nil
+		}
+		log.Error().Err(err).Str("user_id", userID).Msg("error fetching user scores")
+		return nil, err
+	}
+
+	profile.Scores = *scores
+	return profile, nil
+}
+
+// GetUsers returns the users among ids that exist, for callers that look up
+// many users at once.
+func (ps *profileService) GetUsers(ids []string) (*[]domain.User, error) {
+	users, err := ps.ur.GetUsersByIDs(ids)
+	if err != nil {
+		log.Error().Err(err).Int("users", len(ids)).Msg("error fetching users")
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListUsers returns a page of users. Out of range pages and sizes are
+// clamped rather than rejected.
+func (ps *profileService) ListUsers(query *domain.UserQuery) (*domain.UserPage, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = defaultUserPageSize
+	}
+	if query.PageSize > ps.maxPageSize {
+		query.PageSize = ps.maxPageSize
+	}
+	query.Search = strings.TrimSpace(query.Search)
+
+	users, total, err := ps.ur.ListUsers(query)
+	if err != nil {
+		log.Error().Err(err).Str("search", query.Search).Msg("failed to list users")
+		return nil, err
+	}
+
+	return &domain.UserPage{
+		Users:    *users,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+func trim(value *string) {
+	if value != nil {
+		*value = strings.TrimSpace(*value)
+	}
+}
+
+// Rename changes the username of a user. Renames are allowed once per
+// cooldown, and the old username stays held for the user for a while.
+func (ps *profileService) Rename(userID, username string) (*domain.User, error) {
+	username, err := ps.names.normalize(username)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ps.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+	if user.Username == username {
+		return user, nil
+	}
+
+	history, err := ps.ur.ListUsernameHistory(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to list username history")
+		return nil, err
+	}
+	if len(*history) > 0 {
+		if wait := time.Until((*history)[0].ChangedAt.Add(ps.cooldown)); wait > 0 {
+			log.Info().Str("user_id", userID).Dur("retry_after", wait).Msg("rename refused during cooldown")
+			return nil, &domain.RenameCooldownError{RetryAfter: wait}
+		}
+	}
+
+	if err := ps.names.checkHeld(username, userID); err != nil {
+		return nil, err
+	}
+
+	if err := ps.ur.RenameUser(userID, username); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Str("username", username).Msg("failed to rename user")
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID).Str("previous_username", user.Username).Str("username", username).Msg("user renamed")
+	return ps.ur.GetUserByID(userID)
+}
+
+// UsernameHistory lists the usernames a user gave up, newest first.
+func (ps *profileService) UsernameHistory(userID string) (*[]domain.UsernameChange, error) {
+	if _, err := ps.ur.GetUserByID(userID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	history, err := ps.ur.ListUsernameHistory(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to list username history")
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateProfile_NormalizesFields(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	name, country := "  Martín ", "ar"
+	ur.On("UpdateProfile", "user1", mock.MatchedBy(func(u *domain.ProfileUpdate) bool {
+		return *u.DisplayName == "Martín" && *u.Country == "AR" && u.Bio == nil
+	})).Return(validUser, nil)
+
+	_, err := ps.UpdateProfile("user1", &domain.ProfileUpdate{DisplayName: &name, Country: &country})
+	assert.NoError(t, err)
+	ur.AssertExpectations(t)
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	sr := new(mocks.ScoreRepositoryMock)
+	ps := services.NewProfileService(ur, sr)
+
+	scores := []domain.Score{
+		{UserID: "user1", GameID: "game1", Points: 300},
+		{UserID: "user1", GameID: "game2", Points: 100},
+		{UserID: "user1", GameID: "game3", Points: 0},
+	}
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	sr.On("GetScoresByUserID", "user1").Return(&scores, nil)
+
+	profile, err := ps.GetPublicProfile("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, validUser, profile.User)
+
+	summary := domain.SummarizeScores(profile.Scores)
+	assert.Equal(t, 2, summary.GamesPlayed)
+	assert.Equal(t, 400, summary.TotalPoints)
+	assert.Equal(t, "game1", summary.Best.GameID)
+}
+
+func TestGetPublicProfile_NoScores(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	sr := new(mocks.ScoreRepositoryMock)
+	ps := services.NewProfileService(ur, sr)
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	sr.On("GetScoresByUserID", "user1").Return((*[]domain.Score)(nil), domain.ErrScoreNotFound)
+
+	profile, err := ps.GetPublicProfile("user1")
+	assert.NoError(t, err)
+	assert.Empty(t, profile.Scores)
+	assert.Nil(t, domain.SummarizeScores(profile.Scores).Best)
+}
+
+func TestListUsers_ClampsPaging(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	users := []domain.User{*validUser}
+	ur.On("ListUsers", &domain.UserQuery{Search: "te", Page: 1, PageSize: 100}).Return(&users, int64(1), nil)
+
+	page, err := ps.ListUsers(&domain.UserQuery{Search: " te ", Page: 0, PageSize: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, 100, page.PageSize)
+	ur.AssertExpectations(t)
+}
+
+func TestRename(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	renamed := &domain.User{ID: "user1", Username: "Martin"}
+	ur.On("GetUserByID", "user1").Return(validUser, nil).Once()
+	ur.On("ListUsernameHistory", "user1").Return(&[]domain.UsernameChange{
+		{UserID: "user1", Username: "old", ChangedAt: time.Now().Add(-60 * 24 * time.Hour)},
+	}, nil)
+	ur.On("IsUsernameHeld", "Martin", "user1", mock.Anything).Return(false, nil)
+	ur.On("RenameUser", "user1", "Martin").Return(nil)
+	ur.On("GetUserByID", "user1").Return(renamed, nil).Once()
+
+	user, err := ps.Rename("user1", " Martin ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Martin", user.Username)
+	ur.AssertExpectations(t)
+}
+
+func TestRename_Cooldown(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	ur.On("ListUsernameHistory", "user1").Return(&[]domain.UsernameChange{
+		{UserID: "user1", Username: "old", ChangedAt: time.Now().Add(-24 * time.Hour)},
+	}, nil)
+
+	_, err := ps.Rename("user1", "martin")
+	assert.ErrorIs(t, err, domain.ErrUsernameCooldown)
+	var cooldown *domain.RenameCooldownError
+	if assert.ErrorAs(t, err, &cooldown) {
+		assert.InDelta(t, (29 * 24 * time.Hour).Seconds(), cooldown.RetryAfter.Seconds(), 60)
+	}
+	ur.AssertNotCalled(t, "RenameUser", mock.Anything, mock.Anything)
+}
+
+func TestRename_Held(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	ur.On("ListUsernameHistory", "user1").Return(&[]domain.UsernameChange{}, nil)
+	ur.On("IsUsernameHeld", "martin", "user1", mock.Anything).Return(true, nil)
+
+	_, err := ps.Rename("user1", "martin")
+	assert.ErrorIs(t, err, domain.ErrUsernameHeld)
+	ur.AssertNotCalled(t, "RenameUser", mock.Anything, mock.Anything)
+}
+
+func TestRename_Reserved(t *testing.T) {
+	t.Setenv("RESERVED_USERNAMES", "scorekeeper, Staff")
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	for _, username := range []string{"root", "STAFF", "deleted-abc"} {
+		_, err := ps.Rename("user1", username)
+		assert.ErrorIs(t, err, domain.ErrUsernameReserved, username)
+	}
+	ur.AssertNotCalled(t, "GetUserByID", mock.Anything)
+}
+
+func TestRename_SameUsername(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	ps := services.NewProfileService(ur, new(mocks.ScoreRepositoryMock))
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+
+	user, err := ps.Rename("user1", validUser.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, validUser, user)
+	ur.AssertNotCalled(t, "RenameUser", mock.Anything, mock.Anything)
+}
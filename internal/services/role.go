@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/rs/zerolog/log"
+)
+
+type roleService struct {
+	rr ports.RoleRepository
+	ur ports.UserRepository
+}
+
+func NewRoleService(rr ports.RoleRepository, ur ports.UserRepository) ports.RoleService {
+	return &roleService{
+		rr: rr,
+		ur: ur,
+	}
+}
+
+func (rs *roleService) ListRoles() (*[]domain.Role, error) {
+	roles, err := rs.rr.ListRoles()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to retrieve roles")
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// AssignRole replaces the role of a user. The new permissions show up in the
+// user's next access token.
+func (rs *roleService) AssignRole(userID, role string) error {
+	if _, err := rs.rr.GetRole(role); err != nil {
+		log.Warn().Err(err).Str("role", role).Msg("error fetching role")
+		return err
+	}
+
+	user, err := rs.ur.GetUserByID(userID)
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return err
+	}
+
+	if user.IsAdmin() && role != domain.RoleAdmin {
+		admins, err := rs.ur.CountUsersByRole(domain.RoleAdmin)
+		if err != nil {
+			log.Error().Err(err).Msg("error counting admins")
+			return err
+		}
+		if admins <= 1 {
+			log.Warn().Str("user_id", userID).Msg("refusing to demote the last admin")
+			return domain.ErrLastAdmin
+		}
+	}
+
+	if err := rs.ur.SetUserRole(userID, role); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Str("role", role).Msg("failed to assign role")
+		return err
+	}
+
+	log.Info().Str("user_id", userID).Str("previous_role", user.Role).Str("role", role).Msg("role assigned")
+	return nil
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var moderatorRole = &domain.Role{Name: domain.RoleModerator}
+
+func TestAssignRole(t *testing.T) {
+	rr := new(mocks.RoleRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rs := services.NewRoleService(rr, ur)
+
+	rr.On("GetRole", domain.RoleModerator).Return(moderatorRole, nil)
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	ur.On("SetUserRole", "user1", domain.RoleModerator).Return(nil)
+
+	err := rs.AssignRole("user1", domain.RoleModerator)
+	assert.NoError(t, err)
+
+	rr.AssertExpectations(t)
+	ur.AssertExpectations(t)
+}
+
+func TestAssignRole_UnknownRole(t *testing.T) {
+	rr := new(mocks.RoleRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rs := services.NewRoleService(rr, ur)
+
+	rr.On("GetRole", "superuser").Return(nil, domain.ErrRoleNotFound)
+
+	err := rs.AssignRole("user1", "superuser")
+	assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+	ur.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+}
+
+func TestAssignRole_LastAdmin(t *testing.T) {
+	rr := new(mocks.RoleRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rs := services.NewRoleService(rr, ur)
+
+	admin := &domain.User{ID: "admin1", Username: "admin", Role: domain.RoleAdmin}
+	rr.On("GetRole", domain.RoleModerator).Return(moderatorRole, nil)
+	ur.On("GetUserByID", "admin1").Return(admin, nil)
+	ur.On("CountUsersByRole", domain.RoleAdmin).Return(int64(1), nil)
+
+	err := rs.AssignRole("admin1", domain.RoleModerator)
+	assert.ErrorIs(t, err, domain.ErrLastAdmin)
+	ur.AssertNotCalled(t, "SetUserRole", mock.Anything, mock.Anything)
+}
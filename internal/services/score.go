@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
@@ -32,16 +33,17 @@ func (ss *ScoreService) Submit(newScore *domain.Score) error {
 		return err
 	}
 
-	if usr.IsAdmin {
+	if usr.IsAdmin() {
 		log.Warn().Str("username", usr.Username).Msg("admin tried to submit score")
 		return domain.ErrUserNotFound
 	}
 
-	_, err = ss.gr.GetGameByID(newScore.GameID)
+	game, err := findGame(ss.gr, newScore.GameID)
 	if err != nil {
 		log.Error().Err(err).Str("game_id", newScore.GameID).Msg("error fetching game")
 		return err
 	}
+	newScore.GameID = game.ID
 
 	existingScore, err := ss.sr.GetScore(newScore.UserID, newScore.GameID)
 	if err != nil {
@@ -51,7 +53,7 @@ func (ss *ScoreService) Submit(newScore *domain.Score) error {
 		}
 	}
 
-	if existingScore != nil && existingScore.Points >= newScore.Points {
+	if existingScore != nil && !domain.BetterScore(newScore.Points, existingScore.Points) {
 		log.Info().
 			Str("user_id", newScore.UserID).
 			Str("game_id", newScore.GameID).
@@ -69,16 +71,17 @@ func (ss *ScoreService) Submit(newScore *domain.Score) error {
 	return nil
 }
 
-func (ss *ScoreService) GetGameScores(gameID string) (*[]domain.Score, error) {
-	_, err := ss.gr.GetGameByID(gameID)
+// GetGameScores returns the leaderboard of a game, identified by ID or slug.
+func (ss *ScoreService) GetGameScores(gameRef string) (*[]domain.Score, error) {
+	game, err := findGame(ss.gr, gameRef)
 	if err != nil {
-		log.Error().Err(err).Str("game_id", gameID).Msg("error checking game existence")
+		log.Error().Err(err).Str("game_id", gameRef).Msg("error checking game existence")
 		return nil, err
 	}
 
-	scores, err := ss.sr.GetScoresByGameID(gameID)
+	scores, err := ss.sr.GetScoresByGameID(game.ID)
 	if err != nil {
-		log.Error().Err(err).Str("game_id", gameID).Msg("error retrieving scores by game")
+		log.Error().Err(err).Str("game_id", game.ID).Msg("error retrieving scores by game")
 		return nil, err
 	}
 
@@ -102,11 +105,104 @@ func (ss *ScoreService) GetUserScores(userID string) (*[]domain.Score, error) {
 	return scores, nil
 }
 
-func (ss *ScoreService) GetGameStats(gameID string) (*dto.ScoreStatisticsDTO, error) {
+// GetScoresByGames returns the best limit scores of several games, identified
+// by ID, in one lookup. Unknown games and games without scores are left out.
+func (ss *ScoreService) GetScoresByGames(gameIDs []string, limit int) (*[]domain.Score, error) {
+	scores, err := ss.sr.GetScoresByGameIDs(gameIDs, limit)
+	if err != nil {
+		log.Error().Err(err).Int("games", len(gameIDs)).Msg("error retrieving scores by games")
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetScoresByUsers returns the scores of several users in one lookup. Unknown
+// users and users without scores are left out.
+func (ss *ScoreService) GetScoresByUsers(userIDs []string) (*[]domain.Score, error) {
+	scores, err := ss.sr.GetScoresByUserIDs(userIDs)
+	if err != nil {
+		log.Error().Err(err).Int("users", len(userIDs)).Msg("error retrieving scores by users")
+		return nil, err
+	}
+	return scores, nil
+}
+
+// StreamGameScores calls fn with every score of the leaderboard of a game,
+// identified by ID or slug, as the scores are read.
+func (ss *ScoreService) StreamGameScores(ctx context.Context, gameRef string, fn func(*domain.Score) error) error {
+	game, err := findGame(ss.gr, gameRef)
+	if err != nil {
+		log.Error().Err(err).Str("game_id", gameRef).Msg("error checking game existence")
+		return err
+	}
+
+	if err := ss.sr.StreamScoresByGameID(ctx, game.ID, fn); err != nil {
+		log.Error().Err(err).Str("game_id", game.ID).Msg("error streaming scores by game")
+		return err
+	}
+	return nil
+}
+
+// StreamUserScores calls fn with every score of a user as the scores are
+// read.
+func (ss *ScoreService) StreamUserScores(ctx context.Context, userID string, fn func(*domain.Score) error) error {
+	if _, err := ss.ur.GetUserByID(userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return err
+	}
+
+	if err := ss.sr.StreamScoresByUserID(ctx, userID, fn); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("error streaming user scores")
+		return err
+	}
+	return nil
+}
+
+// GetUserGameScore returns the score of a user in a game, identified by ID or
+// slug.
+func (ss *ScoreService) GetUserGameScore(userID, gameRef string) (*domain.Score, error) {
+	usr, err := ss.ur.GetUserByID(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("error fetching user")
+		return nil, err
+	}
+
+	game, err := findGame(ss.gr, gameRef)
+	if err != nil {
+		log.Error().Err(err).Str("game_id", gameRef).Msg("error checking game existence")
+		return nil, err
+	}
+
+	// The scores of banned players are hidden, as on leaderboards.
+	if usr.Status.State == domain.StatusBanned {
+		return nil, domain.ErrScoreNotFound
+	}
+
+	score, err := ss.sr.GetScore(usr.ID, game.ID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrScoreNotFound) {
+			log.Error().Err(err).Str("user_id", usr.ID).Str("game_id", game.ID).Msg("error retrieving user game score")
+		}
+		return nil, err
+	}
+
+	score.Username = usr.Username
+	score.GameName = game.Name
+	score.GameSlug = game.Slug
+	return score, nil
+}
+
+// GetGameStats computes the score statistics of a game, identified by ID or slug.
+func (ss *ScoreService) GetGameStats(gameRef string) (*dto.ScoreStatisticsDTO, error) {
+	game, err := findGame(ss.gr, gameRef)
+	if err != nil {
+		log.Error().Err(err).Str("game_id", gameRef).Msg("error checking game existence")
+		return nil, err
+	}
 
-	scores, err := ss.sr.GetScoresByGameID(gameID)
+	scores, err := ss.sr.GetScoresByGameID(game.ID)
 	if err != nil {
-		log.Error().Err(err).Str("game_id", gameID).Msg("error retrieving scores for statistics")
+		log.Error().Err(err).Str("game_id", game.ID).Msg("error retrieving scores for statistics")
 		return nil, err
 	}
 
@@ -118,15 +214,16 @@ func (ss *ScoreService) GetGameStats(gameID string) (*dto.ScoreStatisticsDTO, er
 	mean, median, mode := utils.CalculateStatistics(points)
 
 	log.Info().
-		Str("game_id", gameID).
+		Str("game_id", game.ID).
 		Float64("mean", mean).
 		Float64("median", median).
 		Ints("mode", mode).
 		Msg("score statistics calculated")
 
 	return &dto.ScoreStatisticsDTO{
-		GameID:   gameID,
-		GameName: (*scores)[0].GameName,
+		GameID:   game.ID,
+		GameSlug: game.Slug,
+		GameName: game.Name,
 		Mean:     mean,
 		Median:   median,
 		Mode:     mode,
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -8,13 +9,16 @@ import (
 	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
 	"github.com/Martin-Arias/go-scoring-api/internal/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-var validUser = &domain.User{ID: "user1", Username: "test", IsAdmin: false}
-var validGame = &domain.Game{ID: "game1", Name: "testgame"}
+const gameID = "3f1c2b7e-9a4d-4c5e-8f6a-1b2c3d4e5f60"
 
-var newScore = &domain.Score{UserID: "user1", GameID: "game1", Points: 101}
-var validScore = &domain.Score{UserID: "user1", GameID: "game1", Points: 100}
+var validUser = &domain.User{ID: "user1", Username: "test", Role: domain.RolePlayer}
+var validGame = &domain.Game{ID: gameID, Name: "testgame", Slug: "testgame"}
+
+var newScore = &domain.Score{UserID: "user1", GameID: gameID, Points: 101}
+var validScore = &domain.Score{UserID: "user1", GameID: gameID, Points: 100}
 
 func TestSubmitScore_NewScoreSuccess(t *testing.T) {
 	sr := new(mocks.ScoreRepositoryMock)
@@ -24,8 +28,8 @@ func TestSubmitScore_NewScoreSuccess(t *testing.T) {
 	ss := services.NewScoreService(sr, ur, gr)
 
 	ur.On("GetUserByID", "user1").Return(validUser, nil)
-	gr.On("GetGameByID", "game1").Return(validGame, nil)
-	sr.On("GetScore", "user1", "game1").Return(validScore, nil)
+	gr.On("GetGameByID", gameID).Return(validGame, nil)
+	sr.On("GetScore", "user1", gameID).Return(validScore, nil)
 	sr.On("SubmitScore", newScore).Return(nil)
 
 	err := ss.Submit(newScore)
@@ -58,7 +62,7 @@ func TestSubmitScore_GameNotFound(t *testing.T) {
 	ss := services.NewScoreService(sr, ur, gr)
 
 	ur.On("GetUserByID", "user1").Return(validUser, nil)
-	gr.On("GetGameByID", "game1").Return(nil, errors.New("game not found"))
+	gr.On("GetGameByID", gameID).Return(nil, errors.New("game not found"))
 
 	err := ss.Submit(validScore)
 	assert.Error(t, err)
@@ -72,11 +76,11 @@ func TestSubmitScore_LowerScoreRejected(t *testing.T) {
 
 	ss := services.NewScoreService(sr, ur, gr)
 
-	oldScore := &domain.Score{UserID: "user1", GameID: "game1", Points: 200}
+	oldScore := &domain.Score{UserID: "user1", GameID: gameID, Points: 200}
 
 	ur.On("GetUserByID", "user1").Return(validUser, nil)
-	gr.On("GetGameByID", "game1").Return(validGame, nil)
-	sr.On("GetScore", "user1", "game1").Return(oldScore, nil)
+	gr.On("GetGameByID", gameID).Return(validGame, nil)
+	sr.On("GetScore", "user1", gameID).Return(oldScore, nil)
 
 	err := ss.Submit(validScore)
 	assert.ErrorIs(t, err, domain.ErrScoreNotAllowed)
@@ -90,7 +94,7 @@ func TestGetUserScores_Success(t *testing.T) {
 	ss := services.NewScoreService(sr, ur, gr)
 
 	userScores := &[]domain.Score{
-		{GameID: "game1", Points: 100},
+		{GameID: gameID, Points: 100},
 	}
 
 	ur.On("GetUserByID", "user1").Return(validUser, nil)
@@ -109,16 +113,159 @@ func TestGetGameStats_Success(t *testing.T) {
 	ss := services.NewScoreService(sr, ur, gr)
 
 	scoreList := &[]domain.Score{
-		{GameID: "game1", GameName: "Test Game", Points: 10},
-		{GameID: "game1", GameName: "Test Game", Points: 20},
-		{GameID: "game1", GameName: "Test Game", Points: 10},
+		{GameID: gameID, GameName: "testgame", Points: 10},
+		{GameID: gameID, GameName: "testgame", Points: 20},
+		{GameID: gameID, GameName: "testgame", Points: 10},
 	}
 
-	sr.On("GetScoresByGameID", "game1").Return(scoreList, nil)
+	gr.On("GetGameByID", gameID).Return(validGame, nil)
+	sr.On("GetScoresByGameID", gameID).Return(scoreList, nil)
 
-	stats, err := ss.GetGameStats("game1")
+	stats, err := ss.GetGameStats(gameID)
 	assert.NoError(t, err)
-	assert.Equal(t, "game1", stats.GameID)
-	assert.Equal(t, "Test Game", stats.GameName)
+	assert.Equal(t, gameID, stats.GameID)
+	assert.Equal(t, "testgame", stats.GameName)
 	assert.Equal(t, 13.33, stats.Mean)
 }
+
+func TestSubmitScore_GameBySlug(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	bySlug := &domain.Score{UserID: "user1", GameID: "testgame", Points: 101}
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	gr.On("GetGameBySlug", "testgame").Return(validGame, nil)
+	sr.On("GetScore", "user1", gameID).Return(validScore, nil)
+	sr.On("SubmitScore", &domain.Score{UserID: "user1", GameID: gameID, Points: 101}).Return(nil)
+
+	err := ss.Submit(bySlug)
+	assert.NoError(t, err)
+
+	sr.AssertExpectations(t)
+	gr.AssertExpectations(t)
+}
+
+func TestGetGameScores_BySlug(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	gameScores := &[]domain.Score{
+		{UserID: "user1", GameID: gameID, GameSlug: "testgame", Points: 100},
+	}
+
+	gr.On("GetGameBySlug", "testgame").Return(validGame, nil)
+	sr.On("GetScoresByGameID", gameID).Return(gameScores, nil)
+
+	scores, err := ss.GetGameScores("testgame")
+	assert.NoError(t, err)
+	assert.Equal(t, gameScores, scores)
+}
+
+func TestGetGameStats_GameNotFound(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	gr.On("GetGameBySlug", "missing").Return(nil, domain.ErrGameNotFound)
+
+	stats, err := ss.GetGameStats("missing")
+	assert.ErrorIs(t, err, domain.ErrGameNotFound)
+	assert.Nil(t, stats)
+	sr.AssertNotCalled(t, "GetScoresByGameID", mock.Anything)
+}
+
+func TestGetUserGameScore_Success(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	gr.On("GetGameBySlug", "testgame").Return(validGame, nil)
+	sr.On("GetScore", "user1", gameID).Return(&domain.Score{UserID: "user1", GameID: gameID, Points: 100}, nil)
+
+	score, err := ss.GetUserGameScore("user1", "testgame")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, score.Points)
+	assert.Equal(t, "test", score.Username)
+	assert.Equal(t, "testgame", score.GameSlug)
+}
+
+func TestGetUserGameScore_NotPlayed(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	gr.On("GetGameByID", gameID).Return(validGame, nil)
+	sr.On("GetScore", "user1", gameID).Return((*domain.Score)(nil), domain.ErrScoreNotFound)
+
+	score, err := ss.GetUserGameScore("user1", gameID)
+	assert.ErrorIs(t, err, domain.ErrScoreNotFound)
+	assert.Nil(t, score)
+}
+
+func TestGetUserGameScore_BannedUser(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	banned := &domain.User{ID: "user1", Username: "test", Status: domain.AccountStatus{State: domain.StatusBanned}}
+	ur.On("GetUserByID", "user1").Return(banned, nil)
+	gr.On("GetGameByID", gameID).Return(validGame, nil)
+
+	score, err := ss.GetUserGameScore("user1", gameID)
+	assert.ErrorIs(t, err, domain.ErrScoreNotFound)
+	assert.Nil(t, score)
+	sr.AssertNotCalled(t, "GetScore", mock.Anything, mock.Anything)
+}
+
+func TestStreamGameScores_BySlug(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	gr.On("GetGameBySlug", "testgame").Return(validGame, nil)
+	sr.On("StreamScoresByGameID", mock.Anything, gameID).Return(&[]domain.Score{
+		{UserID: "user1", GameID: gameID, Points: 30},
+		{UserID: "user2", GameID: gameID, Points: 20},
+	}, nil)
+
+	var points []int
+	err := ss.StreamGameScores(context.Background(), "testgame", func(score *domain.Score) error {
+		points = append(points, score.Points)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{30, 20}, points)
+}
+
+func TestStreamUserScores_UserNotFound(t *testing.T) {
+	sr := new(mocks.ScoreRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	gr := new(mocks.GameRepositoryMock)
+
+	ss := services.NewScoreService(sr, ur, gr)
+
+	ur.On("GetUserByID", "ghost").Return((*domain.User)(nil), domain.ErrUserNotFound)
+
+	err := ss.StreamUserScores(context.Background(), "ghost", func(*domain.Score) error { return nil })
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	sr.AssertNotCalled(t, "StreamScoresByUserID", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultKeyRotation = 30 * 24 * time.Hour
+	// keyGracePeriod keeps a retired key verifiable a little longer than the
+	// tokens it signed, to absorb clock skew between instances.
+	keyGracePeriod = 5 * time.Minute
+	// keyReloadInterval limits how often an unknown kid triggers a reload, so
+	// forged tokens cannot be used to hammer the database.
+	keyReloadInterval = 30 * time.Second
+	// keyPublishLead is how long before it signs a key is published, so
+	// verifiers holding a cached JWKS have picked it up by then. It covers the
+	// cache lifetime and the interval rotations are checked at, with room to
+	// spare.
+	keyPublishLead = time.Hour
+	rsaKeyBits     = 2048
+)
+
+type keyService struct {
+	kr        ports.SigningKeyRepository
+	algorithm string
+	rotation  time.Duration
+
+	mu       sync.RWMutex
+	keys     []domain.SigningKey // newest first
+	loadedAt time.Time
+}
+
+func NewKeyService(kr ports.SigningKeyRepository) ports.KeyService {
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = domain.AlgRS256
+	}
+
+	return &keyService{
+		kr:        kr,
+		algorithm: algorithm,
+		rotation:  utils.EnvDuration("JWT_KEY_ROTATION", defaultKeyRotation),
+	}
+}
+
+// Rotate drops expired keys, publishes the next signing key ahead of the
+// rotation of the current one and reloads the key set. A new key only signs
+// once the current one rotates, so verifiers caching the JWKS know it by
+// then. Instances sharing a database pick up each other's keys on their next
+// call.
+func (ks *keyService) Rotate() error {
+	now := time.Now()
+	if err := ks.kr.DeleteExpiredSigningKeys(now); err != nil {
+		log.Error().Err(err).Msg("failed to delete expired signing keys")
+		return err
+	}
+
+	keys, err := ks.kr.ListSigningKeys(now)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load signing keys")
+		return err
+	}
+
+	active := activeKey(*keys, now)
+	var activatesAt time.Time
+	switch {
+	// Nothing can sign: the first start, or every instance was down past a
+	// rotation. The new key has to sign right away.
+	case active == nil:
+		activatesAt = now
+	case pendingKey(*keys, now) == nil && !now.Before(active.RotatesAt.Add(-ks.publishLead())):
+		activatesAt = active.RotatesAt
+	}
+
+	if !activatesAt.IsZero() {
+		key, err := ks.generateKey(activatesAt)
+		if err != nil {
+			log.Error().Err(err).Str("alg", ks.algorithm).Msg("failed to generate signing key")
+			return err
+		}
+		if err := ks.kr.CreateSigningKey(key); err != nil {
+			log.Error().Err(err).Msg("failed to store signing key")
+			return err
+		}
+		log.Info().Str("kid", key.ID).Str("alg", key.Algorithm).Time("activates_at", key.ActivatesAt).Time("rotates_at", key.RotatesAt).Msg("signing key created")
+		*keys = append([]domain.SigningKey{*key}, *keys...)
+	}
+
+	ks.mu.Lock()
+	ks.keys = *keys
+	ks.loadedAt = now
+	ks.mu.Unlock()
+	return nil
+}
+
+// SigningKey returns the key new tokens must be signed with, rotating first
+// if the current one is due.
+func (ks *keyService) SigningKey() (*domain.SigningKey, error) {
+	ks.mu.RLock()
+	key := activeKey(ks.keys, time.Now())
+	ks.mu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	if err := ks.Rotate(); err != nil {
+		return nil, err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key := activeKey(ks.keys, time.Now()); key != nil {
+		return key, nil
+	}
+	return nil, domain.ErrSigningKeyNotFound
+}
+
+// VerificationKey returns the unexpired key with the given kid. An unknown
+// kid may belong to a key another instance just created, so the key set is
+// reloaded, at most once per keyReloadInterval.
+func (ks *keyService) VerificationKey(kid string) (*domain.SigningKey, error) {
+	if key := ks.findKey(kid); key != nil {
+		return key, nil
+	}
+
+	ks.mu.RLock()
+	stale := time.Since(ks.loadedAt) > keyReloadInterval
+	ks.mu.RUnlock()
+	if !stale {
+		return nil, domain.ErrSigningKeyNotFound
+	}
+
+	if err := ks.reload(); err != nil {
+		return nil, err
+	}
+	if key := ks.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, domain.ErrSigningKeyNotFound
+}
+
+// JWKS returns the public keys of every unexpired signing key.
+func (ks *keyService) JWKS() ([]domain.JSONWebKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	jwks := make([]domain.JSONWebKey, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if !now.Before(key.ExpiresAt) {
+			continue
+		}
+
+		jwk := domain.JSONWebKey{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Algorithm,
+		}
+		switch pub := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			log.Warn().Str("kid", key.ID).Msg("signing key of unknown type skipped from jwks")
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks, nil
+}
+
+func (ks *keyService) reload() error {
+	now := time.Now()
+	keys, err := ks.kr.ListSigningKeys(now)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load signing keys")
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = *keys
+	ks.loadedAt = now
+	ks.mu.Unlock()
+	return nil
+}
+
+func (ks *keyService) findKey(kid string) *domain.SigningKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	for i := range ks.keys {
+		if ks.keys[i].ID == kid && now.Before(ks.keys[i].ExpiresAt) {
+			return &ks.keys[i]
+		}
+	}
+	return nil
+}
+
+// publishLead is keyPublishLead, shortened for rotation periods so short
+// that keys would otherwise be published for most of their life.
+func (ks *keyService) publishLead() time.Duration {
+	return min(keyPublishLead, ks.rotation/2)
+}
+
+func (ks *keyService) generateKey(activatesAt time.Time) (*domain.SigningKey, error) {
+	var signer crypto.Signer
+	switch ks.algorithm {
+	case domain.AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		signer = key
+	case domain.AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer = key
+	default:
+		return nil, domain.ErrUnsupportedAlgorithm
+	}
+
+	rotatesAt := activatesAt.Add(ks.rotation)
+	return &domain.SigningKey{
+		Algorithm:   ks.algorithm,
+		PrivateKey:  signer,
+		CreatedAt:   time.Now(),
+		ActivatesAt: activatesAt,
+		RotatesAt:   rotatesAt,
+		ExpiresAt:   rotatesAt.Add(accessTokenTTL() + keyGracePeriod),
+	}, nil
+}
+
+// activeKey returns the newest key that may sign tokens now.
+func activeKey(keys []domain.SigningKey, now time.Time) *domain.SigningKey {
+	for i := range keys {
+		if !now.Before(keys[i].ActivatesAt) && now.Before(keys[i].RotatesAt) {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// pendingKey returns a published key that does not sign yet.
+func pendingKey(keys []domain.SigningKey, now time.Time) *domain.SigningKey {
+	for i := range keys {
+		if now.Before(keys[i].ActivatesAt) {
+			return &keys[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package services_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newKeyService returns a key service whose repository starts empty, so the
+// first signature creates a key.
+func newKeyService() ports.KeyService {
+	kr := new(mocks.SigningKeyRepositoryMock)
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{}, nil)
+	kr.On("CreateSigningKey", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.SigningKey).ID = uuid.NewString()
+	}).Return(nil)
+	return services.NewKeyService(kr)
+}
+
+func TestRotate_CreatesKeyWhenNoneIsActive(t *testing.T) {
+	kr := new(mocks.SigningKeyRepositoryMock)
+	ks := services.NewKeyService(kr)
+
+	_, old := newEd25519Key(t)
+	retired := domain.SigningKey{
+		ID:         "old",
+		Algorithm:  domain.AlgEdDSA,
+		PrivateKey: old,
+		RotatesAt:  time.Now().Add(-time.Minute),
+		ExpiresAt:  time.Now().Add(10 * time.Minute),
+	}
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{retired}, nil)
+	kr.On("CreateSigningKey", mock.MatchedBy(func(k *domain.SigningKey) bool {
+		return k.Algorithm == domain.AlgRS256 && k.RotatesAt.After(time.Now()) && k.ExpiresAt.After(k.RotatesAt)
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.SigningKey).ID = "new"
+	}).Return(nil)
+
+	assert.NoError(t, ks.Rotate())
+
+	key, err := ks.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "new", key.ID)
+
+	// The retired key still verifies the tokens it signed.
+	key, err = ks.VerificationKey("old")
+	assert.NoError(t, err)
+	assert.Equal(t, "old", key.ID)
+
+	jwks, err := ks.JWKS()
+	assert.NoError(t, err)
+	assert.Len(t, jwks, 2)
+	assert.Equal(t, "RSA", jwks[0].KeyType)
+	assert.NotEmpty(t, jwks[0].Modulus)
+	assert.Equal(t, "AQAB", jwks[0].Exponent)
+	assert.Equal(t, "OKP", jwks[1].KeyType)
+	assert.Equal(t, "Ed25519", jwks[1].Curve)
+
+	kr.AssertExpectations(t)
+}
+
+func TestRotate_KeepsActiveKey(t *testing.T) {
+	kr := new(mocks.SigningKeyRepositoryMock)
+	ks := services.NewKeyService(kr)
+
+	_, priv := newEd25519Key(t)
+	active := domain.SigningKey{
+		ID:         "active",
+		Algorithm:  domain.AlgEdDSA,
+		PrivateKey: priv,
+		RotatesAt:  time.Now().Add(24 * time.Hour),
+		ExpiresAt:  time.Now().Add(25 * time.Hour),
+	}
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{active}, nil)
+
+	assert.NoError(t, ks.Rotate())
+
+	key, err := ks.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "active", key.ID)
+	kr.AssertNotCalled(t, "CreateSigningKey", mock.Anything)
+}
+
+func TestRotate_PublishesNextKeyBeforeItSigns(t *testing.T) {
+	kr := new(mocks.SigningKeyRepositoryMock)
+	ks := services.NewKeyService(kr)
+
+	_, priv := newEd25519Key(t)
+	rotatesAt := time.Now().Add(30 * time.Minute)
+	active := domain.SigningKey{
+		ID:         "active",
+		Algorithm:  domain.AlgEdDSA,
+		PrivateKey: priv,
+		RotatesAt:  rotatesAt,
+		ExpiresAt:  rotatesAt.Add(time.Hour),
+	}
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{active}, nil)
+	kr.On("CreateSigningKey", mock.MatchedBy(func(k *domain.SigningKey) bool {
+		return k.ActivatesAt.Equal(rotatesAt) && k.RotatesAt.After(rotatesAt)
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.SigningKey).ID = "next"
+	}).Return(nil)
+
+	assert.NoError(t, ks.Rotate())
+
+	// The next key is published, but the current one keeps signing until it
+	// rotates.
+	key, err := ks.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "active", key.ID)
+
+	jwks, err := ks.JWKS()
+	assert.NoError(t, err)
+	assert.Len(t, jwks, 2)
+
+	kr.AssertExpectations(t)
+}
+
+func TestRotate_SwitchesToPublishedKeyOnceDue(t *testing.T) {
+	kr := new(mocks.SigningKeyRepositoryMock)
+	ks := services.NewKeyService(kr)
+
+	_, oldKey := newEd25519Key(t)
+	_, nextKey := newEd25519Key(t)
+	now := time.Now()
+	retired := domain.SigningKey{
+		ID:         "old",
+		Algorithm:  domain.AlgEdDSA,
+		PrivateKey: oldKey,
+		RotatesAt:  now.Add(-time.Minute),
+		ExpiresAt:  now.Add(time.Hour),
+	}
+	next := domain.SigningKey{
+		ID:          "next",
+		Algorithm:   domain.AlgEdDSA,
+		PrivateKey:  nextKey,
+		ActivatesAt: now.Add(-time.Minute),
+		RotatesAt:   now.Add(24 * time.Hour),
+		ExpiresAt:   now.Add(25 * time.Hour),
+	}
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{next, retired}, nil)
+
+	assert.NoError(t, ks.Rotate())
+
+	key, err := ks.SigningKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "next", key.ID)
+	kr.AssertNotCalled(t, "CreateSigningKey", mock.Anything)
+}
+
+func TestRotate_UnsupportedAlgorithm(t *testing.T) {
+	t.Setenv("JWT_SIGNING_ALG", "HS256")
+
+	kr := new(mocks.SigningKeyRepositoryMock)
+	ks := services.NewKeyService(kr)
+	kr.On("DeleteExpiredSigningKeys", mock.Anything).Return(nil)
+	kr.On("ListSigningKeys", mock.Anything).Return(&[]domain.SigningKey{}, nil)
+
+	assert.ErrorIs(t, ks.Rotate(), domain.ErrUnsupportedAlgorithm)
+}
+
+func TestParseAccessToken_EdDSA(t *testing.T) {
+	t.Setenv("JWT_SIGNING_ALG", domain.AlgEdDSA)
+
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	tr.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	ur.On("GetAccountStatus", "user1").Return(&domain.AccountStatus{State: domain.StatusActive}, nil)
+
+	tokens, err := ts.IssueTokens(validUser)
+	assert.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokens.AccessToken, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AlgEdDSA, token.Method.Alg())
+	assert.NotEmpty(t, token.Header["kid"])
+
+	claims, err := ts.ParseAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", claims.UserID)
+}
+
+func TestParseAccessToken_RejectsHMAC(t *testing.T) {
+	ks := newKeyService()
+	ts := services.NewTokenService(new(mocks.TokenRepositoryMock), new(mocks.UserRepositoryMock), new(mocks.RoleRepositoryMock), ks)
+
+	key, err := ks.SigningKey()
+	assert.NoError(t, err)
+
+	// A token "signed" with a published key ID and a shared secret must not
+	// be accepted, whatever the secret is.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti": "token-1",
+		"uid": "user1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = key.ID
+	tokenStr, err := token.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = ts.ParseAccessToken(tokenStr)
+	assert.ErrorIs(t, err, domain.ErrTokenInvalid)
+}
+
+func newEd25519Key(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	return pub, priv
+}
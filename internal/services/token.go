@@ -0,0 +1,317 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type tokenService struct {
+	tr         ports.TokenRepository
+	ur         ports.UserRepository
+	rr         ports.RoleRepository
+	ks         ports.KeyService
+	refreshTTL time.Duration
+}
+
+func NewTokenService(tr ports.TokenRepository, ur ports.UserRepository, rr ports.RoleRepository, ks ports.KeyService) ports.TokenService {
+	return &tokenService{
+		tr:         tr,
+		ur:         ur,
+		rr:         rr,
+		ks:         ks,
+		refreshTTL: utils.EnvDuration("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL),
+	}
+}
+
+// IssueTokens starts a new session for the user: a short-lived access token
+// and a refresh token that can be exchanged for the next pair.
+func (ts *tokenService) IssueTokens(user *domain.User) (*domain.TokenPair, error) {
+	if err := user.Status.Check(time.Now()); err != nil {
+		log.Info().Err(err).Str("user_id", user.ID).Msg("refusing to issue tokens to a restricted user")
+		return nil, err
+	}
+
+	accessToken, err := ts.accessToken(user)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to generate access token")
+		return nil, err
+	}
+
+	refreshToken, hash, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate refresh token")
+		return nil, err
+	}
+
+	if err := ts.tr.CreateRefreshToken(&domain.RefreshToken{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(ts.refreshTTL),
+	}, hash); err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to store refresh token")
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:        accessToken,
+		RefreshToken:       refreshToken,
+		ExpiresIn:          accessTokenTTL(),
+		MustChangePassword: user.MustChangePassword,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked, so each refresh token can only be used once.
+func (ts *tokenService) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	stored, err := ts.tr.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		log.Warn().Err(err).Msg("refresh token lookup failed")
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		// A rotated token showing up again means it was copied. Kill every
+		// session of the user so the copy becomes useless.
+		log.Warn().Str("user_id", stored.UserID).Msg("revoked refresh token reused, revoking all sessions")
+		if err := ts.tr.RevokeUserRefreshTokens(stored.UserID); err != nil {
+			log.Error().Err(err).Str("user_id", stored.UserID).Msg("failed to revoke user sessions")
+		}
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		log.Info().Str("user_id", stored.UserID).Msg("expired refresh token presented")
+		return nil, domain.ErrRefreshTokenInvalid
+	}
+
+	// Reload the user so role changes apply from the next access token on.
+	user, err := ts.ur.GetUserByID(stored.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", stored.UserID).Msg("error fetching user for refresh")
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if err := user.Status.Check(time.Now()); err != nil {
+		log.Info().Err(err).Str("user_id", user.ID).Msg("refresh refused for a restricted user")
+		return nil, err
+	}
+
+	accessToken, err := ts.accessToken(user)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", user.ID).Msg("failed to generate access token")
+		return nil, err
+	}
+
+	nextToken, nextHash, err := newOpaqueToken()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate refresh token")
+		return nil, err
+	}
+
+	if err := ts.tr.RotateRefreshToken(stored.ID, &domain.RefreshToken{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(ts.refreshTTL),
+	}, nextHash); err != nil {
+		log.Warn().Err(err).Str("user_id", user.ID).Msg("failed to rotate refresh token")
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:        accessToken,
+		RefreshToken:       nextToken,
+		ExpiresIn:          accessTokenTTL(),
+		MustChangePassword: user.MustChangePassword,
+	}, nil
+}
+
+// Logout revokes the access token behind claims and, when given, the refresh
+// token of the same session.
+func (ts *tokenService) Logout(claims *domain.AccessClaims, refreshToken string) error {
+	if err := ts.tr.RevokeAccessToken(claims.TokenID, claims.ExpiresAt); err != nil {
+		log.Error().Err(err).Str("user_id", claims.UserID).Msg("failed to revoke access token")
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := ts.tr.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		log.Warn().Err(err).Str("user_id", claims.UserID).Msg("unknown refresh token on logout")
+		return nil
+	}
+	if stored.UserID != claims.UserID {
+		log.Warn().Str("user_id", claims.UserID).Msg("refresh token of another user presented on logout")
+		return nil
+	}
+
+	if err := ts.tr.RevokeRefreshToken(stored.ID); err != nil {
+		log.Error().Err(err).Str("user_id", claims.UserID).Msg("failed to revoke refresh token")
+		return err
+	}
+	return nil
+}
+
+// RevokeUserSessions ends every session of the user, e.g. after a password
+// change: refresh tokens are revoked and access tokens issued until now are
+// refused by ParseAccessToken.
+func (ts *tokenService) RevokeUserSessions(userID string) error {
+	if err := ts.tr.RevokeUserRefreshTokens(userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to revoke user sessions")
+		return err
+	}
+	return nil
+}
+
+// ParseAccessToken verifies an access token and checks that it was not revoked
+// and that its user is still allowed to sign in. A ban, a suspension or the
+// end of every session of the user thus applies to access tokens issued
+// before it.
+func (ts *tokenService) ParseAccessToken(tokenStr string) (*domain.AccessClaims, error) {
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := ts.ks.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// The algorithm is pinned by the key, never taken from the token.
+		if t.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return key.PrivateKey.Public(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	claims := &domain.AccessClaims{}
+	claims.TokenID, _ = mapClaims["jti"].(string)
+	claims.UserID, _ = mapClaims["uid"].(string)
+	claims.Username, _ = mapClaims["username"].(string)
+	claims.Role, _ = mapClaims["role"].(string)
+	if perms, ok := mapClaims["perms"].([]interface{}); ok {
+		for _, p := range perms {
+			if perm, ok := p.(string); ok {
+				claims.Permissions = append(claims.Permissions, domain.Permission(perm))
+			}
+		}
+	}
+	claims.MustChangePassword, _ = mapClaims["pwd_change"].(bool)
+	if iat, ok := mapClaims["iat"].(float64); ok {
+		claims.IssuedAt = time.Unix(int64(iat), 0)
+	}
+	if exp, ok := mapClaims["exp"].(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+
+	// Tokens without an ID could never be revoked, so they are not accepted.
+	if claims.TokenID == "" || claims.ExpiresAt.IsZero() {
+		return nil, domain.ErrTokenInvalid
+	}
+
+	revoked, err := ts.tr.IsAccessTokenRevoked(claims.TokenID)
+	if err != nil {
+		log.Error().Err(err).Str("jti", claims.TokenID).Msg("failed to check token revocation")
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	status, err := ts.ur.GetAccountStatus(claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrTokenInvalid
+		}
+		log.Error().Err(err).Str("user_id", claims.UserID).Msg("failed to check account status")
+		return nil, err
+	}
+	if err := status.Check(time.Now()); err != nil {
+		return nil, err
+	}
+	// iat only has whole seconds: the tokens issued right after the sessions
+	// were ended, e.g. by a password change, share its second.
+	if status.SessionsRevokedAt != nil && claims.IssuedAt.Before(status.SessionsRevokedAt.Truncate(time.Second)) {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// accessToken signs an access token carrying the permissions of the user's role.
+func (ts *tokenService) accessToken(user *domain.User) (string, error) {
+	role, err := ts.rr.GetRole(user.Role)
+	if err != nil {
+		log.Error().Err(err).Str("role", user.Role).Msg("error fetching role")
+		return "", err
+	}
+
+	key, err := ts.ks.SigningKey()
+	if err != nil {
+		log.Error().Err(err).Msg("no signing key available")
+		return "", err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":      uuid.NewString(),
+		"uid":      user.ID,
+		"username": user.Username,
+		"role":     user.Role,
+		"perms":    role.Permissions,
+		"iat":      now.Unix(),
+		"exp":      now.Add(accessTokenTTL()).Unix(),
+	}
+	if user.MustChangePassword {
+		claims["pwd_change"] = true
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+func accessTokenTTL() time.Duration {
+	return utils.EnvDuration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// newOpaqueToken returns a random opaque token, such as a refresh or reset
+// token, and the hash under which it is stored.
+func newOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,180 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIssueTokens(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer, Permissions: []domain.Permission{domain.PermGamesRead}}, nil)
+
+	var storedHash string
+	tr.On("CreateRefreshToken", mock.MatchedBy(func(rt *domain.RefreshToken) bool {
+		return rt.UserID == "user1" && rt.ExpiresAt.After(time.Now())
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+	}).Return(nil)
+
+	tokens, err := ts.IssueTokens(validUser)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEqual(t, tokens.RefreshToken, storedHash, "refresh token must be stored hashed")
+
+	tr.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	ur.On("GetAccountStatus", "user1").Return(&domain.AccountStatus{State: domain.StatusActive}, nil)
+	claims, err := ts.ParseAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", claims.UserID)
+	assert.Equal(t, domain.RolePlayer, claims.Role)
+	assert.Equal(t, []domain.Permission{domain.PermGamesRead}, claims.Permissions)
+	assert.NotEmpty(t, claims.TokenID)
+
+	tr.AssertExpectations(t)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	stored := &domain.RefreshToken{ID: "rt1", UserID: "user1", ExpiresAt: time.Now().Add(time.Hour)}
+	tr.On("GetRefreshToken", mock.Anything).Return(stored, nil)
+	ur.On("GetUserByID", "user1").Return(validUser, nil)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("RotateRefreshToken", "rt1", mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := ts.Refresh("old-token")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEqual(t, "old-token", tokens.RefreshToken)
+
+	tr.AssertExpectations(t)
+	ur.AssertExpectations(t)
+}
+
+func TestRefresh_ReusedTokenRevokesSessions(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	revokedAt := time.Now().Add(-time.Minute)
+	stored := &domain.RefreshToken{ID: "rt1", UserID: "user1", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	tr.On("GetRefreshToken", mock.Anything).Return(stored, nil)
+	tr.On("RevokeUserRefreshTokens", "user1").Return(nil)
+
+	tokens, err := ts.Refresh("old-token")
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+	assert.Nil(t, tokens)
+
+	tr.AssertExpectations(t)
+	tr.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_ExpiredToken(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	stored := &domain.RefreshToken{ID: "rt1", UserID: "user1", ExpiresAt: time.Now().Add(-time.Hour)}
+	tr.On("GetRefreshToken", mock.Anything).Return(stored, nil)
+
+	tokens, err := ts.Refresh("old-token")
+	assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+	assert.Nil(t, tokens)
+}
+
+func TestLogout_RevokesAccessAndRefreshToken(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	claims := &domain.AccessClaims{TokenID: "jti1", UserID: "user1", ExpiresAt: time.Now().Add(time.Minute)}
+	tr.On("RevokeAccessToken", "jti1", claims.ExpiresAt).Return(nil)
+	tr.On("GetRefreshToken", mock.Anything).Return(&domain.RefreshToken{ID: "rt1", UserID: "user1"}, nil)
+	tr.On("RevokeRefreshToken", "rt1").Return(nil)
+
+	err := ts.Logout(claims, "refresh-token")
+	assert.NoError(t, err)
+
+	tr.AssertExpectations(t)
+}
+
+func TestLogout_IgnoresForeignRefreshToken(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	claims := &domain.AccessClaims{TokenID: "jti1", UserID: "user1", ExpiresAt: time.Now().Add(time.Minute)}
+	tr.On("RevokeAccessToken", "jti1", claims.ExpiresAt).Return(nil)
+	tr.On("GetRefreshToken", mock.Anything).Return(&domain.RefreshToken{ID: "rt2", UserID: "user2"}, nil)
+
+	err := ts.Logout(claims, "refresh-token")
+	assert.NoError(t, err)
+
+	tr.AssertNotCalled(t, "RevokeRefreshToken", "rt2")
+}
+
+func TestIssueTokens_MustChangePassword(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	rr.On("GetRole", domain.RoleAdmin).Return(&domain.Role{Name: domain.RoleAdmin}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	tr.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+	ur.On("GetAccountStatus", "admin1").Return(&domain.AccountStatus{State: domain.StatusActive}, nil)
+
+	admin := &domain.User{ID: "admin1", Username: "admin", Role: domain.RoleAdmin, MustChangePassword: true}
+	tokens, err := ts.IssueTokens(admin)
+	assert.NoError(t, err)
+	assert.True(t, tokens.MustChangePassword)
+
+	claims, err := ts.ParseAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.True(t, claims.MustChangePassword)
+}
+
+func TestParseAccessToken_RefusesTokensOfEndedSessions(t *testing.T) {
+	tr := new(mocks.TokenRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+	tr.On("IsAccessTokenRevoked", mock.Anything).Return(false, nil)
+
+	tokens, err := ts.IssueTokens(validUser)
+	assert.NoError(t, err)
+
+	// Sessions ended after the token was issued, e.g. by a password reset.
+	later := time.Now().Add(2 * time.Second)
+	ur.On("GetAccountStatus", "user1").Return(&domain.AccountStatus{State: domain.StatusActive, SessionsRevokedAt: &later}, nil).Once()
+	_, err = ts.ParseAccessToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+
+	// Tokens issued in the same second, like those a password change returns,
+	// are still accepted.
+	now := time.Now()
+	ur.On("GetAccountStatus", "user1").Return(&domain.AccountStatus{State: domain.StatusActive, SessionsRevokedAt: &now}, nil).Once()
+	_, err = ts.ParseAccessToken(tokens.AccessToken)
+	assert.NoError(t, err)
+}
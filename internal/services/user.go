@@ -2,35 +2,60 @@ package services
 
 import (
 	"context"
-	"os"
+	"errors"
 	"time"
 
 	"github.com/Martin-Arias/go-scoring-api/internal/domain"
 	"github.com/Martin-Arias/go-scoring-api/internal/ports"
-	"github.com/golang-jwt/jwt"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
 	ur ports.UserRepository
+	ts ports.TokenService
+	ls ports.LockoutService
+	ph ports.PasswordHasher
+	pp ports.PasswordPolicy
+
+	names *usernameRules
 }
 
-func NewUserService(ur ports.UserRepository) ports.UserService {
+func NewUserService(ur ports.UserRepository, ts ports.TokenService, ls ports.LockoutService, ph ports.PasswordHasher, pp ports.PasswordPolicy) ports.UserService {
 	return &UserService{
 		ur: ur,
+		ts: ts,
+		ls: ls,
+		ph: ph,
+		pp: pp,
+
+		names: newUsernameRules(ur),
 	}
 }
 
-func (us *UserService) RegisterUser(username, password string) (*domain.User, error) {
+func (us *UserService) RegisterUser(username, email, password string) (*domain.User, error) {
+	normalized, err := us.names.normalize(username)
+	if err != nil {
+		log.Info().Err(err).Str("username", username).Msg("registration with an invalid username")
+		return nil, err
+	}
+	username = normalized
+
+	if err := us.pp.Validate(password, username); err != nil {
+		log.Info().Err(err).Str("username", username).Msg("registration with a password against the policy")
+		return nil, err
+	}
+
+	if err := us.names.checkHeld(username, ""); err != nil {
+		return nil, err
+	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := us.ph.Hash(password)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to hash password")
 		return nil, err
 	}
 
-	createdUser, err := us.ur.CreateUserWithInitialScores(context.Background(), username, string(hash))
+	createdUser, err := us.ur.CreateUserWithInitialScores(context.Background(), username, email, hash)
 	if err != nil {
 		log.Error().Err(err).Str("username", username).Msg("failed to register user")
 		return nil, err
@@ -39,41 +64,79 @@ func (us *UserService) RegisterUser(username, password string) (*domain.User, er
 	return createdUser, nil
 }
 
-func (us *UserService) LoginUser(username, password string) (string, error) {
+// LoginUser checks the credentials of a user logging in from ip. Failures
+// are counted per username and per IP, and while either is locked out a
+// *domain.LockedError is returned without looking at the password.
+func (us *UserService) LoginUser(username, password, ip string) (*domain.TokenPair, error) {
+	if err := us.ls.Check(username, ip); err != nil {
+		return nil, err
+	}
+
 	user, err := us.ur.GetUserCreds(username)
 	if err != nil {
 		log.Error().Err(err).Str("username", username).Msg("failed to fetch user")
-		return "", err
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Unknown usernames count too, or they could be told apart.
+			if err := us.ls.RecordFailure(username, ip); err != nil {
+				return nil, err
+			}
+			return nil, domain.ErrAuthInvalid
+		}
+		return nil, err
+	}
+
+	if !us.ph.Verify(user.PasswordHash, password) {
+		log.Info().Str("username", username).Str("ip", ip).Msg("login failed: invalid password")
+		if err := us.ls.RecordFailure(username, ip); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrAuthInvalid
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		log.Info().Str("username", username).Msg("login failed: invalid password")
-		return "", domain.ErrAuthInvalid
+	if err := user.Status.Check(time.Now()); err != nil {
+		log.Info().Err(err).Str("username", username).Msg("login refused for a restricted user")
+		return nil, err
+	}
+
+	if err := us.ls.RecordSuccess(username); err != nil {
+		return nil, err
 	}
 
-	token, err := GenerateToken(&domain.User{
+	// The plain password is only at hand during a login, which makes it the
+	// moment to move an outdated hash to the current algorithm and cost.
+	if us.ph.NeedsRehash(user.PasswordHash) {
+		us.rehash(user.ID, password, user.MustChangePassword)
+	}
+
+	tokens, err := us.ts.IssueTokens(&domain.User{
 		ID:       user.ID,
 		Username: user.Username,
-		IsAdmin:  user.IsAdmin,
+		Role:     user.Role,
+
+		MustChangePassword: user.MustChangePassword,
+		Status:             user.Status,
 	})
 
 	if err != nil {
-		log.Error().Err(err).Str("username", username).Msg("failed to generate token")
-		return "", domain.ErrUnexpected
+		log.Error().Err(err).Str("username", username).Msg("failed to issue tokens")
+		return nil, domain.ErrUnexpected
 	}
 
-	return token, nil
+	return tokens, nil
 }
 
-func GenerateToken(user *domain.User) (string, error) {
-	var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-	claims := jwt.MapClaims{
-		"uid":      user.ID,
-		"username": user.Username,
-		"admin":    user.IsAdmin,
-		"iat":      time.Now().Unix(),
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
+// rehash stores a fresh hash of password. Failures are only logged: the
+// login already succeeded and the next one tries again.
+func (us *UserService) rehash(userID, password string, mustChange bool) {
+	hash, err := us.ph.Hash(password)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to rehash password")
+		return
+	}
+
+	if err := us.ur.UpdatePassword(userID, hash, mustChange); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("failed to store rehashed password")
+		return
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	log.Info().Str("user_id", userID).Msg("password hash upgraded")
 }
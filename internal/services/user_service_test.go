@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/core/auth"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/hasher"
+	mocks "github.com/Martin-Arias/go-scoring-api/internal/mocks/repository"
+	"github.com/Martin-Arias/go-scoring-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2 keeps the memory cost of argon2id low enough for tests.
+var fastArgon2 = hasher.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestLoginUser_RehashesOutdatedHash(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+	ph := hasher.NewArgon2id(fastArgon2)
+	us := services.NewUserService(ur, ts, services.NewLockoutService(lr, ur), ph, newPolicy(t))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{}, nil)
+	lr.On("ClearLoginAttempts", "user:test").Return(nil)
+	ur.On("GetUserCreds", "test").Return(&auth.AuthUserData{ID: "user1", Username: "test", PasswordHash: string(hash), Role: domain.RolePlayer, MustChangePassword: true}, nil)
+	ur.On("UpdatePassword", "user1", mock.MatchedBy(func(h string) bool {
+		return !ph.NeedsRehash(h) && ph.Verify(h, "secret")
+	}), true).Return(nil)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := us.LoginUser("test", "secret", "10.0.0.1")
+	assert.NoError(t, err)
+	ur.AssertExpectations(t)
+}
+
+func TestLoginUser_CurrentHashIsKept(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	rr := new(mocks.RoleRepositoryMock)
+	ts := services.NewTokenService(tr, ur, rr, newKeyService())
+	ph := hasher.NewArgon2id(fastArgon2)
+	us := services.NewUserService(ur, ts, services.NewLockoutService(lr, ur), ph, newPolicy(t))
+
+	hash, _ := ph.Hash("secret")
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{}, nil)
+	lr.On("ClearLoginAttempts", "user:test").Return(nil)
+	ur.On("GetUserCreds", "test").Return(&auth.AuthUserData{ID: "user1", Username: "test", PasswordHash: hash, Role: domain.RolePlayer}, nil)
+	rr.On("GetRole", domain.RolePlayer).Return(&domain.Role{Name: domain.RolePlayer}, nil)
+	tr.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := us.LoginUser("test", "secret", "10.0.0.1")
+	assert.NoError(t, err)
+	ur.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegisterUser_PasswordPolicy(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	us := services.NewUserService(ur, nil, nil, newHasher(), newPolicy(t))
+
+	_, err := us.RegisterUser("martin", "", "short")
+	assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+	ur.AssertNotCalled(t, "CreateUserWithInitialScores", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegisterUser_ReservedUsername(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	us := services.NewUserService(ur, nil, nil, newHasher(), newPolicy(t))
+
+	_, err := us.RegisterUser("ADMIN", "", "s3cure-pass")
+	assert.ErrorIs(t, err, domain.ErrUsernameReserved)
+	_, err = us.RegisterUser("guest-1234", "", "s3cure-pass")
+	assert.ErrorIs(t, err, domain.ErrUsernameReserved)
+	_, err = us.RegisterUser("bad name", "", "s3cure-pass")
+	assert.ErrorIs(t, err, domain.ErrUsernameInvalid)
+	ur.AssertNotCalled(t, "CreateUserWithInitialScores", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegisterUser_HeldUsername(t *testing.T) {
+	ur := new(mocks.UserRepositoryMock)
+	us := services.NewUserService(ur, nil, nil, newHasher(), newPolicy(t))
+
+	ur.On("IsUsernameHeld", "martin", "", mock.Anything).Return(true, nil)
+
+	_, err := us.RegisterUser(" martin ", "", "s3cure-pass")
+	assert.ErrorIs(t, err, domain.ErrUsernameHeld)
+	ur.AssertNotCalled(t, "CreateUserWithInitialScores", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginUser_Banned(t *testing.T) {
+	lr := new(mocks.LoginAttemptRepositoryMock)
+	ur := new(mocks.UserRepositoryMock)
+	tr := new(mocks.TokenRepositoryMock)
+	ts := services.NewTokenService(tr, ur, new(mocks.RoleRepositoryMock), newKeyService())
+	us := services.NewUserService(ur, ts, services.NewLockoutService(lr, ur), newHasher(), newPolicy(t))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	lr.On("ListLoginAttempts", mock.Anything).Return(&[]domain.LoginAttempt{}, nil)
+	ur.On("GetUserCreds", "test").Return(&auth.AuthUserData{
+		ID:           "user1",
+		Username:     "test",
+		PasswordHash: string(hash),
+		Role:         domain.RolePlayer,
+		Status:       domain.AccountStatus{State: domain.StatusBanned, Reason: "cheating"},
+	}, nil)
+
+	_, err := us.LoginUser("test", "secret", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrUserBanned)
+	tr.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/ports"
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultUsernameCooldown = 30 * 24 * time.Hour
+	defaultUsernameHold     = 90 * 24 * time.Hour
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{1,28}[A-Za-z0-9]$`)
+
+// usernameRules decides which usernames users may pick when they register,
+// upgrade a guest or rename themselves. Usernames the API generates itself,
+// such as those of guests, don't go through them.
+type usernameRules struct {
+	ur       ports.UserRepository
+	reserved map[string]bool
+	// hold is how long a username someone gave up stays out of reach of
+	// other users.
+	hold time.Duration
+}
+
+// newUsernameRules reads the extra reserved names from RESERVED_USERNAMES
+// (comma separated) and the hold period from USERNAME_HOLD.
+func newUsernameRules(ur ports.UserRepository) *usernameRules {
+	reserved := map[string]bool{}
+	for _, name := range domain.ReservedUsernames {
+		reserved[domain.UsernameKey(name)] = true
+	}
+	for _, name := range strings.Split(os.Getenv("RESERVED_USERNAMES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			reserved[domain.UsernameKey(name)] = true
+		}
+	}
+
+	return &usernameRules{
+		ur:       ur,
+		reserved: reserved,
+		hold:     utils.EnvDuration("USERNAME_HOLD", defaultUsernameHold),
+	}
+}
+
+// normalize trims username and checks its format and the reserved names.
+// It doesn't hit the database.
+func (r *usernameRules) normalize(username string) (string, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return "", domain.ErrUsernameInvalid
+	}
+
+	key := domain.UsernameKey(username)
+	if r.reserved[key] {
+		return "", domain.ErrUsernameReserved
+	}
+	for _, prefix := range domain.ReservedUsernamePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return "", domain.ErrUsernameReserved
+		}
+	}
+	return username, nil
+}
+
+// checkHeld fails when a user other than userID gave up username within the
+// hold period. Taken usernames are left to the unique index.
+func (r *usernameRules) checkHeld(username, userID string) error {
+	held, err := r.ur.IsUsernameHeld(username, userID, time.Now().Add(-r.hold))
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to check username history")
+		return err
+	}
+	if held {
+		return domain.ErrUsernameHeld
+	}
+	return nil
+}
+
+// check runs normalize and checkHeld.
+func (r *usernameRules) check(username, userID string) (string, error) {
+	username, err := r.normalize(username)
+	if err != nil {
+		return "", err
+	}
+	if err := r.checkHeld(username, userID); err != nil {
+		return "", err
+	}
+	return username, nil
+}
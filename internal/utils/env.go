@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EnvDuration reads a duration such as "15m" from the environment, falling
+// back to def when the variable is unset or malformed.
+func EnvDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Warn().Str("key", key).Str("value", raw).Msg("invalid duration in environment, using default")
+		return def
+	}
+	return d
+}
+
+// EnvString reads a string from the environment, falling back to def when
+// the variable is unset.
+func EnvString(key, def string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return def
+}
+
+// EnvInt reads a positive integer from the environment, falling back to def
+// when the variable is unset or malformed.
+func EnvInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		log.Warn().Str("key", key).Str("value", raw).Msg("invalid integer in environment, using default")
+		return def
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package utils_test
+
+import (
+	"strings"
+
+	"github.com/Martin-Arias/go-scoring-api/internal/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slugs", func() {
+
+	It("should lowercase and join words with dashes", func() {
+		Expect(utils.Slugify("Space Racer")).To(Equal("space-racer"))
+	})
+
+	It("should strip accents and collapse symbols", func() {
+		Expect(utils.Slugify("  Fútbol -- Manía!! 2 ")).To(Equal("futbol-mania-2"))
+	})
+
+	It("should return an empty slug when there is nothing usable", func() {
+		Expect(utils.Slugify("!!!")).To(Equal(""))
+	})
+
+	It("should cap the slug length without a trailing dash", func() {
+		slug := utils.Slugify(strings.Repeat("a", 63) + " b")
+		Expect(slug).To(Equal(strings.Repeat("a", 63)))
+	})
+
+	It("should validate canonical slugs only", func() {
+		Expect(utils.IsValidSlug("space-racer")).To(BeTrue())
+		Expect(utils.IsValidSlug("Space-Racer")).To(BeFalse())
+		Expect(utils.IsValidSlug("space--racer")).To(BeFalse())
+		Expect(utils.IsValidSlug("-space")).To(BeFalse())
+		Expect(utils.IsValidSlug("")).To(BeFalse())
+	})
+})
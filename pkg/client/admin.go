@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+func (c *Client) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	var out []dto.RoleResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/roles", out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AssignRole gives a user one of the roles of ListRoles.
+func (c *Client) AssignRole(ctx context.Context, userID, role string) error {
+	return c.do(ctx, &call{method: http.MethodPut, path: userPath(userID, "/role"), body: dto.AssignRoleRequest{Role: role}})
+}
+
+// Promote makes a user an admin.
+func (c *Client) Promote(ctx context.Context, userID, reason string) (*dto.ModerationActionResponse, error) {
+	return c.moderate(ctx, userID, "/promote", dto.ModerationRequest{Reason: reason})
+}
+
+// Demote takes the admin role from a user.
+func (c *Client) Demote(ctx context.Context, userID, reason string) (*dto.ModerationActionResponse, error) {
+	return c.moderate(ctx, userID, "/demote", dto.ModerationRequest{Reason: reason})
+}
+
+// Suspend keeps a user out until the given time.
+func (c *Client) Suspend(ctx context.Context, userID, reason string, until time.Time) (*dto.ModerationActionResponse, error) {
+	return c.moderate(ctx, userID, "/suspend", dto.SuspendRequest{Reason: reason, Until: until})
+}
+
+// Ban keeps a user out until reinstated.
+func (c *Client) Ban(ctx context.Context, userID, reason string) (*dto.ModerationActionResponse, error) {
+	return c.moderate(ctx, userID, "/ban", dto.ModerationRequest{Reason: reason})
+}
+
+// Reinstate lifts the ban or suspension of a user.
+func (c *Client) Reinstate(ctx context.Context, userID, reason string) (*dto.ModerationActionResponse, error) {
+	return c.moderate(ctx, userID, "/reinstate", dto.ModerationRequest{Reason: reason})
+}
+
+func (c *Client) moderate(ctx context.Context, userID, action string, req any) (*dto.ModerationActionResponse, error) {
+	var out dto.ModerationActionResponse
+	if err := c.do(ctx, &call{method: http.MethodPost, path: userPath(userID, action), body: req, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ModerationHistory returns the moderation actions taken on a user.
+func (c *Client) ModerationHistory(ctx context.Context, userID string) ([]dto.ModerationActionResponse, error) {
+	var out []dto.ModerationActionResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: userPath(userID, "/moderation"), out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Unlock clears the failed logins of a user locked out of login.
+func (c *Client) Unlock(ctx context.Context, userID string) error {
+	return c.do(ctx, &call{method: http.MethodDelete, path: userPath(userID, "/lockout")})
+}
+
+// CreateAPIKey creates an API key. The key itself is only returned here.
+func (c *Client) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	var out dto.CreateAPIKeyResponse
+	if err := c.do(ctx, &call{method: http.MethodPost, path: "/api-keys", body: req, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context) ([]dto.APIKeyResponse, error) {
+	var out []dto.APIKeyResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/api-keys", out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.do(ctx, &call{method: http.MethodDelete, path: "/api-keys/" + url.PathEscape(id)})
+}
+
+func userPath(userID, rest string) string {
+	return "/users/" + url.PathEscape(userID) + rest
+}
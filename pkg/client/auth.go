@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// Register creates a user. It does not log in.
+func (c *Client) Register(ctx context.Context, req dto.RegisterRequest) (*dto.RegisterResponse, error) {
+	var out dto.RegisterResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/auth/register", body: req, public: true, idempotencyKey: true, out: &out})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Login logs in as username and keeps the tokens for the next calls.
+func (c *Client) Login(ctx context.Context, username, password string) (*dto.LoginResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.loginLocked(ctx, username, password)
+}
+
+func (c *Client) loginLocked(ctx context.Context, username, password string) (*dto.LoginResponse, error) {
+	var out dto.LoginResponse
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/auth/login",
+		body:   dto.AuthRequest{Username: username, Password: password},
+		public: true,
+		out:    &out,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.setTokensLocked(&out)
+	return &out, nil
+}
+
+// LoginGuest logs in as the guest of deviceID, creating it on first use, and
+// keeps the tokens for the next calls.
+func (c *Client) LoginGuest(ctx context.Context, deviceID string) (*dto.LoginResponse, error) {
+	var out dto.LoginResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/auth/guest", body: dto.GuestLoginRequest{DeviceID: deviceID}, public: true, out: &out})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setTokensLocked(&out)
+	return &out, nil
+}
+
+// Refresh swaps the refresh token of the client for new tokens. Calls do it
+// on their own when the access token is about to expire.
+func (c *Client) Refresh(ctx context.Context) (*dto.LoginResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refreshLocked(ctx)
+}
+
+func (c *Client) refreshLocked(ctx context.Context) (*dto.LoginResponse, error) {
+	if c.tokens.RefreshToken == "" {
+		return nil, ErrRefreshTokenInvalid
+	}
+	var out dto.LoginResponse
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/auth/refresh",
+		body:   dto.RefreshRequest{RefreshToken: c.tokens.RefreshToken},
+		public: true,
+		out:    &out,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.setTokensLocked(&out)
+	return &out, nil
+}
+
+// Logout revokes the session of the client and forgets its tokens.
+func (c *Client) Logout(ctx context.Context) error {
+	tokens := c.Tokens()
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/auth/logout", body: dto.LogoutRequest{RefreshToken: tokens.RefreshToken}})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = Tokens{}
+	c.username, c.password = "", ""
+	if c.onTokens != nil {
+		c.onTokens(c.tokens)
+	}
+	return nil
+}
+
+// ForgotPassword asks for a password reset link for the user with login as
+// username or email. It succeeds whether or not the user exists.
+func (c *Client) ForgotPassword(ctx context.Context, login string) error {
+	return c.do(ctx, &call{method: http.MethodPost, path: "/auth/password/forgot", body: dto.ForgotPasswordRequest{Login: login}, public: true})
+}
+
+// ResetPassword sets a new password with the token of a reset link.
+func (c *Client) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/auth/password/reset",
+		body:   dto.ResetPasswordRequest{Token: token, NewPassword: newPassword},
+		public: true,
+	})
+}
+
+// ChangePassword changes the password of the caller. The other sessions are
+// closed and the client keeps the new tokens.
+func (c *Client) ChangePassword(ctx context.Context, current, newPassword string) (*dto.LoginResponse, error) {
+	var out dto.LoginResponse
+	err := c.do(ctx, &call{
+		method: http.MethodPut,
+		path:   "/users/me/password",
+		body:   dto.ChangePasswordRequest{CurrentPassword: current, NewPassword: newPassword},
+		// Once it went through, the current password is no longer current.
+		retry: noRetry(),
+		out:   &out,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.username != "" {
+		c.password = newPassword
+	}
+	c.setTokensLocked(&out)
+	return &out, nil
+}
+
+// JWKS returns the public keys access tokens are signed with.
+func (c *Client) JWKS(ctx context.Context) (*dto.JWKSResponse, error) {
+	var out dto.JWKSResponse
+	err := c.do(ctx, &call{method: http.MethodGet, path: "/.well-known/jwks.json", absolute: true, public: true, out: &out})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,374 @@
+// Package client is the Go client of the Scoring API.
+//
+// A Client authenticates with an API key, with a username and password, or
+// with tokens obtained elsewhere. With credentials it logs in on the first
+// call and refreshes the access token before it expires, logging in again
+// when the refresh token is no longer valid:
+//
+//	c, err := client.New("https://scores.example.com", client.WithCredentials("ana", "secret"))
+//	games, err := c.ListGames(ctx)
+//
+// Safe calls, and writes the API deduplicates with an Idempotency-Key, are
+// retried with exponential backoff on network errors, 429 and 5xx gateway
+// answers. Error responses are returned as *Error and match the Err*
+// sentinels with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/google/uuid"
+)
+
+// apiPrefix is where the API version the client speaks is served.
+const apiPrefix = "/api/v1"
+
+// refreshSkew is how long before it expires an access token is refreshed.
+const refreshSkew = 30 * time.Second
+
+// Tokens are the tokens of a logged in user.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is when the access token expires. Zero means unknown: the
+	// token is used until the API refuses it.
+	ExpiresAt time.Time
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL   *url.URL
+	http      *http.Client
+	userAgent string
+	retry     RetryPolicy
+
+	apiKey   string
+	username string
+	password string
+	onTokens func(Tokens)
+
+	// mu guards tokens and serializes logins and refreshes, so concurrent
+	// calls share one new token.
+	mu     sync.Mutex
+	tokens Tokens
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithAPIKey authenticates every call with an API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithCredentials logs in as username when a call needs a token and none is
+// valid.
+func WithCredentials(username, password string) Option {
+	return func(c *Client) { c.username, c.password = username, password }
+}
+
+// WithTokens starts the client with tokens of an earlier session.
+func WithTokens(t Tokens) Option {
+	return func(c *Client) { c.tokens = t }
+}
+
+// OnTokens calls fn whenever the client gets new tokens, e.g. to persist the
+// session.
+func OnTokens(fn func(Tokens)) Option {
+	return func(c *Client) { c.onTokens = fn }
+}
+
+// WithRetry replaces the default retry policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client of the API served at baseURL.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be absolute", baseURL)
+	}
+
+	c := &Client{
+		baseURL:   u,
+		http:      http.DefaultClient,
+		userAgent: "go-scoring-api-client",
+		retry:     DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Tokens returns the current tokens of the client.
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// call describes one API call.
+type call struct {
+	method string
+	// path is relative to /api/v1 unless absolute is set.
+	path     string
+	absolute bool
+	query    url.Values
+	// body is encoded as JSON; raw is sent as is with contentType.
+	body        any
+	raw         io.Reader
+	contentType string
+	accept      string
+	// public calls are sent without credentials.
+	public bool
+	// idempotencyKey sends a fresh Idempotency-Key, the same on every
+	// attempt, which makes a write safe to retry.
+	idempotencyKey bool
+	// retry overrides whether the call is retried, which by default depends
+	// on its method.
+	retry *bool
+	out   any
+}
+
+func (cl *call) retryable() bool {
+	if cl.raw != nil {
+		// The body can only be read once.
+		return false
+	}
+	if cl.retry != nil {
+		return *cl.retry
+	}
+	if cl.idempotencyKey {
+		return true
+	}
+	switch cl.method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func noRetry() *bool {
+	retry := false
+	return &retry
+}
+
+func alwaysRetry() *bool {
+	retry := true
+	return &retry
+}
+
+// do runs cl, retrying it as allowed, and decodes the response into cl.out.
+func (c *Client) do(ctx context.Context, cl *call) error {
+	var body []byte
+	if cl.body != nil {
+		var err error
+		if body, err = json.Marshal(cl.body); err != nil {
+			return fmt.Errorf("client: encoding request: %w", err)
+		}
+	}
+	var key string
+	if cl.idempotencyKey {
+		key = uuid.NewString()
+	}
+
+	reauthenticated := false
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, cl, body, key)
+		if err != nil {
+			return err
+		}
+		if !cl.public {
+			if err := c.authenticate(ctx, req); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if cl.retryable() && attempt < c.retry.MaxRetries {
+				if err := c.retry.sleep(ctx, attempt, 0); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if resp.StatusCode < 300 {
+			return decode(resp, cl.out)
+		}
+
+		apiErr := decodeError(resp)
+		// An access token refused before its expiry, e.g. revoked, is
+		// replaced once.
+		if resp.StatusCode == http.StatusUnauthorized && !cl.public && !reauthenticated && c.dropToken(req) {
+			reauthenticated = true
+			attempt--
+			continue
+		}
+		if cl.retryable() && apiErr.temporary() && attempt < c.retry.MaxRetries && apiErr.RetryAfter <= c.retry.MaxBackoff {
+			if err := c.retry.sleep(ctx, attempt, apiErr.RetryAfter); err != nil {
+				return err
+			}
+			continue
+		}
+		return apiErr
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, cl *call, body []byte, key string) (*http.Request, error) {
+	u := *c.baseURL
+	if cl.absolute {
+		u.Path += cl.path
+	} else {
+		u.Path += apiPrefix + cl.path
+	}
+	u.RawQuery = cl.query.Encode()
+
+	var reader io.Reader
+	switch {
+	case cl.raw != nil:
+		reader = cl.raw
+	case body != nil:
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, cl.method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("client: building request: %w", err)
+	}
+
+	switch {
+	case cl.raw != nil:
+		req.Header.Set("Content-Type", cl.contentType)
+	case body != nil:
+		req.Header.Set("Content-Type", "application/json")
+	}
+	accept := cl.accept
+	if accept == "" {
+		accept = "application/json"
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", c.userAgent)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	return req, nil
+}
+
+func decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decoding response: %w", err)
+	}
+	return nil
+}
+
+// authenticate adds the credentials of the client to req: the API key, or an
+// access token, logging in or refreshing first when needed. Without any
+// credentials the request is sent as is and the API decides.
+func (c *Client) authenticate(ctx context.Context, req *http.Request) error {
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+		return nil
+	}
+
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := c.tokens
+	if t.AccessToken != "" && (t.ExpiresAt.IsZero() || time.Until(t.ExpiresAt) > refreshSkew) {
+		return t.AccessToken, nil
+	}
+	if t.RefreshToken != "" {
+		_, err := c.refreshLocked(ctx)
+		if err == nil {
+			return c.tokens.AccessToken, nil
+		}
+		if c.username == "" || !errors.Is(err, ErrRefreshTokenInvalid) {
+			return "", err
+		}
+	}
+	if c.username != "" {
+		if _, err := c.loginLocked(ctx, c.username, c.password); err != nil {
+			return "", err
+		}
+		return c.tokens.AccessToken, nil
+	}
+	return t.AccessToken, nil
+}
+
+// dropToken forgets the access token req was sent with, if the client can get
+// another one, and reports whether it did.
+func (c *Client) dropToken(req *http.Request) bool {
+	if c.apiKey != "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sent := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if sent != c.tokens.AccessToken {
+		// Another call already replaced it.
+		return true
+	}
+	if c.tokens.RefreshToken == "" && c.username == "" {
+		return false
+	}
+	c.tokens.AccessToken = ""
+	return true
+}
+
+func (c *Client) setTokensLocked(resp *dto.LoginResponse) {
+	c.tokens = Tokens{AccessToken: resp.Token, RefreshToken: resp.RefreshToken}
+	if resp.ExpiresIn > 0 {
+		c.tokens.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	if c.onTokens != nil {
+		c.onTokens(c.tokens)
+	}
+}
@@ -0,0 +1,370 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+	"github.com/Martin-Arias/go-scoring-api/internal/domain"
+	"github.com/Martin-Arias/go-scoring-api/internal/problem"
+	"github.com/Martin-Arias/go-scoring-api/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fast = client.RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem.Problem{
+		Type:      "urn:problem:" + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    strings.ReplaceAll(code, "_", " "),
+		Code:      problem.Code(code),
+		RequestID: "req-1",
+	})
+}
+
+func newClient(t *testing.T, handler http.HandlerFunc, opts ...client.Option) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := client.New(srv.URL, append([]client.Option{client.WithRetry(fast)}, opts...)...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_RejectsRelativeURL(t *testing.T) {
+	_, err := client.New("/api")
+	assert.Error(t, err)
+}
+
+func TestClient_LogsInOnFirstCall(t *testing.T) {
+	var logins atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/login":
+			logins.Add(1)
+			var req dto.AuthRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, dto.AuthRequest{Username: "ana", Password: "secret"}, req)
+			writeJSON(w, http.StatusOK, dto.LoginResponse{Token: "t1", RefreshToken: "r1", ExpiresIn: 3600})
+		case "/api/v1/games":
+			assert.Equal(t, "Bearer t1", r.Header.Get("Authorization"))
+			writeJSON(w, http.StatusOK, []dto.GameResponse{{ID: "g1", Name: "Tetris", Slug: "tetris"}})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}, client.WithCredentials("ana", "secret"))
+
+	for range 2 {
+		games, err := c.ListGames(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []dto.GameResponse{{ID: "g1", Name: "Tetris", Slug: "tetris"}}, games)
+	}
+	assert.EqualValues(t, 1, logins.Load())
+	assert.Equal(t, "r1", c.Tokens().RefreshToken)
+}
+
+func TestClient_RefreshesBeforeExpiry(t *testing.T) {
+	var saved []client.Tokens
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/refresh":
+			var req dto.RefreshRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "r1", req.RefreshToken)
+			writeJSON(w, http.StatusOK, dto.LoginResponse{Token: "t2", RefreshToken: "r2", ExpiresIn: 3600})
+		case "/api/v1/users/me":
+			assert.Equal(t, "Bearer t2", r.Header.Get("Authorization"))
+			writeJSON(w, http.StatusOK, dto.ProfileResponse{ID: "u1", Username: "ana"})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	},
+		client.WithTokens(client.Tokens{AccessToken: "t1", RefreshToken: "r1", ExpiresAt: time.Now().Add(10 * time.Second)}),
+		client.OnTokens(func(t client.Tokens) { saved = append(saved, t) }),
+	)
+
+	me, err := c.Me(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ana", me.Username)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "t2", saved[0].AccessToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), saved[0].ExpiresAt, time.Minute)
+}
+
+func TestClient_LogsInAgainWhenRefreshTokenIsInvalid(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/refresh":
+			writeProblem(w, http.StatusUnauthorized, "refresh_token_invalid")
+		case "/api/v1/auth/login":
+			writeJSON(w, http.StatusOK, dto.LoginResponse{Token: "t2", RefreshToken: "r2", ExpiresIn: 3600})
+		case "/api/v1/games":
+			assert.Equal(t, "Bearer t2", r.Header.Get("Authorization"))
+			writeJSON(w, http.StatusOK, []dto.GameResponse{})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	},
+		client.WithCredentials("ana", "secret"),
+		client.WithTokens(client.Tokens{AccessToken: "t1", RefreshToken: "r1", ExpiresAt: time.Now()}),
+	)
+
+	_, err := c.ListGames(context.Background())
+	require.NoError(t, err)
+}
+
+func TestClient_ReplacesRevokedToken(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/refresh":
+			writeJSON(w, http.StatusOK, dto.LoginResponse{Token: "t2", RefreshToken: "r2", ExpiresIn: 3600})
+		case "/api/v1/games/tetris":
+			calls.Add(1)
+			if r.Header.Get("Authorization") != "Bearer t2" {
+				writeProblem(w, http.StatusUnauthorized, "token_revoked")
+				return
+			}
+			writeJSON(w, http.StatusOK, dto.GameResponse{ID: "g1", Slug: "tetris"})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}, client.WithTokens(client.Tokens{AccessToken: "t1", RefreshToken: "r1", ExpiresAt: time.Now().Add(time.Hour)}))
+
+	game, err := c.GetGame(context.Background(), "tetris")
+	require.NoError(t, err)
+	assert.Equal(t, "g1", game.ID)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestClient_ReturnsUnauthorizedWithoutCredentials(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		writeProblem(w, http.StatusUnauthorized, "authentication_required")
+	})
+
+	_, err := c.Me(context.Background())
+	assert.ErrorIs(t, err, client.ErrAuthRequired)
+}
+
+func TestClient_SendsAPIKey(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sk_test", r.Header.Get("X-API-Key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, dto.ScoreStatisticsDTO{})
+	}, client.WithAPIKey("sk_test"))
+
+	_, err := c.GameStats(context.Background(), "tetris")
+	require.NoError(t, err)
+}
+
+func TestClient_RetriesTemporaryErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			writeProblem(w, http.StatusServiceUnavailable, "internal_error")
+			return
+		}
+		writeJSON(w, http.StatusOK, []dto.ScoreResponse{{UserID: "u1", Points: 10}})
+	}, client.WithAPIKey("sk_test"))
+
+	scores, err := c.Leaderboard(context.Background(), "tetris")
+	require.NoError(t, err)
+	assert.Len(t, scores, 1)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestClient_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = io.WriteString(w, "upstream down")
+	}, client.WithAPIKey("sk_test"))
+
+	_, err := c.ListGames(context.Background())
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "upstream down", apiErr.Detail)
+	assert.EqualValues(t, 1+fast.MaxRetries, calls.Load())
+}
+
+func TestClient_DoesNotRetryUnsafeWrites(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeProblem(w, http.StatusServiceUnavailable, "internal_error")
+	}, client.WithAPIKey("sk_test"))
+
+	_, err := c.CreateAPIKey(context.Background(), dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"scores:read"}})
+	assert.ErrorIs(t, err, client.ErrInternal)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestClient_RetriesWritesWithTheSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		var req dto.CreateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Tetris", req.Name)
+		if len(keys) == 1 {
+			writeProblem(w, http.StatusConflict, "idempotency_key_in_progress")
+			return
+		}
+		writeJSON(w, http.StatusCreated, dto.GameResponse{ID: "g1", Name: "Tetris", Slug: "tetris"})
+	}, client.WithAPIKey("sk_test"))
+
+	game, err := c.CreateGame(context.Background(), dto.CreateRequest{Name: "Tetris"})
+	require.NoError(t, err)
+	assert.Equal(t, "g1", game.ID)
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+}
+
+func TestClient_ReturnsLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		writeProblem(w, http.StatusTooManyRequests, "username_cooldown")
+	}, client.WithAPIKey("sk_test"))
+
+	_, err := c.Rename(context.Background(), "ana2")
+	assert.ErrorIs(t, err, client.ErrUsernameCooldown)
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, time.Minute, apiErr.RetryAfter)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusNotFound, "game_not_found")
+	}, client.WithAPIKey("sk_test"))
+
+	_, err := c.GetGame(context.Background(), "nope")
+	assert.ErrorIs(t, err, client.ErrGameNotFound)
+	assert.NotErrorIs(t, err, client.ErrUserNotFound)
+
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "req-1", apiErr.RequestID)
+	assert.Equal(t, "scoring api: 404 game_not_found: game not found", apiErr.Error())
+}
+
+func TestClient_StopsWhenContextIsDone(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusServiceUnavailable, "internal_error")
+	}, client.WithAPIKey("sk_test"), client.WithRetry(client.RetryPolicy{MaxRetries: 5, MinBackoff: time.Hour, MaxBackoff: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.MyScores(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestClient_Import(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/imports/scores", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "true", r.URL.Query().Get("dry_run"))
+		assert.Equal(t, "skip", r.URL.Query().Get("conflict"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"username":"ana","game_slug":"tetris","points":10}`+"\n", string(body))
+		writeJSON(w, http.StatusOK, dto.ImportReportResponse{Kind: "scores", DryRun: true})
+	}, client.WithAPIKey("sk_test"))
+
+	file := strings.NewReader(`{"username":"ana","game_slug":"tetris","points":10}` + "\n")
+	report, err := c.Import(context.Background(), client.ImportScores, file, client.ImportOptions{Format: client.FormatNDJSON, Conflict: "skip", DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+}
+
+func TestClient_GraphQL(t *testing.T) {
+	c := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req dto.GraphQLRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "tetris", req.Variables["ref"])
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]any{"game": map[string]any{"name": "Tetris"}, "user": nil},
+			"errors": []dto.GraphQLError{{
+				Message:    "user not found",
+				Path:       []any{"user"},
+				Extensions: map[string]any{"code": "user_not_found", "status": 404},
+			}},
+		})
+	}, client.WithAPIKey("sk_test"))
+
+	var data struct {
+		Game struct{ Name string }
+	}
+	err := c.GraphQL(context.Background(), `query($ref: ID!) { game(ref: $ref) { name } user(id: "x") { id } }`, map[string]interface{}{"ref": "tetris"}, &data)
+	assert.ErrorIs(t, err, client.ErrUserNotFound)
+	assert.Equal(t, "Tetris", data.Game.Name)
+}
+
+func TestSentinels_MatchTheAPICodes(t *testing.T) {
+	for sentinel, domainErr := range map[*client.Error]error{
+		client.ErrInvalidRequest:           domain.ErrInvalidRequest,
+		client.ErrAuthRequired:             domain.ErrAuthRequired,
+		client.ErrInvalidCredentials:       domain.ErrAuthInvalid,
+		client.ErrForbidden:                domain.ErrForbidden,
+		client.ErrPasswordPolicy:           domain.ErrPasswordPolicy,
+		client.ErrLoginLocked:              domain.ErrLoginLocked,
+		client.ErrAccountBanned:            domain.ErrUserBanned,
+		client.ErrAccountSuspended:         domain.ErrUserSuspended,
+		client.ErrTokenInvalid:             domain.ErrTokenInvalid,
+		client.ErrTokenRevoked:             domain.ErrTokenRevoked,
+		client.ErrRefreshTokenInvalid:      domain.ErrRefreshTokenInvalid,
+		client.ErrAPIKeyInvalid:            domain.ErrAPIKeyInvalid,
+		client.ErrScoreNotFound:            domain.ErrScoreNotFound,
+		client.ErrGameNotFound:             domain.ErrGameNotFound,
+		client.ErrUserNotFound:             domain.ErrUserNotFound,
+		client.ErrGuestNotFound:            domain.ErrGuestNotFound,
+		client.ErrRoleNotFound:             domain.ErrRoleNotFound,
+		client.ErrAPIKeyNotFound:           domain.ErrAPIKeyNotFound,
+		client.ErrGameExists:               domain.ErrGameAlreadyExists,
+		client.ErrGameSlugExists:           domain.ErrGameSlugAlreadyExists,
+		client.ErrUsernameExists:           domain.ErrUsernameAlreadyExists,
+		client.ErrEmailExists:              domain.ErrEmailAlreadyExists,
+		client.ErrUsernameCooldown:         domain.ErrUsernameCooldown,
+		client.ErrScoreNotHigher:           domain.ErrScoreNotAllowed,
+		client.ErrNotGuest:                 domain.ErrNotGuest,
+		client.ErrMergeTargetInvalid:       domain.ErrMergeTarget,
+		client.ErrLastAdmin:                domain.ErrLastAdmin,
+		client.ErrInvalidSuspension:        domain.ErrInvalidSuspension,
+		client.ErrInvalidScope:             domain.ErrInvalidScope,
+		client.ErrGameNotAllowed:           domain.ErrGameNotAllowed,
+		client.ErrInvalidGameSlug:          domain.ErrInvalidGameSlug,
+		client.ErrIdempotencyKeyReused:     domain.ErrIdempotencyKeyReused,
+		client.ErrIdempotencyKeyInProgress: domain.ErrIdempotencyKeyInProgress,
+		client.ErrInternal:                 errors.New("boom"),
+	} {
+		assert.Equal(t, string(problem.CodeOf(domainErr)), sentinel.Code, domainErr.Error())
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Error is an error answered by the API. Its Code is stable and matches one
+// of the Err* sentinels:
+//
+//	if errors.Is(err, client.ErrGameNotFound) { ... }
+type Error struct {
+	StatusCode int
+	Code       string
+	Title      string
+	Detail     string
+	// Instance is the path of the failed request.
+	Instance  string
+	RequestID string
+	// Fields lists the request fields that failed validation.
+	Fields []FieldError
+	// Violations lists the rules a refused password breaks.
+	Violations []string
+	// Reason and Until describe the ban or suspension of the caller.
+	Reason string
+	Until  *time.Time
+	// RetryAfter is how long the API asked to wait before trying again.
+	RetryAfter time.Duration
+}
+
+// FieldError is a request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Detail
+	if msg == "" {
+		msg = e.Title
+	}
+	switch {
+	case e.Code == "":
+		return fmt.Sprintf("scoring api: %d %s", e.StatusCode, msg)
+	case e.StatusCode == 0:
+		return fmt.Sprintf("scoring api: %s: %s", e.Code, msg)
+	}
+	return fmt.Sprintf("scoring api: %d %s: %s", e.StatusCode, e.Code, msg)
+}
+
+// Is reports whether target is an *Error with the same code, so errors match
+// the sentinels.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// temporary reports whether the same call may succeed later.
+func (e *Error) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return e.Code == ErrIdempotencyKeyInProgress.Code
+}
+
+// decodeError reads the problem document of a failed response. Answers that
+// are not one, e.g. from a proxy, keep their status and body.
+func decodeError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var doc struct {
+		Title      string       `json:"title"`
+		Detail     string       `json:"detail"`
+		Instance   string       `json:"instance"`
+		Code       string       `json:"code"`
+		RequestID  string       `json:"request_id"`
+		Errors     []FieldError `json:"errors"`
+		Violations []string     `json:"violations"`
+		Reason     string       `json:"reason"`
+		Until      *time.Time   `json:"until"`
+	}
+	e := &Error{StatusCode: resp.StatusCode, Title: http.StatusText(resp.StatusCode)}
+	if err := json.Unmarshal(body, &doc); err == nil && doc.Code != "" {
+		e.Code, e.Title, e.Detail = doc.Code, doc.Title, doc.Detail
+		e.Instance, e.RequestID = doc.Instance, doc.RequestID
+		e.Fields, e.Violations = doc.Errors, doc.Violations
+		e.Reason, e.Until = doc.Reason, doc.Until
+	} else {
+		e.Detail = string(body)
+	}
+	if e.RequestID == "" {
+		e.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		e.RetryAfter = time.Duration(secs) * time.Second
+	}
+	return e
+}
+
+func sentinel(code string) *Error {
+	return &Error{Code: code}
+}
+
+// The errors of the API, by code.
+var (
+	ErrInternal       = sentinel("internal_error")
+	ErrInvalidRequest = sentinel("invalid_request")
+	ErrVersionSunset  = sentinel("version_sunset")
+	ErrNotAcceptable  = sentinel("not_acceptable")
+	ErrInvalidImport  = sentinel("invalid_import")
+
+	ErrAuthRequired       = sentinel("authentication_required")
+	ErrInvalidCredentials = sentinel("invalid_credentials")
+	ErrForbidden          = sentinel("forbidden")
+	ErrUserTokenRequired  = sentinel("user_token_required")
+
+	ErrCurrentPasswordInvalid = sentinel("current_password_invalid")
+	ErrResetTokenInvalid      = sentinel("reset_token_invalid")
+	ErrPasswordPolicy         = sentinel("password_policy")
+	ErrPasswordChangeRequired = sentinel("password_change_required")
+	ErrLoginLocked            = sentinel("login_locked")
+	ErrAccountBanned          = sentinel("account_banned")
+	ErrAccountSuspended       = sentinel("account_suspended")
+	ErrIdentityLinked         = sentinel("identity_linked")
+
+	ErrTokenInvalid        = sentinel("token_invalid")
+	ErrTokenRevoked        = sentinel("token_revoked")
+	ErrRefreshTokenInvalid = sentinel("refresh_token_invalid")
+	ErrAPIKeyInvalid       = sentinel("api_key_invalid")
+
+	ErrScoreNotFound      = sentinel("score_not_found")
+	ErrGameNotFound       = sentinel("game_not_found")
+	ErrUserNotFound       = sentinel("user_not_found")
+	ErrGuestNotFound      = sentinel("guest_not_found")
+	ErrRoleNotFound       = sentinel("role_not_found")
+	ErrAPIKeyNotFound     = sentinel("api_key_not_found")
+	ErrSigningKeyNotFound = sentinel("signing_key_not_found")
+
+	ErrGameExists     = sentinel("game_exists")
+	ErrGameSlugExists = sentinel("game_slug_exists")
+	ErrUsernameExists = sentinel("username_exists")
+	ErrEmailExists    = sentinel("email_exists")
+
+	ErrUsernameInvalid  = sentinel("username_invalid")
+	ErrUsernameReserved = sentinel("username_reserved")
+	ErrUsernameHeld     = sentinel("username_held")
+	ErrUsernameCooldown = sentinel("username_cooldown")
+
+	ErrScoreNotHigher = sentinel("score_not_higher")
+
+	ErrNotGuest           = sentinel("not_guest")
+	ErrMergeTargetInvalid = sentinel("merge_target_invalid")
+
+	ErrLastAdmin         = sentinel("last_admin")
+	ErrUserNotAdmin      = sentinel("user_not_admin")
+	ErrUserAlreadyAdmin  = sentinel("user_already_admin")
+	ErrModerateAdmin     = sentinel("moderate_admin")
+	ErrInvalidSuspension = sentinel("suspension_invalid")
+
+	ErrInvalidScope    = sentinel("api_key_scope_invalid")
+	ErrGameNotAllowed  = sentinel("game_not_allowed")
+	ErrInvalidExpiry   = sentinel("api_key_expiry_invalid")
+	ErrInvalidGameSlug = sentinel("game_slug_invalid")
+
+	ErrIdempotencyKeyInvalid    = sentinel("idempotency_key_invalid")
+	ErrIdempotencyKeyReused     = sentinel("idempotency_key_reused")
+	ErrIdempotencyKeyInProgress = sentinel("idempotency_key_in_progress")
+	ErrRequestTooLarge          = sentinel("request_too_large")
+)
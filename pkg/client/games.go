@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// CreateGame creates a game. The slug is derived from the name when empty.
+func (c *Client) CreateGame(ctx context.Context, req dto.CreateRequest) (*dto.GameResponse, error) {
+	var out dto.GameResponse
+	if err := c.do(ctx, &call{method: http.MethodPost, path: "/games", body: req, idempotencyKey: true, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListGames(ctx context.Context) ([]dto.GameResponse, error) {
+	var out []dto.GameResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/games", out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetGame returns the game with ref as ID or slug.
+func (c *Client) GetGame(ctx context.Context, ref string) (*dto.GameResponse, error) {
+	var out dto.GameResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/games/" + url.PathEscape(ref), out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Leaderboard returns the scores of the game with ref as ID or slug, best
+// first.
+func (c *Client) Leaderboard(ctx context.Context, ref string) ([]dto.ScoreResponse, error) {
+	var out []dto.ScoreResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/games/" + url.PathEscape(ref) + "/scores", out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GameStats returns the score statistics of the game with ref as ID or slug.
+func (c *Client) GameStats(ctx context.Context, ref string) (*dto.ScoreStatisticsDTO, error) {
+	var out dto.ScoreStatisticsDTO
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/games/" + url.PathEscape(ref) + "/stats", out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
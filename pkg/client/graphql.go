@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// GraphQL runs a query on the GraphQL endpoint and decodes its data into
+// out. The errors of the response are returned as *Error, joined, so they
+// match the sentinels like those of the REST endpoints.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out any) error {
+	var resp struct {
+		Data   json.RawMessage    `json:"data"`
+		Errors []dto.GraphQLError `json:"errors"`
+	}
+	err := c.do(ctx, &call{
+		method: http.MethodPost,
+		path:   "/graphql",
+		body:   dto.GraphQLRequest{Query: query, Variables: variables},
+		// The endpoint is read-only.
+		retry: alwaysRetry(),
+		out:   &resp,
+	})
+	if err != nil {
+		return err
+	}
+
+	if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("client: decoding GraphQL data: %w", err)
+		}
+	}
+	errs := make([]error, 0, len(resp.Errors))
+	for _, gqlErr := range resp.Errors {
+		errs = append(errs, graphQLError(gqlErr))
+	}
+	return errors.Join(errs...)
+}
+
+func graphQLError(gqlErr dto.GraphQLError) *Error {
+	e := &Error{Detail: gqlErr.Message}
+	if code, ok := gqlErr.Extensions["code"].(string); ok {
+		e.Code = code
+	}
+	if status, ok := gqlErr.Extensions["status"].(float64); ok {
+		e.StatusCode = int(status)
+	}
+	return e
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// Import kinds.
+const (
+	ImportUsers  = "users"
+	ImportGames  = "games"
+	ImportScores = "scores"
+)
+
+// Import file formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ImportOptions tune Import.
+type ImportOptions struct {
+	// Format of the file, FormatCSV by default.
+	Format string
+	// Conflict is what to do with rows that already exist: keep-best,
+	// overwrite or skip. Empty leaves it to the API.
+	Conflict string
+	// DryRun validates and reports without writing.
+	DryRun bool
+}
+
+// Import loads a file of users, games or scores. The file is streamed, so the
+// call is not retried.
+func (c *Client) Import(ctx context.Context, kind string, file io.Reader, opts ImportOptions) (*dto.ImportReportResponse, error) {
+	contentType := "text/csv"
+	if opts.Format == FormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	query := url.Values{}
+	if opts.Conflict != "" {
+		query.Set("conflict", opts.Conflict)
+	}
+	if opts.DryRun {
+		query.Set("dry_run", strconv.FormatBool(true))
+	}
+
+	var out dto.ImportReportResponse
+	err := c.do(ctx, &call{
+		method:      http.MethodPost,
+		path:        "/imports/" + url.PathEscape(kind),
+		query:       query,
+		raw:         file,
+		contentType: contentType,
+		out:         &out,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
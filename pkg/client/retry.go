@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides how failed calls that are safe to repeat are retried.
+type RetryPolicy struct {
+	// MaxRetries is how many times a call is retried after the first
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// MinBackoff is the wait before the first retry; it doubles on each
+	// retry, with jitter, up to MaxBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff bounds the wait. Answers asking to retry later than that,
+	// e.g. a login lockout, are returned instead.
+	MaxBackoff time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 200 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// backoff returns the wait before retry number attempt+1: full jitter over an
+// exponential bound, or what the API asked for in Retry-After.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	bound := p.MinBackoff << attempt
+	if bound <= 0 || bound > p.MaxBackoff {
+		bound = p.MaxBackoff
+	}
+	if bound <= 0 {
+		return 0
+	}
+	return bound/2 + rand.N(bound/2+1)
+}
+
+// sleep waits before a retry, or until ctx is done.
+func (p RetryPolicy) sleep(ctx context.Context, attempt int, retryAfter time.Duration) error {
+	timer := time.NewTimer(p.backoff(attempt, retryAfter))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// SubmitScore records the points of a user in a game. It fails with
+// ErrScoreNotHigher when the user already has a better score.
+func (c *Client) SubmitScore(ctx context.Context, req dto.SubmitScoreRequest) error {
+	return c.do(ctx, &call{method: http.MethodPut, path: "/scores", body: req, idempotencyKey: true})
+}
+
+// MyScores returns the scores of the caller.
+func (c *Client) MyScores(ctx context.Context) ([]dto.ScoreResponse, error) {
+	return c.scores(ctx, "/users/me/scores")
+}
+
+// MyGameScore returns the score of the caller in the game with ref as ID or
+// slug.
+func (c *Client) MyGameScore(ctx context.Context, game string) (*dto.ScoreResponse, error) {
+	return c.score(ctx, "/users/me/games/"+url.PathEscape(game)+"/score")
+}
+
+// UserScores returns the scores of a user.
+func (c *Client) UserScores(ctx context.Context, userID string) ([]dto.ScoreResponse, error) {
+	return c.scores(ctx, "/users/"+url.PathEscape(userID)+"/scores")
+}
+
+// UserGameScore returns the score of a user in the game with ref as ID or
+// slug.
+func (c *Client) UserGameScore(ctx context.Context, userID, game string) (*dto.ScoreResponse, error) {
+	return c.score(ctx, "/users/"+url.PathEscape(userID)+"/games/"+url.PathEscape(game)+"/score")
+}
+
+func (c *Client) scores(ctx context.Context, path string) ([]dto.ScoreResponse, error) {
+	var out []dto.ScoreResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: path, out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) score(ctx context.Context, path string) (*dto.ScoreResponse, error) {
+	var out dto.ScoreResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: path, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
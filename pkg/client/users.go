@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Martin-Arias/go-scoring-api/cmd/api/dto"
+)
+
+// Me returns the profile of the caller.
+func (c *Client) Me(ctx context.Context) (*dto.ProfileResponse, error) {
+	return c.profile(ctx, &call{method: http.MethodGet, path: "/users/me"})
+}
+
+// UpdateMe changes the profile fields of req that are set.
+func (c *Client) UpdateMe(ctx context.Context, req dto.UpdateProfileRequest) (*dto.ProfileResponse, error) {
+	return c.profile(ctx, &call{method: http.MethodPatch, path: "/users/me", body: req})
+}
+
+// Rename changes the username of the caller.
+func (c *Client) Rename(ctx context.Context, username string) (*dto.ProfileResponse, error) {
+	return c.profile(ctx, &call{method: http.MethodPut, path: "/users/me/username", body: dto.RenameRequest{Username: username}})
+}
+
+// UpgradeGuest turns the calling guest into a regular user.
+func (c *Client) UpgradeGuest(ctx context.Context, req dto.UpgradeGuestRequest) (*dto.ProfileResponse, error) {
+	return c.profile(ctx, &call{method: http.MethodPost, path: "/users/me/upgrade", body: req})
+}
+
+func (c *Client) profile(ctx context.Context, cl *call) (*dto.ProfileResponse, error) {
+	var out dto.ProfileResponse
+	cl.out = &out
+	if err := c.do(ctx, cl); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LinkIdentity starts linking an account of an identity provider to the
+// caller and returns the provider URL to send the user to. The callback of
+// that login links the account. The callback also needs the oidc_binding
+// cookie set by this response, so the link can only be completed by a browser
+// holding it; clients that open the URL elsewhere can't link accounts.
+func (c *Client) LinkIdentity(ctx context.Context, provider string) (string, error) {
+	var out dto.LinkIdentityResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/users/me/identities/" + url.PathEscape(provider), out: &out})
+	if err != nil {
+		return "", err
+	}
+	return out.URL, nil
+}
+
+// MergeGuest moves the scores of the guest of deviceID to the caller and
+// returns the scores of the caller.
+func (c *Client) MergeGuest(ctx context.Context, deviceID string) ([]dto.ScoreResponse, error) {
+	var out []dto.ScoreResponse
+	err := c.do(ctx, &call{method: http.MethodPost, path: "/users/me/merge", body: dto.MergeGuestRequest{DeviceID: deviceID}, out: &out})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteMe deletes the account of the caller, who confirms with the
+// password. The client forgets its tokens.
+func (c *Client) DeleteMe(ctx context.Context, password string) error {
+	err := c.do(ctx, &call{method: http.MethodDelete, path: "/users/me", body: dto.DeleteAccountRequest{Password: password}})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = Tokens{}
+	c.username, c.password = "", ""
+	if c.onTokens != nil {
+		c.onTokens(c.tokens)
+	}
+	return nil
+}
+
+// ExportMe returns everything stored about the caller.
+func (c *Client) ExportMe(ctx context.Context) (*dto.UserExportResponse, error) {
+	var out dto.UserExportResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/users/me/export", out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetUser returns the public profile of a user.
+func (c *Client) GetUser(ctx context.Context, id string) (*dto.PublicProfileResponse, error) {
+	var out dto.PublicProfileResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/users/" + url.PathEscape(id), out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListUsersOptions filters and pages ListUsers. Zero values use the defaults
+// of the API.
+type ListUsersOptions struct {
+	Search   string
+	Page     int
+	PageSize int
+}
+
+func (c *Client) ListUsers(ctx context.Context, opts ListUsersOptions) (*dto.UserListResponse, error) {
+	query := url.Values{}
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(opts.PageSize))
+	}
+
+	var out dto.UserListResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/users", query: query, out: &out}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UsernameHistory returns the former usernames of a user, newest first.
+func (c *Client) UsernameHistory(ctx context.Context, id string) ([]dto.UsernameChangeResponse, error) {
+	var out []dto.UsernameChangeResponse
+	if err := c.do(ctx, &call{method: http.MethodGet, path: "/users/" + url.PathEscape(id) + "/usernames", out: &out}); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
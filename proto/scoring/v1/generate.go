@@ -0,0 +1,4 @@
+// Package scoringv1 holds the generated code of the gRPC API.
+package scoringv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative scoring/v1/scoring.proto